@@ -0,0 +1,223 @@
+package certm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// CachePolicy 主机下发的数据缓存控制，随调用上下文传入
+type CachePolicy struct {
+	Disabled bool     `json:"disabled,omitempty"` // 禁用缓存
+	TTL      int      `json:"ttl,omitempty"`      // 缓存有效期(秒)，0 表示在本次调用内有效
+	NoCache  []string `json:"no_cache,omitempty"` // 不缓存的主机函数，如 db_get_cert_asset_detail
+}
+
+// CachedDataAccess 带缓存的数据访问，缓存只读方法的结果，作用于单次组件调用
+//
+// 相同参数的查询只请求主机一次，错误不缓存；写入方法成功后清空缓存。
+// 缓存以JSON形式保存，每次命中都返回新解码的副本，调用方修改返回的对象或列表不影响后续查询。
+type CachedDataAccess struct {
+	DataAccess
+
+	policy  CachePolicy
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	now     func() time.Time
+}
+
+type cacheEntry struct {
+	fn       string
+	data     []byte // 查询结果的JSON编码
+	expireAt time.Time
+}
+
+// NewCachedDataAccess 创建带缓存的数据访问，policy 为 nil 时使用默认策略
+func NewCachedDataAccess(da DataAccess, policy *CachePolicy) *CachedDataAccess {
+	c := &CachedDataAccess{DataAccess: da, entries: make(map[string]cacheEntry), now: time.Now}
+	if policy != nil {
+		c.policy = *policy
+	}
+	return c
+}
+
+// InvalidateDataCache 清空上下文数据访问的缓存，fns 为空时清空全部，否则仅清空指定主机函数的缓存
+func InvalidateDataCache(ctx context.Context, fns ...string) {
+	if c, ok := GetDataAccess(ctx).(*CachedDataAccess); ok {
+		c.Invalidate(fns...)
+	}
+}
+
+// Invalidate 清空缓存，fns 为空时清空全部，否则仅清空指定主机函数的缓存
+func (c *CachedDataAccess) Invalidate(fns ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(fns) == 0 {
+		clear(c.entries)
+		return
+	}
+	for key, e := range c.entries {
+		if slices.Contains(fns, e.fn) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// cached 按主机函数名及参数缓存查询结果
+func cached[T any](c *CachedDataAccess, fn string, fetch func() (T, error), args ...any) (T, error) {
+	if c.policy.Disabled || slices.Contains(c.policy.NoCache, fn) {
+		return fetch()
+	}
+
+	key := fn
+	for _, arg := range args {
+		key += fmt.Sprintf("|%v", arg)
+	}
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if ok && !e.expireAt.IsZero() && !c.now().Before(e.expireAt) {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.mu.Unlock()
+	if ok {
+		var value T
+		if err := json.Unmarshal(e.data, &value); err == nil {
+			return value, nil
+		}
+	}
+
+	value, err := fetch()
+	if err != nil {
+		return value, err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value, nil
+	}
+	e = cacheEntry{fn: fn, data: data}
+	if c.policy.TTL > 0 {
+		e.expireAt = c.now().Add(time.Duration(c.policy.TTL) * time.Second)
+	}
+	c.mu.Lock()
+	c.entries[key] = e
+	c.mu.Unlock()
+	return value, nil
+}
+
+// GetCertContainerList 获取证书容器列表
+func (c *CachedDataAccess) GetCertContainerList(projectID int) ([]*CertContainerInfo, error) {
+	return cached(c, "db_get_cert_container_list", func() ([]*CertContainerInfo, error) {
+		return c.DataAccess.GetCertContainerList(projectID)
+	}, projectID)
+}
+
+// GetCertAssetListOfContainer 获取证书资产列表
+func (c *CachedDataAccess) GetCertAssetListOfContainer(projectID, containerID int) ([]*CertAssetInfo, error) {
+	return cached(c, "db_get_cert_asset_list_of_container", func() ([]*CertAssetInfo, error) {
+		return c.DataAccess.GetCertAssetListOfContainer(projectID, containerID)
+	}, projectID, containerID)
+}
+
+// GetCertAssetDetail 获取证书资产详情
+func (c *CachedDataAccess) GetCertAssetDetail(projectID, assetID int) (*CertAssetDetail, error) {
+	return cached(c, "db_get_cert_asset_detail", func() (*CertAssetDetail, error) {
+		return c.DataAccess.GetCertAssetDetail(projectID, assetID)
+	}, projectID, assetID)
+}
+
+// GetCertAssetHistory 获取证书容器的历史证书资产
+func (c *CachedDataAccess) GetCertAssetHistory(projectID, containerID int) ([]*CertAssetHistory, error) {
+	return cached(c, "db_get_cert_asset_history", func() ([]*CertAssetHistory, error) {
+		return c.DataAccess.GetCertAssetHistory(projectID, containerID)
+	}, projectID, containerID)
+}
+
+// GetDeployerList 获取部署器列表
+func (c *CachedDataAccess) GetDeployerList(projectID int, targetID string) ([]*DeployerInfo, error) {
+	return cached(c, "db_get_deployer_list", func() ([]*DeployerInfo, error) {
+		return c.DataAccess.GetDeployerList(projectID, targetID)
+	}, projectID, targetID)
+}
+
+// GetDeployerDetail 获取部署器详情
+func (c *CachedDataAccess) GetDeployerDetail(projectID, deployerID int) (*DeployerDetail, error) {
+	return cached(c, "db_get_deployer_detail", func() (*DeployerDetail, error) {
+		return c.DataAccess.GetDeployerDetail(projectID, deployerID)
+	}, projectID, deployerID)
+}
+
+// GetCheckTargetList 获取检测目标列表
+func (c *CachedDataAccess) GetCheckTargetList(projectID int) ([]*CheckTargetInfo, error) {
+	return cached(c, "db_get_check_target_list", func() ([]*CheckTargetInfo, error) {
+		return c.DataAccess.GetCheckTargetList(projectID)
+	}, projectID)
+}
+
+// GetCheckTargetDetail 获取检测目标详情
+func (c *CachedDataAccess) GetCheckTargetDetail(projectID, targetID int) (*CheckTargetDetail, error) {
+	return cached(c, "db_get_check_target_detail", func() (*CheckTargetDetail, error) {
+		return c.DataAccess.GetCheckTargetDetail(projectID, targetID)
+	}, projectID, targetID)
+}
+
+// GetWorkflowSteps 获取工作流步骤列表
+func (c *CachedDataAccess) GetWorkflowSteps(projectID, workflowID int) ([]*WorkflowStepInfo, error) {
+	return cached(c, "db_get_workflow_steps", func() ([]*WorkflowStepInfo, error) {
+		return c.DataAccess.GetWorkflowSteps(projectID, workflowID)
+	}, projectID, workflowID)
+}
+
+// GetNoticeRuleList 获取告警规则列表
+func (c *CachedDataAccess) GetNoticeRuleList(projectID int) ([]*NoticeRuleInfo, error) {
+	return cached(c, "db_get_notice_rule_list", func() ([]*NoticeRuleInfo, error) {
+		return c.DataAccess.GetNoticeRuleList(projectID)
+	}, projectID)
+}
+
+// GetNoticeRuleDetail 获取告警规则详情
+func (c *CachedDataAccess) GetNoticeRuleDetail(projectID, ruleID int) (*NoticeRuleDetail, error) {
+	return cached(c, "db_get_notice_rule_detail", func() (*NoticeRuleDetail, error) {
+		return c.DataAccess.GetNoticeRuleDetail(projectID, ruleID)
+	}, projectID, ruleID)
+}
+
+// CreateCertContainer 创建证书容器，成功后清空缓存
+func (c *CachedDataAccess) CreateCertContainer(projectID int, req *CreateCertContainerRequest) (*CertContainerInfo, error) {
+	info, err := c.DataAccess.CreateCertContainer(projectID, req)
+	if err == nil {
+		c.Invalidate()
+	}
+	return info, err
+}
+
+// ImportCertAsset 导入证书资产，成功后清空缓存
+func (c *CachedDataAccess) ImportCertAsset(projectID, containerID int, data *CertOutputData) (*CertAssetInfo, error) {
+	info, err := c.DataAccess.ImportCertAsset(projectID, containerID, data)
+	if err == nil {
+		c.Invalidate()
+	}
+	return info, err
+}
+
+// RevokeCertAsset 标记证书资产已吊销，成功后清空缓存
+func (c *CachedDataAccess) RevokeCertAsset(projectID, assetID int, reason string) error {
+	err := c.DataAccess.RevokeCertAsset(projectID, assetID, reason)
+	if err == nil {
+		c.Invalidate()
+	}
+	return err
+}
+
+// AttachLabels 添加标签，成功后清空缓存
+func (c *CachedDataAccess) AttachLabels(projectID int, resource ResourceType, id int, labels map[string]string) error {
+	err := c.DataAccess.AttachLabels(projectID, resource, id, labels)
+	if err == nil {
+		c.Invalidate()
+	}
+	return err
+}
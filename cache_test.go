@@ -0,0 +1,127 @@
+package certm
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+type countingAccess struct {
+	DataAccess
+	calls int
+	err   error
+}
+
+func (c *countingAccess) GetDeployerDetail(projectID, deployerID int) (*DeployerDetail, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &DeployerDetail{DeployerInfo: DeployerInfo{ID: deployerID}}, nil
+}
+
+func (c *countingAccess) RevokeCertAsset(projectID, assetID int, reason string) error {
+	return nil
+}
+
+func TestCachedDataAccess(t *testing.T) {
+	access := &countingAccess{}
+	cache := NewCachedDataAccess(access, nil)
+
+	for range 3 {
+		if d, err := cache.GetDeployerDetail(1, 7); err != nil || d.ID != 7 {
+			t.Fatalf("detail = %+v, err = %v", d, err)
+		}
+	}
+	_, _ = cache.GetDeployerDetail(1, 8)
+	if access.calls != 2 {
+		t.Errorf("calls = %d, want 2", access.calls)
+	}
+
+	// 按函数名清空
+	InvalidateDataCache(SetContextKey(context.Background(), cache, "zh-CN", 1), "db_get_deployer_detail")
+	_, _ = cache.GetDeployerDetail(1, 7)
+	if access.calls != 3 {
+		t.Errorf("calls after invalidate = %d, want 3", access.calls)
+	}
+
+	// 写入后清空
+	_ = cache.RevokeCertAsset(1, 1, "superseded")
+	_, _ = cache.GetDeployerDetail(1, 7)
+	if access.calls != 4 {
+		t.Errorf("calls after write = %d, want 4", access.calls)
+	}
+
+	// 错误不缓存
+	access.err = errors.New("host unavailable")
+	cache.Invalidate()
+	_, _ = cache.GetDeployerDetail(1, 7)
+	_, _ = cache.GetDeployerDetail(1, 7)
+	if access.calls != 6 {
+		t.Errorf("calls with error = %d, want 6", access.calls)
+	}
+}
+
+func (c *countingAccess) GetCertContainerList(projectID int) ([]*CertContainerInfo, error) {
+	c.calls++
+	return []*CertContainerInfo{{ID: 1, CommonName: "a.example.com"}, {ID: 2, CommonName: "b.example.com"}}, nil
+}
+
+func TestCachedDataAccess_Copy(t *testing.T) {
+	access := &countingAccess{}
+	cache := NewCachedDataAccess(access, nil)
+
+	// 修改返回的对象或列表不影响后续查询
+	for range 2 {
+		d, _ := cache.GetDeployerDetail(1, 7)
+		if d.ID != 7 || d.Name != "" {
+			t.Fatalf("detail = %+v", d)
+		}
+		d.Name = "changed"
+		list, _ := cache.GetCertContainerList(1)
+		if len(list) != 2 || list[0].CommonName != "a.example.com" {
+			t.Fatalf("list = %+v", list)
+		}
+		list[0].CommonName = "changed"
+		slices.Reverse(list)
+	}
+	if access.calls != 2 {
+		t.Errorf("calls = %d, want 2", access.calls)
+	}
+}
+
+func TestCachePolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy *CachePolicy
+		calls  int
+	}{
+		{"disabled", &CachePolicy{Disabled: true}, 2},
+		{"no cache", &CachePolicy{NoCache: []string{"db_get_deployer_detail"}}, 2},
+		{"ttl", &CachePolicy{TTL: 60}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			access := &countingAccess{}
+			cache := NewCachedDataAccess(access, tt.policy)
+			_, _ = cache.GetDeployerDetail(1, 7)
+			_, _ = cache.GetDeployerDetail(1, 7)
+			if access.calls != tt.calls {
+				t.Errorf("calls = %d, want %d", access.calls, tt.calls)
+			}
+		})
+	}
+
+	access := &countingAccess{}
+	cache := NewCachedDataAccess(access, &CachePolicy{TTL: 60})
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	_, _ = cache.GetDeployerDetail(1, 7)
+	now = now.Add(time.Minute)
+	_, _ = cache.GetDeployerDetail(1, 7)
+	if access.calls != 2 {
+		t.Errorf("calls after expiry = %d, want 2", access.calls)
+	}
+}
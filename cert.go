@@ -0,0 +1,201 @@
+package certm
+
+import (
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/trustasia-com/certm-plugin-sdk/certutil"
+)
+
+// pemChain 证书链PEM，第一个证书为叶子证书
+type pemChain []string
+
+// certificates 解析证书链
+func (p pemChain) certificates() ([]*x509.Certificate, error) {
+	return certutil.ParseCertificatesPEM(p...)
+}
+
+// leaf 解析叶子证书
+func (p pemChain) leaf() (*x509.Certificate, error) {
+	certs, err := p.certificates()
+	if err != nil {
+		return nil, err
+	}
+	return certs[0], nil
+}
+
+// intermediates 解析中间证书
+func (p pemChain) intermediates() ([]*x509.Certificate, error) {
+	certs, err := p.certificates()
+	if err != nil {
+		return nil, err
+	}
+	return certs[1:], nil
+}
+
+// sans 叶子证书的主题备用名称
+func (p pemChain) sans() ([]string, error) {
+	leaf, err := p.leaf()
+	if err != nil {
+		return nil, err
+	}
+	return certutil.SANs(leaf), nil
+}
+
+// issuer 叶子证书的颁发者
+func (p pemChain) issuer() (string, error) {
+	leaf, err := p.leaf()
+	if err != nil {
+		return "", err
+	}
+	return leaf.Issuer.String(), nil
+}
+
+// serial 叶子证书序列号
+func (p pemChain) serial() (string, error) {
+	leaf, err := p.leaf()
+	if err != nil {
+		return "", err
+	}
+	return certutil.SerialHex(leaf), nil
+}
+
+// keyType 叶子证书公钥类型
+func (p pemChain) keyType() (string, error) {
+	leaf, err := p.leaf()
+	if err != nil {
+		return "", err
+	}
+	return certutil.KeyType(leaf.PublicKey), nil
+}
+
+// notBefore 叶子证书生效时间
+func (p pemChain) notBefore() (time.Time, error) {
+	leaf, err := p.leaf()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return leaf.NotBefore, nil
+}
+
+// fingerprint 叶子证书指纹
+func (p pemChain) fingerprint(algo certutil.FingerprintAlgo) (string, error) {
+	leaf, err := p.leaf()
+	if err != nil {
+		return "", err
+	}
+	return certutil.Fingerprint(leaf, algo)
+}
+
+// verifySHA1 校验叶子证书SHA1与声明值是否一致
+func (p pemChain) verifySHA1(declared string) error {
+	actual, err := p.fingerprint(certutil.FingerprintSHA1)
+	if err != nil {
+		return err
+	}
+	if certutil.NormalizeFingerprint(declared) != actual {
+		return fmt.Errorf("sha1 mismatch: declared %s, computed %s", declared, actual)
+	}
+	return nil
+}
+
+// Certificates 解析证书链中的全部证书
+func (c *CertOutputData) Certificates() ([]*x509.Certificate, error) {
+	return pemChain(c.ChainPEM).certificates()
+}
+
+// Leaf 解析叶子证书
+func (c *CertOutputData) Leaf() (*x509.Certificate, error) {
+	return pemChain(c.ChainPEM).leaf()
+}
+
+// Intermediates 解析中间证书
+func (c *CertOutputData) Intermediates() ([]*x509.Certificate, error) {
+	return pemChain(c.ChainPEM).intermediates()
+}
+
+// SANs 获取叶子证书的主题备用名称
+func (c *CertOutputData) SANs() ([]string, error) {
+	return pemChain(c.ChainPEM).sans()
+}
+
+// Issuer 获取叶子证书的颁发者
+func (c *CertOutputData) Issuer() (string, error) {
+	return pemChain(c.ChainPEM).issuer()
+}
+
+// Serial 获取叶子证书序列号（十六进制）
+func (c *CertOutputData) Serial() (string, error) {
+	return pemChain(c.ChainPEM).serial()
+}
+
+// KeyType 获取叶子证书公钥类型，如 RSA2048、ECDSA256
+func (c *CertOutputData) KeyType() (string, error) {
+	return pemChain(c.ChainPEM).keyType()
+}
+
+// NotBefore 获取叶子证书生效时间
+func (c *CertOutputData) NotBefore() (time.Time, error) {
+	return pemChain(c.ChainPEM).notBefore()
+}
+
+// Fingerprint 计算叶子证书指纹（小写十六进制）
+func (c *CertOutputData) Fingerprint(algo certutil.FingerprintAlgo) (string, error) {
+	return pemChain(c.ChainPEM).fingerprint(algo)
+}
+
+// VerifySHA1 校验计算出的SHA1是否与 SHA1 字段一致
+func (c *CertOutputData) VerifySHA1() error {
+	return pemChain(c.ChainPEM).verifySHA1(c.SHA1)
+}
+
+// Certificates 解析证书链中的全部证书
+func (c *CertAssetDetail) Certificates() ([]*x509.Certificate, error) {
+	return pemChain(c.ChainPEM).certificates()
+}
+
+// Leaf 解析叶子证书
+func (c *CertAssetDetail) Leaf() (*x509.Certificate, error) {
+	return pemChain(c.ChainPEM).leaf()
+}
+
+// Intermediates 解析中间证书
+func (c *CertAssetDetail) Intermediates() ([]*x509.Certificate, error) {
+	return pemChain(c.ChainPEM).intermediates()
+}
+
+// SANs 获取叶子证书的主题备用名称
+func (c *CertAssetDetail) SANs() ([]string, error) {
+	return pemChain(c.ChainPEM).sans()
+}
+
+// Issuer 获取叶子证书的颁发者
+func (c *CertAssetDetail) Issuer() (string, error) {
+	return pemChain(c.ChainPEM).issuer()
+}
+
+// Serial 获取叶子证书序列号（十六进制）
+func (c *CertAssetDetail) Serial() (string, error) {
+	return pemChain(c.ChainPEM).serial()
+}
+
+// KeyType 获取叶子证书公钥类型，如 RSA2048、ECDSA256
+func (c *CertAssetDetail) KeyType() (string, error) {
+	return pemChain(c.ChainPEM).keyType()
+}
+
+// NotBefore 获取叶子证书生效时间
+func (c *CertAssetDetail) NotBefore() (time.Time, error) {
+	return pemChain(c.ChainPEM).notBefore()
+}
+
+// Fingerprint 计算叶子证书指纹（小写十六进制）
+func (c *CertAssetDetail) Fingerprint(algo certutil.FingerprintAlgo) (string, error) {
+	return pemChain(c.ChainPEM).fingerprint(algo)
+}
+
+// VerifySHA1 校验计算出的SHA1是否与 SHA1 字段一致
+func (c *CertAssetDetail) VerifySHA1() error {
+	return pemChain(c.ChainPEM).verifySHA1(c.SHA1)
+}
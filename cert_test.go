@@ -0,0 +1,133 @@
+package certm
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/trustasia-com/certm-plugin-sdk/certutil"
+)
+
+// newTestChain 生成 叶子 + 中间 证书链PEM
+func newTestChain(t *testing.T) (leafDER []byte, chain []string, notBefore time.Time) {
+	t.Helper()
+
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+
+	leafKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	notBefore = time.Now().Add(-time.Minute).Truncate(time.Second).UTC()
+	leafTmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(0x1234),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		DNSNames:     []string{"example.com", "www.example.com"},
+		IPAddresses:  []net.IP{net.ParseIP("192.0.2.1")},
+		NotBefore:    notBefore,
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	leafDER, err = x509.CreateCertificate(rand.Reader, leafTmpl, ca, &leafKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain = []string{
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDER})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})),
+	}
+	return leafDER, chain, notBefore
+}
+
+func TestCertOutputData_Inspect(t *testing.T) {
+	leafDER, chain, notBefore := newTestChain(t)
+	sum := sha1.Sum(leafDER)
+	data := &CertOutputData{SHA1: strings.ToUpper(hex.EncodeToString(sum[:])), ChainPEM: chain}
+
+	leaf, err := data.Leaf()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leaf.Subject.CommonName != "example.com" {
+		t.Errorf("Expected leaf CN example.com, got %s", leaf.Subject.CommonName)
+	}
+
+	intermediates, err := data.Intermediates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(intermediates) != 1 {
+		t.Errorf("Expected 1 intermediate, got %d", len(intermediates))
+	}
+
+	sans, _ := data.SANs()
+	if strings.Join(sans, ",") != "example.com,www.example.com,192.0.2.1" {
+		t.Errorf("Unexpected SANs: %v", sans)
+	}
+
+	if issuer, _ := data.Issuer(); issuer != "CN=Test CA" {
+		t.Errorf("Expected issuer CN=Test CA, got %s", issuer)
+	}
+	if serial, _ := data.Serial(); serial != "1234" {
+		t.Errorf("Expected serial 1234, got %s", serial)
+	}
+	if keyType, _ := data.KeyType(); keyType != "ECDSA256" {
+		t.Errorf("Expected key type ECDSA256, got %s", keyType)
+	}
+	if nb, _ := data.NotBefore(); !nb.Equal(notBefore) {
+		t.Errorf("Expected not before %v, got %v", notBefore, nb)
+	}
+
+	fp, err := data.Fingerprint(certutil.FingerprintSHA256)
+	if err != nil || len(fp) != 64 {
+		t.Errorf("Unexpected sha256 fingerprint %q: %v", fp, err)
+	}
+
+	if err := data.VerifySHA1(); err != nil {
+		t.Errorf("Expected SHA1 to match, got %v", err)
+	}
+	data.SHA1 = "0000"
+	if err := data.VerifySHA1(); err == nil {
+		t.Error("Expected SHA1 mismatch error")
+	}
+}
+
+func TestCertAssetDetail_Inspect(t *testing.T) {
+	_, chain, _ := newTestChain(t)
+
+	// 证书链合并在同一个PEM字符串中
+	detail := &CertAssetDetail{ChainPEM: []string{chain[0] + chain[1]}}
+	certs, err := detail.Certificates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(certs) != 2 {
+		t.Errorf("Expected 2 certificates, got %d", len(certs))
+	}
+
+	empty := &CertAssetDetail{}
+	if _, err := empty.Leaf(); err != certutil.ErrNoCertificate {
+		t.Errorf("Expected ErrNoCertificate, got %v", err)
+	}
+}
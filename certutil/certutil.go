@@ -0,0 +1,159 @@
+// Package certutil 提供证书、私钥的PEM解析及常用属性提取工具
+package certutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNoCertificate 未找到证书
+var ErrNoCertificate = errors.New("no certificate found")
+
+// FingerprintAlgo 指纹算法
+type FingerprintAlgo string
+
+// String 实现 Stringer 接口
+func (f FingerprintAlgo) String() string {
+	return string(f)
+}
+
+const (
+	FingerprintSHA1   FingerprintAlgo = "sha1"   // SHA1指纹
+	FingerprintSHA256 FingerprintAlgo = "sha256" // SHA256指纹
+)
+
+// ParseCertificatesPEM 解析PEM格式证书，每个字符串可包含多个证书块
+func ParseCertificatesPEM(pems ...string) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for _, s := range pems {
+		rest := []byte(s)
+		for {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("parse certificate: %w", err)
+			}
+			certs = append(certs, cert)
+		}
+	}
+	if len(certs) == 0 {
+		return nil, ErrNoCertificate
+	}
+	return certs, nil
+}
+
+// ParsePrivateKeyPEM 解析PEM格式私钥，支持PKCS#8、PKCS#1及SEC1格式
+func ParsePrivateKeyPEM(keyPEM string) (crypto.Signer, error) {
+	rest := []byte(keyPEM)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return nil, errors.New("no private key found")
+		}
+
+		var (
+			key any
+			err error
+		)
+		switch block.Type {
+		case "PRIVATE KEY":
+			key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		case "RSA PRIVATE KEY":
+			key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY":
+			key, err = x509.ParseECPrivateKey(block.Bytes)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse private key: %w", err)
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type: %T", key)
+		}
+		return signer, nil
+	}
+}
+
+// EncodeCertificatePEM 将证书编码为PEM
+func EncodeCertificatePEM(cert *x509.Certificate) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+}
+
+// Fingerprint 计算证书指纹，返回小写十六进制字符串
+func Fingerprint(cert *x509.Certificate, algo FingerprintAlgo) (string, error) {
+	switch algo {
+	case FingerprintSHA1:
+		sum := sha1.Sum(cert.Raw)
+		return hex.EncodeToString(sum[:]), nil
+	case FingerprintSHA256:
+		sum := sha256.Sum256(cert.Raw)
+		return hex.EncodeToString(sum[:]), nil
+	default:
+		return "", fmt.Errorf("unsupported fingerprint algorithm: %s", algo)
+	}
+}
+
+// NormalizeFingerprint 规范化指纹字符串：去除冒号和空白并转为小写
+func NormalizeFingerprint(fp string) string {
+	fp = strings.TrimSpace(fp)
+	fp = strings.ReplaceAll(fp, ":", "")
+	fp = strings.ReplaceAll(fp, " ", "")
+	return strings.ToLower(fp)
+}
+
+// KeyType 返回公钥类型描述，如 RSA2048、ECDSA256、Ed25519
+func KeyType(pub any) string {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return fmt.Sprintf("RSA%d", k.N.BitLen())
+	case *ecdsa.PublicKey:
+		return fmt.Sprintf("ECDSA%d", k.Curve.Params().BitSize)
+	case ed25519.PublicKey:
+		return "Ed25519"
+	default:
+		return "Unknown"
+	}
+}
+
+// SANs 返回证书的全部主题备用名称（DNS、IP、邮箱、URI）
+func SANs(cert *x509.Certificate) []string {
+	sans := make([]string, 0, len(cert.DNSNames)+len(cert.IPAddresses))
+	sans = append(sans, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	sans = append(sans, cert.EmailAddresses...)
+	for _, u := range cert.URIs {
+		sans = append(sans, u.String())
+	}
+	return sans
+}
+
+// SerialHex 返回证书序列号的小写十六进制表示
+func SerialHex(cert *x509.Certificate) string {
+	b := cert.SerialNumber.Bytes()
+	if len(b) == 0 {
+		return "00"
+	}
+	return hex.EncodeToString(b)
+}
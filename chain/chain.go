@@ -0,0 +1,389 @@
+// Package chain 提供证书链排序、去重、补全及校验工具
+package chain
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	certm "github.com/trustasia-com/certm-plugin-sdk"
+	"github.com/trustasia-com/certm-plugin-sdk/certutil"
+)
+
+var (
+	ErrEmptyChain   = errors.New("certificate chain is empty")                      // 证书链为空
+	ErrNoPrivateKey = errors.New("private key is empty")                            // 私钥为空
+	ErrKeyMismatch  = errors.New("private key does not match the leaf certificate") // 私钥与叶子证书不匹配
+)
+
+// MultipleLeavesError 证书链包含多个互不相关的叶子证书
+type MultipleLeavesError struct {
+	Subjects []string
+}
+
+func (e *MultipleLeavesError) Error() string {
+	return fmt.Sprintf("certificate chain contains multiple leaf certificates: %s", strings.Join(e.Subjects, "; "))
+}
+
+// GapError 证书链缺少中间证书
+type GapError struct {
+	Subject string   // 缺少上级证书的证书主题
+	Issuer  string   // 缺失的颁发者
+	AIAURLs []string // 颁发者证书下载地址
+	Cause   error    // AIA下载失败原因
+}
+
+func (e *GapError) Error() string {
+	msg := fmt.Sprintf("certificate chain is incomplete: issuer %q of %q not found", e.Issuer, e.Subject)
+	if e.Cause != nil {
+		msg += ": " + e.Cause.Error()
+	}
+	return msg
+}
+
+// Unwrap 返回AIA下载失败原因
+func (e *GapError) Unwrap() error {
+	return e.Cause
+}
+
+// Options 证书链处理选项
+type Options struct {
+	// HTTPClient 不为空时通过AIA下载缺失的中间证书，忽略 ldap 等非 http(s) 地址
+	HTTPClient certm.HTTPClient
+	// Roots 受信任的根证书，与 trust.Input.Roots 一致，用于判断证书链是否已完整，为空时仅要求链末端为CA证书
+	Roots []*x509.Certificate
+	// KeepRoot 是否保留根证书
+	KeepRoot bool
+	// MaxFetch 最多通过AIA下载的证书数量，默认为 3
+	MaxFetch int
+}
+
+// Result 证书链处理结果
+type Result struct {
+	Leaf          *x509.Certificate   // 叶子证书
+	Intermediates []*x509.Certificate // 中间证书，按签发顺序排列
+	Root          *x509.Certificate   // 根证书，仅 KeepRoot 时输出到 Chain()
+	Fetched       []*x509.Certificate // 通过AIA补全的证书
+	Unused        []*x509.Certificate // 与叶子证书无关而被移除的证书
+	Duplicates    int                 // 移除的重复证书数量
+	keepRoot      bool
+}
+
+// Chain 返回叶子优先的证书链
+func (r *Result) Chain() []*x509.Certificate {
+	chain := make([]*x509.Certificate, 0, len(r.Intermediates)+2)
+	chain = append(chain, r.Leaf)
+	chain = append(chain, r.Intermediates...)
+	if r.keepRoot && r.Root != nil {
+		chain = append(chain, r.Root)
+	}
+	return chain
+}
+
+// ChainPEM 返回叶子优先的证书链PEM，可直接用于 CertOutputData.ChainPEM
+func (r *Result) ChainPEM() []string {
+	chain := r.Chain()
+	pems := make([]string, 0, len(chain))
+	for _, cert := range chain {
+		pems = append(pems, certutil.EncodeCertificatePEM(cert))
+	}
+	return pems
+}
+
+// Normalize 对证书数据的证书链进行排序、补全，并校验私钥与叶子证书是否匹配
+func Normalize(data *certm.CertOutputData, opts *Options) (*Result, error) {
+	if len(data.ChainPEM) == 0 {
+		return nil, ErrEmptyChain
+	}
+	certs, err := certutil.ParseCertificatesPEM(data.ChainPEM...)
+	if err != nil {
+		return nil, err
+	}
+	result, err := Build(certs, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := VerifyKeyPair(data.KeyPEM, result.Leaf); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Build 将证书按叶子优先排序，移除重复证书和根证书，并检测缺失的中间证书
+func Build(certs []*x509.Certificate, opts *Options) (*Result, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	maxFetch := opts.MaxFetch
+	if maxFetch <= 0 {
+		maxFetch = 3
+	}
+
+	result := &Result{keepRoot: opts.KeepRoot}
+	pool := dedupe(certs, result)
+	if len(pool) == 0 {
+		return nil, ErrEmptyChain
+	}
+
+	leaf, err := findLeaf(pool)
+	if err != nil {
+		return nil, err
+	}
+	result.Leaf = leaf
+
+	used := map[*x509.Certificate]bool{leaf: true}
+	current := leaf
+	for {
+		if isSelfSigned(current) {
+			if current != leaf {
+				result.Root = current
+				result.Intermediates = result.Intermediates[:len(result.Intermediates)-1]
+			}
+			break
+		}
+
+		issuer := findIssuer(current, pool, used)
+		if issuer == nil {
+			if isComplete(current, result, opts.Roots) {
+				break
+			}
+
+			var fetchErr error
+			if opts.HTTPClient != nil && len(result.Fetched) < maxFetch {
+				issuer, fetchErr = fetchIssuer(opts.HTTPClient, current)
+			}
+			if issuer == nil {
+				return nil, &GapError{
+					Subject: current.Subject.String(),
+					Issuer:  current.Issuer.String(),
+					AIAURLs: current.IssuingCertificateURL,
+					Cause:   fetchErr,
+				}
+			}
+			result.Fetched = append(result.Fetched, issuer)
+		}
+
+		used[issuer] = true
+		result.Intermediates = append(result.Intermediates, issuer)
+		current = issuer
+	}
+
+	for _, cert := range pool {
+		if !used[cert] {
+			result.Unused = append(result.Unused, cert)
+		}
+	}
+	return result, nil
+}
+
+// VerifyKeyPair 校验私钥与叶子证书公钥是否匹配
+func VerifyKeyPair(keyPEM string, leaf *x509.Certificate) error {
+	if keyPEM == "" {
+		return ErrNoPrivateKey
+	}
+	signer, err := certutil.ParsePrivateKeyPEM(keyPEM)
+	if err != nil {
+		return err
+	}
+	pub, ok := leaf.PublicKey.(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(signer.Public()) {
+		return ErrKeyMismatch
+	}
+	return nil
+}
+
+// dedupe 去除重复证书
+func dedupe(certs []*x509.Certificate, result *Result) []*x509.Certificate {
+	pool := make([]*x509.Certificate, 0, len(certs))
+	for _, cert := range certs {
+		if cert == nil {
+			continue
+		}
+		duplicate := false
+		for _, c := range pool {
+			if bytes.Equal(c.Raw, cert.Raw) {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			result.Duplicates++
+			continue
+		}
+		pool = append(pool, cert)
+	}
+	return pool
+}
+
+// findLeaf 查找叶子证书：不是任何其他证书颁发者的证书
+func findLeaf(pool []*x509.Certificate) (*x509.Certificate, error) {
+	var candidates []*x509.Certificate
+	for _, cert := range pool {
+		isParent := false
+		for _, other := range pool {
+			if other != cert && isIssuer(cert, other) {
+				isParent = true
+				break
+			}
+		}
+		if !isParent {
+			candidates = append(candidates, cert)
+		}
+	}
+
+	// 优先选择非CA证书
+	var leaves []*x509.Certificate
+	for _, cert := range candidates {
+		if !cert.IsCA {
+			leaves = append(leaves, cert)
+		}
+	}
+	if len(leaves) == 0 {
+		leaves = candidates
+	}
+
+	switch len(leaves) {
+	case 0:
+		// 证书间互相签发，取第一个证书
+		return pool[0], nil
+	case 1:
+		return leaves[0], nil
+	default:
+		subjects := make([]string, 0, len(leaves))
+		for _, cert := range leaves {
+			subjects = append(subjects, cert.Subject.String())
+		}
+		return nil, &MultipleLeavesError{Subjects: subjects}
+	}
+}
+
+// findIssuer 在证书池中查找颁发者
+func findIssuer(cert *x509.Certificate, pool []*x509.Certificate, used map[*x509.Certificate]bool) *x509.Certificate {
+	for _, candidate := range pool {
+		if !used[candidate] && isIssuer(candidate, cert) {
+			return candidate
+		}
+	}
+	return nil
+}
+
+// isComplete 判断证书链在 cert 处结束是否完整
+func isComplete(cert *x509.Certificate, result *Result, roots []*x509.Certificate) bool {
+	if len(roots) == 0 {
+		// 未提供根证书时，链末端为中间证书即认为完整
+		return cert != result.Leaf && cert.IsCA
+	}
+
+	rootPool := x509.NewCertPool()
+	for _, c := range roots {
+		rootPool.AddCert(c)
+	}
+	intermediates := x509.NewCertPool()
+	for _, c := range result.Intermediates {
+		intermediates.AddCert(c)
+	}
+	_, err := result.Leaf.Verify(x509.VerifyOptions{
+		Roots:         rootPool,
+		Intermediates: intermediates,
+		CurrentTime:   result.Leaf.NotBefore,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err == nil
+}
+
+// fetchIssuer 通过AIA下载颁发者证书，仅支持 http(s) 地址
+func fetchIssuer(client certm.HTTPClient, cert *x509.Certificate) (*x509.Certificate, error) {
+	var urls []string
+	for _, url := range cert.IssuingCertificateURL {
+		if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+			urls = append(urls, url)
+		}
+	}
+	if len(urls) == 0 {
+		return nil, errors.New("no http AIA issuer URL")
+	}
+
+	var lastErr error
+	for _, url := range urls {
+		body, err := certm.HTTPGet(client, url)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		certs, err := parseFetched(body)
+		if err != nil {
+			lastErr = fmt.Errorf("parse %s: %w", url, err)
+			continue
+		}
+		lastErr = fmt.Errorf("certificate from %s is not the issuer", url)
+		for _, issuer := range certs {
+			if isIssuer(issuer, cert) {
+				return issuer, nil
+			}
+		}
+	}
+	return nil, lastErr
+}
+
+var oidSignedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+
+// pkcs7ContentInfo PKCS#7 ContentInfo
+type pkcs7ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"tag:0,explicit,optional"`
+}
+
+// pkcs7SignedData PKCS#7 SignedData，仅解析证书
+type pkcs7SignedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	ContentInfo      asn1.RawValue
+	Certificates     asn1.RawValue `asn1:"tag:0,optional"`
+	CRLs             asn1.RawValue `asn1:"tag:1,optional"`
+	SignerInfos      asn1.RawValue
+}
+
+// parseFetched 解析AIA下载的证书，支持DER、PEM及PKCS#7（.p7c）格式
+func parseFetched(body []byte) ([]*x509.Certificate, error) {
+	if block, _ := pem.Decode(body); block != nil {
+		body = block.Bytes
+	}
+	if cert, err := x509.ParseCertificate(body); err == nil {
+		return []*x509.Certificate{cert}, nil
+	}
+
+	var info pkcs7ContentInfo
+	if _, err := asn1.Unmarshal(body, &info); err != nil || !info.ContentType.Equal(oidSignedData) {
+		return nil, errors.New("unsupported format, expected DER, PEM or PKCS#7 certificates")
+	}
+	var signed pkcs7SignedData
+	if _, err := asn1.Unmarshal(info.Content.Bytes, &signed); err != nil {
+		return nil, fmt.Errorf("pkcs7: %w", err)
+	}
+	certs, err := x509.ParseCertificates(signed.Certificates.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("pkcs7: %w", err)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("pkcs7: no certificates")
+	}
+	return certs, nil
+}
+
+// isIssuer 判断 parent 是否为 child 的颁发者
+func isIssuer(parent, child *x509.Certificate) bool {
+	if !bytes.Equal(parent.RawSubject, child.RawIssuer) {
+		return false
+	}
+	return child.CheckSignatureFrom(parent) == nil
+}
+
+// isSelfSigned 判断是否为自签名证书
+func isSelfSigned(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawSubject, cert.RawIssuer) && cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature) == nil
+}
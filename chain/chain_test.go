@@ -0,0 +1,210 @@
+package chain
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	certm "github.com/trustasia-com/certm-plugin-sdk"
+	"github.com/trustasia-com/certm-plugin-sdk/certutil"
+	"github.com/trustasia-com/certm-plugin-sdk/internal/testpki"
+)
+
+type testPKI struct {
+	*testpki.PKI
+	leaf    *x509.Certificate
+	leafKey *ecdsa.PrivateKey
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	pki := testpki.New(t)
+	leaf, leafKey := testpki.Issue(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(3),
+		Subject:               pkix.Name{CommonName: "example.com"},
+		DNSNames:              []string{"example.com"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IssuingCertificateURL: []string{"http://ca.example.com/inter.cer"},
+	}, pki.Inter, pki.InterKey)
+	return &testPKI{PKI: pki, leaf: leaf, leafKey: leafKey}
+}
+
+func keyPEM(t *testing.T, key *ecdsa.PrivateKey) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+type fakeHTTP map[string][]byte
+
+func (f fakeHTTP) HTTPDo(req *certm.HTTPRequest) (*certm.HTTPResponse, error) {
+	body, ok := f[req.URL]
+	if !ok {
+		return &certm.HTTPResponse{StatusCode: 404}, nil
+	}
+	return &certm.HTTPResponse{StatusCode: 200, Body: body}, nil
+}
+
+func TestBuild_Reorder(t *testing.T) {
+	pki := newTestPKI(t)
+
+	// 乱序、重复并包含根证书
+	result, err := Build([]*x509.Certificate{pki.Root, pki.Inter, pki.leaf, pki.Inter}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := result.Chain()
+	if len(chain) != 2 || chain[0] != pki.leaf || chain[1] != pki.Inter {
+		t.Fatalf("Expected leaf, intermediate; got %d certificates", len(chain))
+	}
+	if result.Root != pki.Root {
+		t.Error("Expected root to be detected")
+	}
+	if result.Duplicates != 1 {
+		t.Errorf("Expected 1 duplicate, got %d", result.Duplicates)
+	}
+
+	result, _ = Build([]*x509.Certificate{pki.Root, pki.leaf, pki.Inter}, &Options{KeepRoot: true})
+	if len(result.Chain()) != 3 {
+		t.Errorf("Expected root to be kept, got %d certificates", len(result.Chain()))
+	}
+}
+
+func TestBuild_Gap(t *testing.T) {
+	pki := newTestPKI(t)
+
+	_, err := Build([]*x509.Certificate{pki.leaf}, nil)
+	var gapErr *GapError
+	if !errors.As(err, &gapErr) {
+		t.Fatalf("Expected GapError, got %v", err)
+	}
+	if gapErr.Issuer != "CN=Test Intermediate" {
+		t.Errorf("Unexpected missing issuer %q", gapErr.Issuer)
+	}
+
+	// 根证书可证明链已完整
+	if _, err := Build([]*x509.Certificate{pki.leaf, pki.Inter}, &Options{Roots: pki.Roots()}); err != nil {
+		t.Errorf("Expected complete chain, got %v", err)
+	}
+}
+
+func TestBuild_AIAFetch(t *testing.T) {
+	pki := newTestPKI(t)
+	client := fakeHTTP{"http://ca.example.com/inter.cer": pki.Inter.Raw}
+
+	result, err := Build([]*x509.Certificate{pki.leaf}, &Options{HTTPClient: client})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Fetched) != 1 || len(result.Intermediates) != 1 {
+		t.Errorf("Expected intermediate fetched via AIA, got %d fetched", len(result.Fetched))
+	}
+
+	_, err = Build([]*x509.Certificate{pki.leaf}, &Options{HTTPClient: fakeHTTP{}})
+	var gapErr *GapError
+	if !errors.As(err, &gapErr) || gapErr.Cause == nil {
+		t.Errorf("Expected GapError with fetch cause, got %v", err)
+	}
+
+	// 仅有 ldap 地址时不发起请求
+	ldapLeaf, _ := testpki.Issue(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(4),
+		Subject:               pkix.Name{CommonName: "example.com"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IssuingCertificateURL: []string{"ldap:///CN=Test%20Intermediate?cACertificate"},
+	}, pki.Inter, pki.InterKey)
+	_, err = Build([]*x509.Certificate{ldapLeaf}, &Options{HTTPClient: client})
+	if !errors.As(err, &gapErr) || gapErr.Cause == nil || !strings.Contains(gapErr.Cause.Error(), "no http") {
+		t.Errorf("Expected GapError without http AIA URL, got %v", err)
+	}
+}
+
+func TestBuild_AIAFetchPKCS7(t *testing.T) {
+	pki := newTestPKI(t)
+	// 颁发者证书以 PKCS#7 certs-only 格式提供，且包含其他证书
+	signed, err := asn1.Marshal(pkcs7SignedData{
+		Version:          1,
+		DigestAlgorithms: asn1.RawValue{Tag: asn1.TagSet, IsCompound: true},
+		ContentInfo:      asn1.RawValue{FullBytes: mustMarshal(t, pkcs7ContentInfo{ContentType: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}})},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: append(pki.Root.Raw, pki.Inter.Raw...)},
+		SignerInfos:      asn1.RawValue{Tag: asn1.TagSet, IsCompound: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p7c := mustMarshal(t, pkcs7ContentInfo{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: signed},
+	})
+
+	result, err := Build([]*x509.Certificate{pki.leaf}, &Options{HTTPClient: fakeHTTP{"http://ca.example.com/inter.cer": p7c}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Fetched) != 1 || result.Fetched[0] != result.Intermediates[0] || !result.Fetched[0].Equal(pki.Inter) {
+		t.Errorf("Expected intermediate fetched from PKCS#7, got %d fetched", len(result.Fetched))
+	}
+
+	_, err = Build([]*x509.Certificate{pki.leaf}, &Options{HTTPClient: fakeHTTP{"http://ca.example.com/inter.cer": []byte("not a certificate")}})
+	var gapErr *GapError
+	if !errors.As(err, &gapErr) || !strings.Contains(gapErr.Cause.Error(), "unsupported format") {
+		t.Errorf("Expected unsupported format error, got %v", err)
+	}
+}
+
+func mustMarshal(t *testing.T, v any) []byte {
+	t.Helper()
+	der, err := asn1.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+func TestNormalize(t *testing.T) {
+	pki := newTestPKI(t)
+	data := &certm.CertOutputData{
+		KeyPEM: keyPEM(t, pki.leafKey),
+		ChainPEM: []string{
+			certutil.EncodeCertificatePEM(pki.Inter),
+			certutil.EncodeCertificatePEM(pki.leaf),
+		},
+	}
+
+	result, err := Normalize(data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pems := result.ChainPEM()
+	if pems[0] != data.ChainPEM[1] {
+		t.Error("Expected leaf certificate first")
+	}
+
+	otherKey := testpki.NewKey(t)
+	data.KeyPEM = keyPEM(t, otherKey)
+	if _, err := Normalize(data, nil); !errors.Is(err, ErrKeyMismatch) {
+		t.Errorf("Expected ErrKeyMismatch, got %v", err)
+	}
+}
+
+func TestBuild_MultipleLeaves(t *testing.T) {
+	a, b := newTestPKI(t), newTestPKI(t)
+
+	_, err := Build([]*x509.Certificate{a.leaf, b.leaf}, nil)
+	var multiErr *MultipleLeavesError
+	if !errors.As(err, &multiErr) {
+		t.Errorf("Expected MultipleLeavesError, got %v", err)
+	}
+}
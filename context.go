@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"time"
+
+	"github.com/trustasia-com/certm-plugin-sdk/helper"
 )
 
 type contextKey string
@@ -47,17 +49,34 @@ type DataAccess interface {
 	// 证书组件访问接口
 	GetCertContainerList(projectID int) ([]*CertContainerInfo, error)
 	GetCertAssetListOfContainer(projectID, containerID int) ([]*CertAssetInfo, error)
+	ListCertContainers(projectID int, opts *ListOptions) (*Page[*CertContainerInfo], error)
+	ListCertAssetsOfContainer(projectID, containerID int, opts *ListOptions) (*Page[*CertAssetInfo], error)
+	FindCertAssets(projectID int, query *CertAssetQuery) ([]*CertAssetInfo, error)
 	GetCertAssetDetail(projectID, assetID int) (*CertAssetDetail, error)
+	GetCertAssetHistory(projectID, containerID int) ([]*CertAssetHistory, error)
+	GetCertAssetsBySHA1(projectID int, sha1s []string) ([]*CertAssetHistory, error)
+
+	// 证书写入接口，需在 plugin.yml 中声明对应权限
+	CreateCertContainer(projectID int, req *CreateCertContainerRequest) (*CertContainerInfo, error)
+	ImportCertAsset(projectID, containerID int, data *CertOutputData) (*CertAssetInfo, error)
+	RevokeCertAsset(projectID, assetID int, reason string) error
+	AttachLabels(projectID int, resource ResourceType, id int, labels map[string]string) error
 
 	// 部署组件访问接口
 	GetDeployerList(projectID int, targetID string) ([]*DeployerInfo, error)
 	GetDeployerDetail(projectID, deployerID int) (*DeployerDetail, error)
 
-	//
 	// 检测组件访问接口
+	GetCheckTargetList(projectID int) ([]*CheckTargetInfo, error)
+	GetCheckTargetDetail(projectID, targetID int) (*CheckTargetDetail, error)
+	GetLatestCheckResults(projectID, targetID int) ([]*CheckEndpointResult, error)
+
+	// 工作流访问接口
+	GetWorkflowSteps(projectID, workflowID int) ([]*WorkflowStepInfo, error)
 
 	// 通知组件访问接口
-	GetNoticeRuleList() ([]*NoticeRuleInfo, error)
+	GetNoticeRuleList(projectID int) ([]*NoticeRuleInfo, error)
+	GetNoticeRuleDetail(projectID, ruleID int) (*NoticeRuleDetail, error)
 }
 
 // CertContainerInfo 证书容器信息
@@ -72,7 +91,8 @@ type CertContainerInfo struct {
 
 // CertAssetInfo 证书资产信息
 type CertAssetInfo struct {
-	ID int `json:"id"`
+	ID          int `json:"id"`
+	ContainerID int `json:"container_id"` // 所属证书容器ID
 
 	SHA1       string    `json:"sha1"`        // 证书SHA1
 	CommonName string    `json:"common_name"` // 通用名称
@@ -87,6 +107,44 @@ type CertAssetDetail struct {
 	ChainPEM []string `json:"chain_pem"` // 证书链PEM，包含叶子证书
 }
 
+// CertAssetHistory 证书资产历史版本
+type CertAssetHistory struct {
+	CertAssetInfo
+
+	NotBefore   time.Time          `json:"not_before"`  // 生效时间
+	Current     bool               `json:"current"`     // 是否为容器当前证书
+	Revoked     bool               `json:"revoked"`     // 是否已标记吊销
+	Deployments []*AssetDeployment `json:"deployments"` // 部署记录
+}
+
+// AssetDeployment 证书资产部署记录
+type AssetDeployment struct {
+	DeployerID   int       `json:"deployer_id"`   // 部署器ID
+	DeployerName string    `json:"deployer_name"` // 部署器名称
+	TargetType   string    `json:"target_type"`   // 目标类型: CDN/LB
+	TargetName   string    `json:"target_name"`   // 目标名称: 域名/IP/节点ID
+	Deployed     bool      `json:"deployed"`      // 是否部署成功
+	Error        string    `json:"error"`         // 错误信息
+	DeployedAt   time.Time `json:"deployed_at"`   // 部署时间
+}
+
+// ResourceType 资源类型
+type ResourceType string
+
+const (
+	ResourceCertContainer ResourceType = "cert_container" // 证书容器
+	ResourceCertAsset     ResourceType = "cert_asset"     // 证书资产
+)
+
+// CreateCertContainerRequest 创建证书容器请求
+type CreateCertContainerRequest struct {
+	CommonName string            `json:"common_name"`        // 通用名称
+	SANs       []string          `json:"sans,omitempty"`     // 主题备用名称
+	KeyAlgo    string            `json:"key_algo,omitempty"` // 密钥算法
+	Remark     string            `json:"remark,omitempty"`   // 备注
+	Labels     map[string]string `json:"labels,omitempty"`   // 标签
+}
+
 // NoticeRuleInfo 告警规则信息
 type DeployerInfo struct {
 	ID int `json:"id"`
@@ -100,21 +158,107 @@ type DeployerInfo struct {
 type DeployerDetail struct {
 	DeployerInfo
 
-	Credentials json.RawMessage `json:"credentials"`
-	Config      json.RawMessage `json:"config"`
+	Type             string          `json:"type"`                        // 部署器类型
+	Credentials      json.RawMessage `json:"credentials"`                 // 凭证，secret 字段为引用
+	Config           json.RawMessage `json:"config"`                      // 配置
+	CredentialSchema []helper.Field  `json:"credential_schema,omitempty"` // 部署器类型声明的凭证Schema
+	ConfigSchema     []helper.Field  `json:"config_schema,omitempty"`     // 部署器类型声明的配置Schema
+}
+
+// CheckTargetInfo 检测目标信息
+type CheckTargetInfo struct {
+	ID int `json:"id"`
+
+	Name          string    `json:"name"`
+	Status        string    `json:"status"`
+	Remark        string    `json:"remark"`
+	LastCheckedAt time.Time `json:"last_checked_at"` // 最近检测时间，未检测时为零值
+}
+
+// CheckTargetDetail 检测目标详情
+type CheckTargetDetail struct {
+	CheckTargetInfo
+
+	Endpoints []string        `json:"endpoints"` // 检测地址，如 example.com:443
+	Config    json.RawMessage `json:"config"`
 }
 
 // WorkflowStepInfo 工作流步骤信息
 type WorkflowStepInfo struct {
 	ID int `json:"id"`
 
-	Name   string          `json:"name"`
-	Config json.RawMessage `json:"config"`
+	Name        string          `json:"name"`
+	ComponentID string          `json:"component_id"` // 组件ID
+	Config      json.RawMessage `json:"config"`
+	Upstream    []int           `json:"upstream"` // 上游步骤ID，其输出作为本步骤输入
 }
 
 // NoticeRuleInfo 告警规则信息
 type NoticeRuleInfo struct {
 	ID int `json:"id"`
 
-	Name string `json:"name"`
+	Name     string         `json:"name"`
+	Enabled  bool           `json:"enabled"`  // 是否启用
+	Severity NoticeSeverity `json:"severity"` // 告警级别
+}
+
+// NoticeSeverity 告警级别
+type NoticeSeverity string
+
+const (
+	NoticeSeverityInfo     NoticeSeverity = "info"     // 提示
+	NoticeSeverityWarning  NoticeSeverity = "warning"  // 警告
+	NoticeSeverityCritical NoticeSeverity = "critical" // 严重
+)
+
+// NoticeEvent 告警事件
+type NoticeEvent string
+
+const (
+	NoticeEventCertExpiring NoticeEvent = "cert_expiring" // 证书即将过期
+	NoticeEventCertExpired  NoticeEvent = "cert_expired"  // 证书已过期
+	NoticeEventTrustChanged NoticeEvent = "trust_changed" // 信任状态变化
+	NoticeEventCertChanged  NoticeEvent = "cert_changed"  // 端点证书变化
+	NoticeEventDeployFailed NoticeEvent = "deploy_failed" // 部署失败
+	NoticeEventCheckFailed  NoticeEvent = "check_failed"  // 检测失败
+)
+
+// NoticeRuleDetail 告警规则详情
+type NoticeRuleDetail struct {
+	NoticeRuleInfo
+
+	Conditions []*NoticeCondition `json:"conditions"`            // 触发条件，满足任一即触发
+	Channels   []*NoticeChannel   `json:"channels"`              // 通知渠道
+	Recipients []*NoticeRecipient `json:"recipients"`            // 接收人
+	QuietHours *QuietHours        `json:"quiet_hours,omitempty"` // 免打扰时段
+}
+
+// NoticeCondition 告警触发条件
+type NoticeCondition struct {
+	Event         NoticeEvent   `json:"event"`                    // 事件
+	ExpireDays    int           `json:"expire_days,omitempty"`    // 剩余天数阈值，仅 cert_expiring
+	TrustStatuses []TrustStatus `json:"trust_statuses,omitempty"` // 关注的信任状态，为空表示全部，仅 trust_changed
+}
+
+// NoticeChannel 通知渠道
+type NoticeChannel struct {
+	ID int `json:"id"`
+
+	Type   string          `json:"type"`   // 渠道类型，如 email/webhook/dingtalk/wecom/feishu/sms
+	Name   string          `json:"name"`   // 渠道名称
+	Config json.RawMessage `json:"config"` // 渠道配置
+}
+
+// NoticeRecipient 告警接收人
+type NoticeRecipient struct {
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+	Phone string `json:"phone,omitempty"`
+}
+
+// QuietHours 免打扰时段，Start 晚于 End 时表示跨越午夜
+type QuietHours struct {
+	Start    string `json:"start"`              // 开始时间，如 22:00
+	End      string `json:"end"`                // 结束时间，如 08:00
+	Timezone string `json:"timezone,omitempty"` // 时区，如 Asia/Shanghai，为空时使用UTC
 }
@@ -0,0 +1,101 @@
+// Package convert 提供证书格式转换：PKCS#12、JKS/JCEKS、DER及PEM组合
+package convert
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"strings"
+
+	certm "github.com/trustasia-com/certm-plugin-sdk"
+	"github.com/trustasia-com/certm-plugin-sdk/certutil"
+)
+
+// ErrNoPrivateKey 证书数据缺少私钥
+var ErrNoPrivateKey = errors.New("private key is required")
+
+// DERFiles DER格式文件
+type DERFiles struct {
+	Leaf          []byte   // 叶子证书
+	Intermediates [][]byte // 中间证书
+	Key           []byte   // PKCS#8私钥，无私钥时为空
+}
+
+// DER 转换为DER格式，适用于F5等需要分别上传证书文件的目标
+func DER(data *certm.CertOutputData) (*DERFiles, error) {
+	certs, err := data.Certificates()
+	if err != nil {
+		return nil, err
+	}
+	files := &DERFiles{Leaf: certs[0].Raw}
+	for _, cert := range certs[1:] {
+		files.Intermediates = append(files.Intermediates, cert.Raw)
+	}
+	if data.KeyPEM != "" {
+		files.Key, err = pkcs8Key(data.KeyPEM)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// FullChainPEM 叶子证书与中间证书组合的PEM，适用于nginx等
+func FullChainPEM(data *certm.CertOutputData) (string, error) {
+	certs, err := data.Certificates()
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, cert := range certs {
+		b.WriteString(certutil.EncodeCertificatePEM(cert))
+	}
+	return b.String(), nil
+}
+
+// CombinedPEM 证书链与私钥组合的PEM，适用于HAProxy等
+func CombinedPEM(data *certm.CertOutputData) (string, error) {
+	chain, err := FullChainPEM(data)
+	if err != nil {
+		return "", err
+	}
+	key, err := KeyPEM(data)
+	if err != nil {
+		return "", err
+	}
+	return chain + key, nil
+}
+
+// KeyPEM 将私钥转换为未加密的PKCS#8 PEM
+func KeyPEM(data *certm.CertOutputData) (string, error) {
+	der, err := pkcs8Key(data.KeyPEM)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+// pkcs8Key 解析私钥并编码为PKCS#8 DER
+func pkcs8Key(keyPEM string) ([]byte, error) {
+	if keyPEM == "" {
+		return nil, ErrNoPrivateKey
+	}
+	key, err := certutil.ParsePrivateKeyPEM(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	return x509.MarshalPKCS8PrivateKey(key)
+}
+
+// chainDER 解析证书链为DER列表
+func chainDER(data *certm.CertOutputData) ([][]byte, error) {
+	certs, err := data.Certificates()
+	if err != nil {
+		return nil, err
+	}
+	ders := make([][]byte, 0, len(certs))
+	for _, cert := range certs {
+		ders = append(ders, cert.Raw)
+	}
+	return ders, nil
+}
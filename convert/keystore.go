@@ -0,0 +1,279 @@
+package convert
+
+import (
+	"bytes"
+	"crypto/des"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	certm "github.com/trustasia-com/certm-plugin-sdk"
+	"github.com/trustasia-com/certm-plugin-sdk/internal/pkcs"
+)
+
+// KeystoreType Java密钥库类型
+type KeystoreType string
+
+// String 实现 Stringer 接口
+func (k KeystoreType) String() string {
+	return string(k)
+}
+
+const (
+	KeystoreJKS   KeystoreType = "jks"   // Sun JKS
+	KeystoreJCEKS KeystoreType = "jceks" // Sun JCEKS
+)
+
+// DefaultKeystoreAlias 默认别名
+const DefaultKeystoreAlias = "certm"
+
+const (
+	jksMagic   = 0xfeedfeed
+	jceksMagic = 0xcececece
+	jksVersion = 2
+
+	jceksIterations = 200000
+)
+
+var (
+	oidJKSKeyProtector        = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 42, 2, 17, 1, 1}
+	oidPBEWithMD5AndTripleDES = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 42, 2, 19, 1}
+)
+
+// KeystoreOptions Java密钥库转换选项
+type KeystoreOptions struct {
+	Type          KeystoreType // 密钥库类型，默认 KeystoreJKS
+	StorePassword string       // 密钥库口令
+	KeyPassword   string       // 私钥口令，为空时与密钥库口令相同
+	Alias         string       // 私钥条目别名，默认 DefaultKeystoreAlias
+	CreatedAt     time.Time    // 条目创建时间，默认当前时间
+}
+
+// Keystore 转换为Java密钥库（JKS/JCEKS），适用于Tomcat等
+func Keystore(data *certm.CertOutputData, opts *KeystoreOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &KeystoreOptions{}
+	}
+	if opts.StorePassword == "" {
+		return nil, errors.New("keystore password is required")
+	}
+	key, err := pkcs8Key(data.KeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	certs, err := chainDER(data)
+	if err != nil {
+		return nil, err
+	}
+
+	keyPassword := opts.KeyPassword
+	if keyPassword == "" {
+		keyPassword = opts.StorePassword
+	}
+	alias := strings.ToLower(opts.Alias)
+	if alias == "" {
+		alias = DefaultKeystoreAlias
+	}
+	createdAt := opts.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	var (
+		magic     uint32
+		protected []byte
+	)
+	switch opts.Type {
+	case KeystoreJCEKS:
+		magic = jceksMagic
+		protected, err = protectJCEKS(key, keyPassword)
+	case KeystoreJKS, "":
+		magic = jksMagic
+		protected, err = protectJKS(key, keyPassword)
+	default:
+		return nil, fmt.Errorf("unsupported keystore type: %s", opts.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writeUint32(&buf, magic)
+	writeUint32(&buf, jksVersion)
+	writeUint32(&buf, 1)
+
+	// 私钥条目
+	writeUint32(&buf, 1)
+	if err := writeUTF(&buf, alias); err != nil {
+		return nil, err
+	}
+	writeUint64(&buf, uint64(createdAt.UnixMilli()))
+	writeUint32(&buf, uint32(len(protected)))
+	buf.Write(protected)
+	writeUint32(&buf, uint32(len(certs)))
+	for _, der := range certs {
+		_ = writeUTF(&buf, "X.509")
+		writeUint32(&buf, uint32(len(der)))
+		buf.Write(der)
+	}
+
+	// 完整性校验
+	digest := keystoreDigest(opts.StorePassword, buf.Bytes())
+	buf.Write(digest)
+	return buf.Bytes(), nil
+}
+
+// keystoreDigest JKS/JCEKS 完整性摘要：SHA1(口令 || "Mighty Aphrodite" || 数据)
+func keystoreDigest(password string, data []byte) []byte {
+	h := sha1.New()
+	h.Write(utf16BE(password))
+	h.Write([]byte("Mighty Aphrodite"))
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// protectJKS 使用JKS私有的SHA1异或方案保护私钥
+func protectJKS(key []byte, password string) ([]byte, error) {
+	passwd := utf16BE(password)
+	salt := make([]byte, sha1.Size)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	encrypted := make([]byte, len(key))
+	digest := salt
+	for offset := 0; offset < len(key); offset += sha1.Size {
+		h := sha1.New()
+		h.Write(passwd)
+		h.Write(digest)
+		digest = h.Sum(nil)
+		for i := 0; i < len(digest) && offset+i < len(key); i++ {
+			encrypted[offset+i] = key[offset+i] ^ digest[i]
+		}
+	}
+
+	check := sha1.New()
+	check.Write(passwd)
+	check.Write(key)
+
+	protected := make([]byte, 0, len(salt)+len(encrypted)+sha1.Size)
+	protected = append(protected, salt...)
+	protected = append(protected, encrypted...)
+	protected = check.Sum(protected)
+
+	return asn1.Marshal(pkcs.EncryptedPrivateKeyInfo{
+		Algorithm:     pkix.AlgorithmIdentifier{Algorithm: oidJKSKeyProtector, Parameters: asn1.NullRawValue},
+		EncryptedData: protected,
+	})
+}
+
+type jcePBEParams struct {
+	Salt       []byte
+	Iterations int
+}
+
+// protectJCEKS 使用 PBEWithMD5AndTripleDES 保护私钥
+func protectJCEKS(key []byte, password string) ([]byte, error) {
+	salt := make([]byte, 8)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	derived := deriveMD5TripleDES(md5.New, latin1(password), salt, jceksIterations)
+	block, err := des.NewTripleDESCipher(derived[:24])
+	if err != nil {
+		return nil, err
+	}
+	encrypted := pkcs.EncryptCBC(block, derived[24:32], key)
+
+	params, err := asn1.Marshal(jcePBEParams{Salt: salt, Iterations: jceksIterations})
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(pkcs.EncryptedPrivateKeyInfo{
+		Algorithm:     pkix.AlgorithmIdentifier{Algorithm: oidPBEWithMD5AndTripleDES, Parameters: asn1.RawValue{FullBytes: params}},
+		EncryptedData: encrypted,
+	})
+}
+
+// deriveMD5TripleDES Sun PBEWithMD5AndTripleDES 密钥派生，返回24字节密钥+8字节IV
+func deriveMD5TripleDES(newHash func() hash.Hash, password, salt []byte, iterations int) []byte {
+	salt = append([]byte(nil), salt...)
+	half := len(salt) / 2
+	if bytes.Equal(salt[:half], salt[half:]) {
+		// 两半相同时反转前半部分
+		for i := 0; i < half/2; i++ {
+			salt[i], salt[half-1-i] = salt[half-1-i], salt[i]
+		}
+	}
+
+	result := make([]byte, 0, 32)
+	for i := 0; i < 2; i++ {
+		digest := salt[i*half : (i+1)*half]
+		for j := 0; j < iterations; j++ {
+			h := newHash()
+			h.Write(digest)
+			h.Write(password)
+			digest = h.Sum(nil)
+		}
+		result = append(result, digest...)
+	}
+	return result
+}
+
+// utf16BE 口令按Java char编码为UTF-16BE
+func utf16BE(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	out := make([]byte, 0, 2*len(units))
+	for _, u := range units {
+		out = append(out, byte(u>>8), byte(u))
+	}
+	return out
+}
+
+// latin1 口令按Java char截取低8位，与 PBEKeySpec 行为一致
+func latin1(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	out := make([]byte, 0, len(units))
+	for _, u := range units {
+		out = append(out, byte(u))
+	}
+	return out
+}
+
+// writeUTF Java DataOutput.writeUTF（modified UTF-8）
+func writeUTF(buf *bytes.Buffer, s string) error {
+	var encoded []byte
+	for _, u := range utf16.Encode([]rune(s)) {
+		switch {
+		case u != 0 && u < 0x80:
+			encoded = append(encoded, byte(u))
+		case u < 0x800:
+			encoded = append(encoded, byte(0xc0|u>>6), byte(0x80|u&0x3f))
+		default:
+			encoded = append(encoded, byte(0xe0|u>>12), byte(0x80|(u>>6)&0x3f), byte(0x80|u&0x3f))
+		}
+	}
+	if len(encoded) > 0xffff {
+		return errors.New("string too long")
+	}
+	_ = binary.Write(buf, binary.BigEndian, uint16(len(encoded)))
+	buf.Write(encoded)
+	return nil
+}
+
+func writeUint32(buf *bytes.Buffer, v uint32) {
+	_ = binary.Write(buf, binary.BigEndian, v)
+}
+
+func writeUint64(buf *bytes.Buffer, v uint64) {
+	_ = binary.Write(buf, binary.BigEndian, v)
+}
@@ -0,0 +1,113 @@
+package convert
+
+import (
+	"crypto/aes"
+	"crypto/des"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/pem"
+	"fmt"
+
+	certm "github.com/trustasia-com/certm-plugin-sdk"
+	"github.com/trustasia-com/certm-plugin-sdk/internal/pkcs"
+)
+
+// PKCS12Encryption PKCS#12加密方案
+type PKCS12Encryption string
+
+// String 实现 Stringer 接口
+func (p PKCS12Encryption) String() string {
+	return string(p)
+}
+
+const (
+	// PKCS12Modern AES-256-CBC + PBKDF2-HMAC-SHA256，MAC使用SHA256，与 OpenSSL 3 默认一致
+	PKCS12Modern PKCS12Encryption = "modern"
+	// PKCS12Legacy pbeWithSHAAnd3-KeyTripleDES-CBC，MAC使用SHA1，兼容 Windows Server 2016 及更早版本
+	PKCS12Legacy PKCS12Encryption = "legacy"
+)
+
+const defaultIterations = 2048
+
+// PKCS12Options PKCS#12转换选项
+type PKCS12Options struct {
+	Password     string           // 口令
+	FriendlyName string           // 友好名称（别名），为空时使用证书通用名称
+	Encryption   PKCS12Encryption // 加密方案，默认 PKCS12Modern
+	Iterations   int              // 迭代次数，默认 2048
+}
+
+// PKCS12 转换为PKCS#12（PFX），适用于IIS、Azure等
+func PKCS12(data *certm.CertOutputData, opts *PKCS12Options) ([]byte, error) {
+	if opts == nil {
+		opts = &PKCS12Options{}
+	}
+	key, err := pkcs8Key(data.KeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	certs, err := chainDER(data)
+	if err != nil {
+		return nil, err
+	}
+
+	iterations := opts.Iterations
+	if iterations <= 0 {
+		iterations = defaultIterations
+	}
+	friendlyName := opts.FriendlyName
+	if friendlyName == "" {
+		friendlyName = data.CommonName
+	}
+
+	pfxOpts := &pkcs.PFXOptions{
+		Password:      opts.Password,
+		MacIterations: iterations,
+	}
+	switch opts.Encryption {
+	case PKCS12Legacy:
+		enc := &pkcs.PKCS12PBE{
+			Hash:       sha1.New,
+			OID:        pkcs.OIDPBEWithSHAAnd3KeyTripleDESCBC,
+			Cipher:     des.NewTripleDESCipher,
+			KeyLen:     24,
+			Iterations: iterations,
+			SaltLen:    8,
+		}
+		pfxOpts.KeyEncryptor, pfxOpts.CertEncryptor = enc, enc
+		pfxOpts.MacHash, pfxOpts.MacOID = sha1.New, pkcs.OIDSHA1
+	case PKCS12Modern, "":
+		enc := modernEncryptor(iterations)
+		pfxOpts.KeyEncryptor, pfxOpts.CertEncryptor = enc, enc
+		pfxOpts.MacHash, pfxOpts.MacOID = sha256.New, pkcs.OIDSHA256
+	default:
+		return nil, fmt.Errorf("unsupported pkcs12 encryption: %s", opts.Encryption)
+	}
+	return pkcs.EncodePFX(key, certs, friendlyName, pfxOpts)
+}
+
+// EncryptedKeyPEM 将私钥加密为PKCS#8 PEM（ENCRYPTED PRIVATE KEY），使用 AES-256-CBC + PBKDF2-HMAC-SHA256
+func EncryptedKeyPEM(data *certm.CertOutputData, password string) (string, error) {
+	key, err := pkcs8Key(data.KeyPEM)
+	if err != nil {
+		return "", err
+	}
+	der, err := pkcs.EncryptPKCS8(key, password, modernEncryptor(defaultIterations))
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: der})), nil
+}
+
+// modernEncryptor AES-256-CBC + PBKDF2-HMAC-SHA256
+func modernEncryptor(iterations int) pkcs.Encryptor {
+	return &pkcs.PBES2{
+		PRF:        sha256.New,
+		PRFOID:     pkcs.OIDHMACWithSHA256,
+		Cipher:     aes.NewCipher,
+		CipherOID:  pkcs.OIDAES256CBC,
+		KeyLen:     32,
+		Iterations: iterations,
+		SaltLen:    16,
+	}
+}
@@ -0,0 +1,71 @@
+package certm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/trustasia-com/certm-plugin-sdk/helper"
+)
+
+// DecodeFields 按字段Schema校验JSON配置并解码为 T
+//
+// Schema 中的 secret 字段必须为引用，不接受明文；schema 为空时仅解码。
+func DecodeFields[T any](raw json.RawMessage, schema []helper.Field) (*T, error) {
+	if len(schema) > 0 {
+		var config helper.FieldConfig
+		if err := json.Unmarshal(raw, &config); err != nil {
+			return nil, err
+		}
+		if err := config.Validate(schema); err != nil {
+			return nil, err
+		}
+		for _, field := range schema {
+			if field.Type != helper.FieldTypeSecret {
+				continue
+			}
+			if _, ok := config[field.Key].(string); ok {
+				return nil, fmt.Errorf("%s: %w", field.Key, ErrSecretNotRef)
+			}
+		}
+	}
+
+	var v T
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// GetDeployerCredentials 获取部署器凭证，按部署器类型声明的凭证Schema校验后解码为 T
+//
+// T 中 secret 字段应使用 Secret 类型，通过 Reveal 获取明文：
+//
+//	type aliyunCredentials struct {
+//		AccessKeyID     string       `json:"access_key_id"`
+//		AccessKeySecret certm.Secret `json:"access_key_secret"`
+//	}
+func GetDeployerCredentials[T any](ctx context.Context, deployerID int) (*T, error) {
+	deployer, err := Data(ctx).GetDeployerDetail(deployerID)
+	if err != nil {
+		return nil, err
+	}
+	v, err := DecodeFields[T](deployer.Credentials, deployer.CredentialSchema)
+	if err != nil {
+		return nil, fmt.Errorf("deployer %d credentials: %w", deployerID, err)
+	}
+	return v, nil
+}
+
+// GetDeployerConfig 获取部署器配置，按部署器类型声明的配置Schema校验后解码为 T
+func GetDeployerConfig[T any](ctx context.Context, deployerID int) (*T, error) {
+	deployer, err := Data(ctx).GetDeployerDetail(deployerID)
+	if err != nil {
+		return nil, err
+	}
+	v, err := DecodeFields[T](deployer.Config, deployer.ConfigSchema)
+	if err != nil {
+		return nil, fmt.Errorf("deployer %d config: %w", deployerID, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,100 @@
+package certm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/trustasia-com/certm-plugin-sdk/helper"
+)
+
+type fakeDeployerAccess struct {
+	DataAccess
+	deployer *DeployerDetail
+}
+
+func (f *fakeDeployerAccess) GetDeployerDetail(projectID, deployerID int) (*DeployerDetail, error) {
+	return f.deployer, nil
+}
+
+type fakeSecretResolver map[string]string
+
+func (f fakeSecretResolver) ResolveSecret(ref string) (string, error) {
+	v, ok := f[ref]
+	if !ok {
+		return "", fmt.Errorf("secret %s not found", ref)
+	}
+	return v, nil
+}
+
+type testCredentials struct {
+	AccessKeyID     string `json:"access_key_id"`
+	AccessKeySecret Secret `json:"access_key_secret"`
+}
+
+func TestSecret(t *testing.T) {
+	var creds testCredentials
+	if err := json.Unmarshal([]byte(`{"access_key_id":"id","access_key_secret":{"secret_ref":"sec_1"}}`), &creds); err != nil {
+		t.Fatal(err)
+	}
+	if !creds.AccessKeySecret.IsRef() || creds.AccessKeySecret.Ref() != "sec_1" {
+		t.Fatalf("secret = %#v", creds.AccessKeySecret)
+	}
+	value, err := creds.AccessKeySecret.Reveal(fakeSecretResolver{"sec_1": "s3cr3t"})
+	if err != nil || value != "s3cr3t" {
+		t.Errorf("Reveal = %q, %v", value, err)
+	}
+	if _, err := creds.AccessKeySecret.Reveal(nil); !errors.Is(err, ErrNoSecretResolver) {
+		t.Errorf("Reveal(nil) err = %v", err)
+	}
+	data, _ := json.Marshal(creds)
+	if string(data) != `{"access_key_id":"id","access_key_secret":{"secret_ref":"sec_1"}}` {
+		t.Errorf("json = %s", data)
+	}
+
+	// 明文不出现在日志及序列化结果中
+	var plain Secret
+	_ = json.Unmarshal([]byte(`"s3cr3t"`), &plain)
+	data, _ = json.Marshal(plain)
+	for _, out := range []string{fmt.Sprintf("%v %+v %#v", plain, plain, plain), string(data)} {
+		if strings.Contains(out, "s3cr3t") {
+			t.Errorf("plain secret leaked: %s", out)
+		}
+	}
+	if value, _ := plain.Reveal(nil); value != "s3cr3t" {
+		t.Errorf("Reveal = %q", value)
+	}
+}
+
+func TestGetDeployerCredentials(t *testing.T) {
+	schema := []helper.Field{
+		{Type: helper.FieldTypeString, Key: "access_key_id", Required: true},
+		{Type: helper.FieldTypeSecret, Key: "access_key_secret", Required: true},
+	}
+	access := &fakeDeployerAccess{deployer: &DeployerDetail{
+		Credentials:      json.RawMessage(`{"access_key_id":"id","access_key_secret":{"secret_ref":"sec_1"}}`),
+		CredentialSchema: schema,
+	}}
+	ctx := SetContextKey(context.Background(), access, "zh-CN", 1)
+
+	creds, err := GetDeployerCredentials[testCredentials](ctx, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.AccessKeyID != "id" || creds.AccessKeySecret.Ref() != "sec_1" {
+		t.Errorf("creds = %+v", creds)
+	}
+
+	access.deployer.Credentials = json.RawMessage(`{"access_key_id":"id","access_key_secret":"s3cr3t"}`)
+	if _, err := GetDeployerCredentials[testCredentials](ctx, 7); !errors.Is(err, ErrSecretNotRef) {
+		t.Errorf("err = %v", err)
+	}
+
+	access.deployer.Credentials = json.RawMessage(`{"access_key_secret":{"secret_ref":"sec_1"}}`)
+	if _, err := GetDeployerCredentials[testCredentials](ctx, 7); !helper.IsRequiredFieldError(errors.Unwrap(err)) {
+		t.Errorf("err = %v", err)
+	}
+}
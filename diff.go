@@ -0,0 +1,102 @@
+package certm
+
+import "github.com/trustasia-com/certm-plugin-sdk/certutil"
+
+// ChangeKind 检测结果变化类型
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"    // 新增端点
+	ChangeRemoved  ChangeKind = "removed"  // 端点不再检测
+	ChangeModified ChangeKind = "modified" // 证书、信任状态、错误或IP发生变化
+)
+
+// CheckResultChange 单个端点的检测结果变化
+type CheckResultChange struct {
+	Kind          ChangeKind           `json:"kind"`
+	Endpoint      string               `json:"endpoint"`
+	Protocol      string               `json:"protocol,omitempty"`
+	IP            string               `json:"ip"`
+	CertChanged   bool                 `json:"cert_changed"`   // 证书SHA1变化
+	StatusChanged bool                 `json:"status_changed"` // 信任状态变化
+	ErrorChanged  bool                 `json:"error_changed"`  // 错误信息变化
+	IPChanged     bool                 `json:"ip_changed"`     // 解析IP变化
+	Previous      *CheckEndpointResult `json:"previous,omitempty"`
+	Current       *CheckEndpointResult `json:"current,omitempty"`
+}
+
+// endpointKey 端点唯一标识
+type endpointKey struct {
+	endpoint, protocol string
+}
+
+// key 返回端点唯一标识，协议为空视为tls
+func (r *CheckEndpointResult) key() endpointKey {
+	protocol := r.Protocol
+	if protocol == "" {
+		protocol = "tls"
+	}
+	return endpointKey{r.Endpoint, protocol}
+}
+
+// DiffCheckResults 对比上次与本次的检测结果，仅返回有变化的端点
+//
+// 按端点和协议对应，同一端点优先匹配相同IP；DNS轮询或解析变化导致的IP不同视为变化而非增减。
+// 结果先按本次结果顺序列出新增和变化的端点，再列出已移除的端点。
+func DiffCheckResults(previous, current []*CheckEndpointResult) []*CheckResultChange {
+	prev := make(map[endpointKey][]*CheckEndpointResult, len(previous))
+	for _, r := range previous {
+		prev[r.key()] = append(prev[r.key()], r)
+	}
+
+	// 先按相同IP配对，剩余的按顺序配对
+	matched := make(map[*CheckEndpointResult]*CheckEndpointResult, len(current))
+	used := make(map[*CheckEndpointResult]bool, len(previous))
+	for _, cur := range current {
+		for _, old := range prev[cur.key()] {
+			if !used[old] && old.IP == cur.IP {
+				matched[cur], used[old] = old, true
+				break
+			}
+		}
+	}
+	for _, cur := range current {
+		if matched[cur] != nil {
+			continue
+		}
+		for _, old := range prev[cur.key()] {
+			if !used[old] {
+				matched[cur], used[old] = old, true
+				break
+			}
+		}
+	}
+
+	var changes []*CheckResultChange
+	for _, cur := range current {
+		change := &CheckResultChange{Endpoint: cur.Endpoint, Protocol: cur.Protocol, IP: cur.IP, Current: cur}
+		old := matched[cur]
+		if old == nil {
+			change.Kind = ChangeAdded
+			changes = append(changes, change)
+			continue
+		}
+		change.Previous = old
+		change.CertChanged = certutil.NormalizeFingerprint(old.SHA1) != certutil.NormalizeFingerprint(cur.SHA1)
+		change.StatusChanged = old.TrustStatus != cur.TrustStatus
+		change.ErrorChanged = old.Error != cur.Error
+		change.IPChanged = old.IP != cur.IP
+		if change.CertChanged || change.StatusChanged || change.ErrorChanged || change.IPChanged {
+			change.Kind = ChangeModified
+			changes = append(changes, change)
+		}
+	}
+	for _, old := range previous {
+		if !used[old] {
+			changes = append(changes, &CheckResultChange{
+				Kind: ChangeRemoved, Endpoint: old.Endpoint, Protocol: old.Protocol, IP: old.IP, Previous: old,
+			})
+		}
+	}
+	return changes
+}
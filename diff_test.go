@@ -0,0 +1,65 @@
+package certm
+
+import "testing"
+
+func TestDiffCheckResults(t *testing.T) {
+	previous := []*CheckEndpointResult{
+		{Endpoint: "a.example.com:443", IP: "192.0.2.1", SHA1: "aa"},
+		{Endpoint: "b.example.com:443", IP: "192.0.2.2", SHA1: "bb"},
+		{Endpoint: "c.example.com:443", IP: "192.0.2.3", SHA1: "cc"},
+		{Endpoint: "mail.example.com:25", Protocol: "smtp", IP: "192.0.2.4", SHA1: "dd"},
+	}
+	current := []*CheckEndpointResult{
+		{Endpoint: "a.example.com:443", Protocol: "tls", IP: "192.0.2.1", SHA1: "aa"},
+		{Endpoint: "b.example.com:443", IP: "192.0.2.2", SHA1: "b2", TrustStatus: TrustStatusCertExpired},
+		{Endpoint: "d.example.com:443", IP: "192.0.2.5", SHA1: "ee"},
+		{Endpoint: "mail.example.com:25", Protocol: "smtp", IP: "192.0.2.4", SHA1: "dd", Error: "timeout"},
+	}
+
+	changes := DiffCheckResults(previous, current)
+	want := []struct {
+		endpoint string
+		kind     ChangeKind
+	}{
+		{"b.example.com:443", ChangeModified},
+		{"d.example.com:443", ChangeAdded},
+		{"mail.example.com:25", ChangeModified},
+		{"c.example.com:443", ChangeRemoved},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("got %d changes, want %d", len(changes), len(want))
+	}
+	for i, w := range want {
+		if changes[i].Endpoint != w.endpoint || changes[i].Kind != w.kind {
+			t.Errorf("changes[%d] = %s %s, want %s %s", i, changes[i].Endpoint, changes[i].Kind, w.endpoint, w.kind)
+		}
+	}
+	if b := changes[0]; !b.CertChanged || !b.StatusChanged || b.ErrorChanged {
+		t.Errorf("b = %+v", b)
+	}
+	if m := changes[2]; m.CertChanged || !m.ErrorChanged {
+		t.Errorf("mail = %+v", m)
+	}
+
+	// IP变化视为修改，指纹按规范化后比较
+	changes = DiffCheckResults(
+		[]*CheckEndpointResult{{Endpoint: "a.example.com:443", IP: "192.0.2.1", SHA1: "AA:BB"}},
+		[]*CheckEndpointResult{{Endpoint: "a.example.com:443", IP: "192.0.2.9", SHA1: "aabb"}},
+	)
+	if len(changes) != 1 || changes[0].Kind != ChangeModified || !changes[0].IPChanged || changes[0].CertChanged {
+		t.Errorf("ip change = %+v", changes)
+	}
+
+	// DNS轮询：同一端点多个IP，仅顺序变化时无变化
+	rr := []*CheckEndpointResult{
+		{Endpoint: "rr.example.com:443", IP: "192.0.2.1", SHA1: "aa"},
+		{Endpoint: "rr.example.com:443", IP: "192.0.2.2", SHA1: "aa"},
+	}
+	if changes := DiffCheckResults(rr, []*CheckEndpointResult{rr[1], rr[0]}); len(changes) != 0 {
+		t.Errorf("round robin: %d changes", len(changes))
+	}
+
+	if changes := DiffCheckResults(current, current); len(changes) != 0 {
+		t.Errorf("identical results: %d changes", len(changes))
+	}
+}
@@ -0,0 +1,386 @@
+// Package discovery 提供从CIDR、IP及域名列表发现TLS端点的工具
+package discovery
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	certm "github.com/trustasia-com/certm-plugin-sdk"
+	"github.com/trustasia-com/certm-plugin-sdk/certutil"
+)
+
+const (
+	defaultMaxTargets  = 4096
+	defaultConcurrency = 10
+	defaultPort        = 443
+)
+
+var (
+	ErrNoProber  = errors.New("discovery: tls prober is required") // 缺少TLS探测能力
+	ErrNoTargets = errors.New("discovery: no targets to scan")     // 没有扫描目标
+)
+
+// TooManyTargetsError 展开后的目标数量超过限制
+type TooManyTargetsError struct {
+	Limit int
+}
+
+func (e *TooManyTargetsError) Error() string {
+	return fmt.Sprintf("discovery: targets exceed the limit of %d", e.Limit)
+}
+
+// TargetError 单个目标探测失败
+type TargetError struct {
+	Target
+	Err error
+}
+
+func (e *TargetError) Error() string {
+	return fmt.Sprintf("discovery: probe %s: %v", e.Address(), e.Err)
+}
+
+func (e *TargetError) Unwrap() error {
+	return e.Err
+}
+
+// Target 扫描目标
+type Target struct {
+	Host string `json:"host"` // 原始主机：域名或IP
+	IP   string `json:"ip"`   // 连接IP，域名未解析时为空
+	Port int    `json:"port"` // 端口
+}
+
+// Address 返回连接地址，优先使用IP
+func (t *Target) Address() string {
+	host := t.IP
+	if host == "" {
+		host = t.Host
+	}
+	return net.JoinHostPort(host, strconv.Itoa(t.Port))
+}
+
+// ServerName 返回SNI主机名，IP目标为空
+func (t *Target) ServerName() string {
+	if _, err := netip.ParseAddr(t.Host); err == nil {
+		return ""
+	}
+	return t.Host
+}
+
+// Expand 将CIDR、IP、域名条目与端口展开为扫描目标，域名不做解析
+//
+// 条目可带端口（example.com:8443、[::1]:443），此时忽略 ports；
+// IPv4 CIDR 跳过网络地址与广播地址。目标数量超过 maxTargets 时返回 TooManyTargetsError。
+func Expand(entries []string, ports []int, maxTargets int) ([]Target, error) {
+	if len(ports) == 0 {
+		ports = []int{defaultPort}
+	}
+	if maxTargets <= 0 {
+		maxTargets = defaultMaxTargets
+	}
+	for _, port := range ports {
+		if port < 1 || port > 65535 {
+			return nil, fmt.Errorf("discovery: invalid port %d", port)
+		}
+	}
+
+	var targets []Target
+	add := func(host, ip string, entryPorts []int) error {
+		for _, port := range entryPorts {
+			if len(targets) >= maxTargets {
+				return &TooManyTargetsError{Limit: maxTargets}
+			}
+			targets = append(targets, Target{Host: host, IP: ip, Port: port})
+		}
+		return nil
+	}
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("discovery: invalid CIDR %q: %w", entry, err)
+			}
+			prefix = prefix.Masked()
+			addr, last := prefix.Addr(), lastAddr(prefix)
+			skipEdges := addr.Is4() && prefix.Bits() < 31
+			for ; prefix.Contains(addr); addr = addr.Next() {
+				if skipEdges && (addr == prefix.Addr() || addr == last) {
+					continue
+				}
+				if err := add(addr.String(), addr.String(), ports); err != nil {
+					return nil, err
+				}
+				if addr == last {
+					break
+				}
+			}
+			continue
+		}
+
+		host, entryPorts := entry, ports
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			port, err := strconv.Atoi(p)
+			if err != nil || port < 1 || port > 65535 {
+				return nil, fmt.Errorf("discovery: invalid port in %q", entry)
+			}
+			host, entryPorts = h, []int{port}
+		}
+		host = strings.TrimSuffix(strings.Trim(host, "[]"), ".")
+		ip := ""
+		if addr, err := netip.ParseAddr(host); err == nil {
+			ip = addr.String()
+		}
+		if err := add(host, ip, entryPorts); err != nil {
+			return nil, err
+		}
+	}
+	return targets, nil
+}
+
+// lastAddr 返回网段最后一个地址
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	b := prefix.Addr().AsSlice()
+	for i := prefix.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 1 << (7 - i%8)
+	}
+	addr, _ := netip.AddrFromSlice(b)
+	return addr
+}
+
+// Options 发现选项
+type Options struct {
+	// Targets CIDR、IP、域名条目，可带端口
+	Targets []string
+	// Ports 扫描端口，默认 443
+	Ports []int
+	// Protocol 探测协议，为空表示直接TLS
+	Protocol string
+	// MaxTargets 展开后的最大目标数量，默认 4096
+	MaxTargets int
+	// Concurrency 并发探测数量，默认 10
+	Concurrency int
+	// RatePerSecond 每秒最多发起的探测数量，0 表示不限制，超过 1e9 时按 1e9 处理
+	RatePerSecond int
+	// Timeout 单次探测超时时间(秒)，0使用主机默认值
+	Timeout int
+	// Prober TLS探测能力，通常为 certm.GetTLSProber(ctx)
+	Prober certm.TLSProber
+	// Resolver 域名解析能力，为空时由探测方解析域名
+	Resolver certm.DNSResolver
+	// Known 已知证书资产，用于匹配发现的证书
+	Known []*certm.CertAssetInfo
+}
+
+// Endpoint 发现的端点
+type Endpoint struct {
+	Target
+	Protocol   string    `json:"protocol,omitempty"` // 探测协议
+	SHA1       string    `json:"sha1"`               // 叶子证书SHA1
+	CommonName string    `json:"common_name"`        // 叶子证书通用名称
+	NotAfter   time.Time `json:"not_after"`          // 叶子证书过期时间
+
+	Known *certm.CertAssetInfo    `json:"known,omitempty"` // 匹配的已知证书资产
+	Probe *certm.TLSProbeResponse `json:"-"`               // 探测结果
+	Chain []*x509.Certificate     `json:"-"`               // 对端证书链
+}
+
+// CheckResult 转换为检测端点结果，CertMatch 表示证书与已知资产匹配
+func (e *Endpoint) CheckResult() *certm.CheckEndpointResult {
+	result := &certm.CheckEndpointResult{
+		Endpoint:   net.JoinHostPort(e.Host, strconv.Itoa(e.Port)),
+		Protocol:   e.Protocol,
+		IP:         e.IP,
+		CertMatch:  e.Known != nil,
+		SHA1:       e.SHA1,
+		CommonName: e.CommonName,
+		NotAfter:   e.NotAfter,
+		CheckedAt:  time.Now(),
+	}
+	if e.Probe != nil {
+		result.ResponseTime = e.Probe.ResponseTime
+	}
+	return result
+}
+
+// Result 发现结果
+type Result struct {
+	Endpoints []*Endpoint    // 成功握手的端点，按目标顺序排列
+	Scanned   int            // 探测的目标数量
+	Failed    int            // 连接或握手失败的目标数量
+	Failures  []*TargetError // 失败目标及原因，按目标顺序排列
+}
+
+// Unknown 返回证书不在已知资产中的端点
+func (r *Result) Unknown() []*Endpoint {
+	var unknown []*Endpoint
+	for _, ep := range r.Endpoints {
+		if ep.Known == nil {
+			unknown = append(unknown, ep)
+		}
+	}
+	return unknown
+}
+
+// Discover 展开目标并并发探测，返回发现的TLS端点
+func Discover(opts *Options) (*Result, error) {
+	if opts.Prober == nil {
+		return nil, ErrNoProber
+	}
+	targets, err := Expand(opts.Targets, opts.Ports, opts.MaxTargets)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Resolver != nil {
+		if targets, err = resolve(targets, opts.Resolver, opts.MaxTargets); err != nil {
+			return nil, err
+		}
+	}
+	if len(targets) == 0 {
+		return nil, ErrNoTargets
+	}
+
+	known := make(map[string]*certm.CertAssetInfo, len(opts.Known))
+	for _, asset := range opts.Known {
+		known[certutil.NormalizeFingerprint(asset.SHA1)] = asset
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	var ticker *time.Ticker
+	if opts.RatePerSecond > 0 {
+		// 速率超过每秒 1e9 时间隔为 0，NewTicker 会 panic
+		ticker = time.NewTicker(max(time.Second/time.Duration(opts.RatePerSecond), time.Nanosecond))
+		defer ticker.Stop()
+	}
+
+	endpoints := make([]*Endpoint, len(targets))
+	errs := make([]error, len(targets))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(concurrency, len(targets)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				endpoints[i], errs[i] = probeTarget(targets[i], opts, known)
+			}
+		}()
+	}
+	for i := range targets {
+		if ticker != nil {
+			<-ticker.C
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	result := &Result{Scanned: len(targets)}
+	for i, ep := range endpoints {
+		if errs[i] != nil {
+			result.Failed++
+			result.Failures = append(result.Failures, &TargetError{Target: targets[i], Err: errs[i]})
+			continue
+		}
+		result.Endpoints = append(result.Endpoints, ep)
+	}
+	return result, nil
+}
+
+// resolve 将域名目标解析为每个IP一个目标，解析失败的域名保留由探测方解析
+func resolve(targets []Target, resolver certm.DNSResolver, maxTargets int) ([]Target, error) {
+	if maxTargets <= 0 {
+		maxTargets = defaultMaxTargets
+	}
+	cache := make(map[string][]string)
+	var resolved []Target
+	for _, t := range targets {
+		if t.IP != "" {
+			resolved = append(resolved, t)
+			continue
+		}
+		ips, ok := cache[t.Host]
+		if !ok {
+			ips = lookupIPs(resolver, t.Host)
+			cache[t.Host] = ips
+		}
+		if len(ips) == 0 {
+			resolved = append(resolved, t)
+			continue
+		}
+		for _, ip := range ips {
+			resolved = append(resolved, Target{Host: t.Host, IP: ip, Port: t.Port})
+		}
+	}
+	if len(resolved) > maxTargets {
+		return nil, &TooManyTargetsError{Limit: maxTargets}
+	}
+	return resolved, nil
+}
+
+// lookupIPs 查询域名的A与AAAA记录
+func lookupIPs(resolver certm.DNSResolver, host string) []string {
+	var ips []string
+	for _, typ := range []certm.DNSRecordType{certm.DNSRecordA, certm.DNSRecordAAAA} {
+		resp, err := resolver.DNSLookup(&certm.DNSRequest{Name: host, Type: typ})
+		if err != nil {
+			continue
+		}
+		for _, record := range resp.Records {
+			if record.Type != typ {
+				continue
+			}
+			if addr, err := netip.ParseAddr(record.Value); err == nil {
+				ips = append(ips, addr.String())
+			}
+		}
+	}
+	return ips
+}
+
+// probeTarget 探测单个目标并匹配已知资产
+func probeTarget(t Target, opts *Options, known map[string]*certm.CertAssetInfo) (*Endpoint, error) {
+	ep := &Endpoint{Target: t, Protocol: opts.Protocol}
+	resp, err := opts.Prober.TLSProbe(&certm.TLSProbeRequest{
+		Address:    t.Address(),
+		ServerName: t.ServerName(),
+		Protocol:   opts.Protocol,
+		Timeout:    opts.Timeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ep.Probe = resp
+	if resp.RemoteAddr != "" && ep.IP == "" {
+		if host, _, err := net.SplitHostPort(resp.RemoteAddr); err == nil {
+			ep.IP = host
+		}
+	}
+
+	chain, err := certutil.ParseCertificatesPEM(resp.ChainPEM...)
+	if err != nil {
+		return nil, err
+	}
+	leaf := chain[0]
+	ep.Chain = chain
+	ep.CommonName = leaf.Subject.CommonName
+	ep.NotAfter = leaf.NotAfter
+	ep.SHA1, _ = certutil.Fingerprint(leaf, certutil.FingerprintSHA1)
+	ep.Known = known[ep.SHA1]
+	return ep, nil
+}
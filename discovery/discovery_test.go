@@ -0,0 +1,154 @@
+package discovery
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	certm "github.com/trustasia-com/certm-plugin-sdk"
+	"github.com/trustasia-com/certm-plugin-sdk/certutil"
+)
+
+func newCertPEM(t *testing.T, cn string) (string, string) {
+	t.Helper()
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	sha1, _ := certutil.Fingerprint(cert, certutil.FingerprintSHA1)
+	return certutil.EncodeCertificatePEM(cert), sha1
+}
+
+type fakeProber struct {
+	mu       sync.Mutex
+	chains   map[string]string
+	requests []*certm.TLSProbeRequest
+}
+
+func (f *fakeProber) TLSProbe(req *certm.TLSProbeRequest) (*certm.TLSProbeResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, req)
+	chain, ok := f.chains[req.Address]
+	if !ok {
+		return nil, errors.New("connection refused")
+	}
+	return &certm.TLSProbeResponse{RemoteAddr: req.Address, ChainPEM: []string{chain}, ResponseTime: 5}, nil
+}
+
+type fakeResolver map[string][]string
+
+func (f fakeResolver) DNSLookup(req *certm.DNSRequest) (*certm.DNSResponse, error) {
+	resp := &certm.DNSResponse{}
+	if req.Type != certm.DNSRecordA {
+		return resp, nil
+	}
+	for _, ip := range f[req.Name] {
+		resp.Records = append(resp.Records, certm.DNSRecord{Name: req.Name, Type: certm.DNSRecordA, Value: ip})
+	}
+	return resp, nil
+}
+
+func TestExpand(t *testing.T) {
+	targets, err := Expand([]string{"10.0.0.0/30", "example.com", "# comment", "[::1]:8443", "192.168.1.1"}, []int{443, 8443}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var addrs []string
+	for _, target := range targets {
+		addrs = append(addrs, target.Address())
+	}
+	expected := []string{
+		"10.0.0.1:443", "10.0.0.1:8443", "10.0.0.2:443", "10.0.0.2:8443",
+		"example.com:443", "example.com:8443",
+		"[::1]:8443",
+		"192.168.1.1:443", "192.168.1.1:8443",
+	}
+	if len(addrs) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, addrs)
+	}
+	for i := range expected {
+		if addrs[i] != expected[i] {
+			t.Errorf("Target %d: expected %s, got %s", i, expected[i], addrs[i])
+		}
+	}
+	if targets[4].ServerName() != "example.com" || targets[0].ServerName() != "" {
+		t.Error("Unexpected server names")
+	}
+
+	var tooMany *TooManyTargetsError
+	if _, err := Expand([]string{"10.0.0.0/16"}, nil, 100); !errors.As(err, &tooMany) {
+		t.Errorf("Expected TooManyTargetsError, got %v", err)
+	}
+	if _, err := Expand([]string{"10.0.0.0/33"}, nil, 0); err == nil {
+		t.Error("Expected invalid CIDR error")
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	knownPEM, knownSHA1 := newCertPEM(t, "known.example.com")
+	unknownPEM, _ := newCertPEM(t, "rogue.example.com")
+	prober := &fakeProber{chains: map[string]string{
+		"10.0.0.1:443":    knownPEM,
+		"203.0.113.5:443": unknownPEM,
+	}}
+
+	result, err := Discover(&Options{
+		Targets:       []string{"10.0.0.0/29", "www.example.com"},
+		Prober:        prober,
+		Resolver:      fakeResolver{"www.example.com": {"203.0.113.5"}},
+		Known:         []*certm.CertAssetInfo{{ID: 7, SHA1: knownSHA1}},
+		Concurrency:   4,
+		RatePerSecond: 1000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Scanned != 7 || result.Failed != 5 || len(result.Endpoints) != 2 {
+		t.Fatalf("Unexpected result: scanned %d, failed %d, found %d", result.Scanned, result.Failed, len(result.Endpoints))
+	}
+	if len(result.Failures) != 5 || result.Failures[0].Address() != "10.0.0.2:443" || result.Failures[0].Err == nil {
+		t.Errorf("Unexpected failures %+v", result.Failures)
+	}
+	if ep := result.Endpoints[0]; ep.Known == nil || ep.Known.ID != 7 || ep.CommonName != "known.example.com" {
+		t.Errorf("Expected known endpoint, got %+v", ep)
+	}
+	unknown := result.Unknown()
+	if len(unknown) != 1 || unknown[0].Host != "www.example.com" || unknown[0].IP != "203.0.113.5" {
+		t.Fatalf("Unexpected unknown endpoints %+v", unknown)
+	}
+
+	check := unknown[0].CheckResult()
+	if check.Endpoint != "www.example.com:443" || check.CertMatch || check.ResponseTime != 5 {
+		t.Errorf("Unexpected check result %+v", check)
+	}
+	for _, req := range prober.requests {
+		if req.Address == "203.0.113.5:443" && req.ServerName != "www.example.com" {
+			t.Errorf("Expected SNI www.example.com, got %q", req.ServerName)
+		}
+	}
+
+	// 超高速率不应导致 NewTicker panic
+	if _, err := Discover(&Options{Targets: []string{"10.0.0.1"}, Prober: prober, RatePerSecond: 2e9}); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	if _, err := Discover(&Options{Targets: []string{"example.com"}}); !errors.Is(err, ErrNoProber) {
+		t.Errorf("Expected ErrNoProber, got %v", err)
+	}
+}
@@ -0,0 +1,223 @@
+// Package dnscheck 提供基于DNS的证书检查：CAA颁发授权及TLSA/DANE证书关联
+package dnscheck
+
+import (
+	"crypto/x509"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	certm "github.com/trustasia-com/certm-plugin-sdk"
+	"github.com/trustasia-com/certm-plugin-sdk/hostmatch"
+)
+
+// CAA标签
+const (
+	CAATagIssue     = "issue"     // 允许颁发证书的CA
+	CAATagIssueWild = "issuewild" // 允许颁发通配符证书的CA
+	CAATagIODEF     = "iodef"     // 违规报告地址
+)
+
+// caaFlagCritical 关键标志位，CA无法识别该标签时不得颁发
+const caaFlagCritical = 128
+
+// knownCAATags 已知的CAA标签
+var knownCAATags = []string{CAATagIssue, CAATagIssueWild, CAATagIODEF, "issuemail", "issuevmc", "contactemail", "contactphone"}
+
+// caIdentifiers 常见CA组织名称对应的CAA标识
+var caIdentifiers = []struct {
+	organization string
+	identifiers  []string
+}{
+	{"Let's Encrypt", []string{"letsencrypt.org"}},
+	{"DigiCert", []string{"digicert.com"}},
+	{"Sectigo", []string{"sectigo.com", "comodoca.com"}},
+	{"ZeroSSL", []string{"sectigo.com"}},
+	{"GlobalSign", []string{"globalsign.com"}},
+	{"Google Trust Services", []string{"pki.goog"}},
+	{"Amazon", []string{"amazon.com", "amazontrust.com", "awstrust.com"}},
+	{"TrustAsia", []string{"trustasia.com"}},
+	{"GoDaddy", []string{"godaddy.com"}},
+	{"Entrust", []string{"entrust.net"}},
+}
+
+// CAARecord CAA记录
+type CAARecord struct {
+	Flags uint8  `json:"flags"` // 标志
+	Tag   string `json:"tag"`   // 标签
+	Value string `json:"value"` // 值，不含引号
+}
+
+// ParseCAA 解析文本格式的CAA记录，如 0 issue "letsencrypt.org"，字段间可为任意空白
+func ParseCAA(value string) (*CAARecord, error) {
+	fields := strings.Fields(value)
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("dnscheck: invalid CAA record %q", value)
+	}
+	flags, err := strconv.ParseUint(fields[0], 10, 8)
+	if err != nil {
+		return nil, fmt.Errorf("dnscheck: invalid CAA flags in %q", value)
+	}
+	record := &CAARecord{Flags: uint8(flags), Tag: strings.ToLower(fields[1])}
+	// 标签之后的部分整体作为值，保留其中的空白
+	rest := strings.TrimSpace(value)
+	for _, field := range fields[:2] {
+		rest = strings.TrimSpace(strings.TrimPrefix(rest, field))
+	}
+	record.Value = strings.Trim(rest, `"`)
+	return record, nil
+}
+
+// Critical 判断是否设置了关键标志
+func (r *CAARecord) Critical() bool {
+	return r.Flags&caaFlagCritical != 0
+}
+
+// IssuerDomain 返回 issue/issuewild 记录的CA标识，";" 表示禁止任何CA时返回空
+func (r *CAARecord) IssuerDomain() string {
+	domain, _, _ := strings.Cut(r.Value, ";")
+	return strings.ToLower(strings.TrimSpace(domain))
+}
+
+// String 返回文本格式
+func (r *CAARecord) String() string {
+	return fmt.Sprintf("%d %s %q", r.Flags, r.Tag, r.Value)
+}
+
+// CAAIdentifiers 根据颁发者组织名称推断CA的CAA标识，无法识别时返回空
+func CAAIdentifiers(cert *x509.Certificate) []string {
+	for _, org := range cert.Issuer.Organization {
+		for _, ca := range caIdentifiers {
+			if strings.Contains(strings.ToLower(org), strings.ToLower(ca.organization)) {
+				return ca.identifiers
+			}
+		}
+	}
+	return nil
+}
+
+// LookupCAA 自域名向上逐级查找CAA记录集，返回找到记录的名称；均无记录时返回空名称
+func LookupCAA(resolver certm.DNSResolver, domain string) (string, []CAARecord, error) {
+	name, err := hostmatch.ToASCII(strings.TrimPrefix(domain, "*."))
+	if err != nil {
+		return "", nil, fmt.Errorf("dnscheck: %w", err)
+	}
+	for {
+		resp, err := resolver.DNSLookup(&certm.DNSRequest{Name: name, Type: certm.DNSRecordCAA})
+		if err != nil {
+			return "", nil, fmt.Errorf("dnscheck: lookup CAA %s: %w", name, err)
+		}
+		var records []CAARecord
+		for _, rr := range resp.Records {
+			if rr.Type != certm.DNSRecordCAA {
+				continue
+			}
+			record, err := ParseCAA(rr.Value)
+			if err != nil {
+				return "", nil, err
+			}
+			records = append(records, *record)
+		}
+		if len(records) > 0 {
+			return name, records, nil
+		}
+
+		i := strings.IndexByte(name, '.')
+		if i < 0 {
+			return "", nil, nil
+		}
+		name = name[i+1:]
+	}
+}
+
+// CAAResult 单个域名的CAA检查结果
+type CAAResult struct {
+	Domain    string      `json:"domain"`           // 检查的域名
+	Name      string      `json:"name,omitempty"`   // 找到记录集的名称
+	Records   []CAARecord `json:"records"`          // 相关记录集
+	Permitted bool        `json:"permitted"`        // 是否允许颁发
+	Reason    string      `json:"reason,omitempty"` // 不允许的原因
+}
+
+// TrustReason 不允许颁发时返回对应的信任原因
+func (r *CAAResult) TrustReason() *certm.TrustReason {
+	if r.Permitted {
+		return nil
+	}
+	reason := certm.NewTrustReason(certm.TrustStatusCAANotPermitted, r.Reason)
+	return &reason
+}
+
+// EvaluateCAA 按 RFC 8659 判断记录集是否允许 caIdentifiers 中的CA为 domain 颁发证书
+func EvaluateCAA(domain string, records []CAARecord, caIdentifiers []string) *CAAResult {
+	result := &CAAResult{Domain: domain, Records: records, Permitted: true}
+	if len(records) == 0 {
+		return result
+	}
+	for _, r := range records {
+		if r.Critical() && !slices.Contains(knownCAATags, r.Tag) {
+			result.Permitted = false
+			result.Reason = fmt.Sprintf("CAA record for %s has unknown critical tag %q", domain, r.Tag)
+			return result
+		}
+	}
+
+	tag := CAATagIssue
+	if strings.HasPrefix(domain, "*.") && slices.ContainsFunc(records, func(r CAARecord) bool { return r.Tag == CAATagIssueWild }) {
+		tag = CAATagIssueWild
+	}
+	var allowed []string
+	for _, r := range records {
+		if r.Tag != tag {
+			continue
+		}
+		issuer := r.IssuerDomain()
+		allowed = append(allowed, issuer)
+		for _, id := range caIdentifiers {
+			if issuer != "" && strings.EqualFold(issuer, id) {
+				return result
+			}
+		}
+	}
+	if allowed == nil {
+		// 无 issue 记录（如仅有 iodef）不限制颁发
+		return result
+	}
+	result.Permitted = false
+	result.Reason = fmt.Sprintf("CAA %s records for %s do not permit %s", tag, domain, strings.Join(caIdentifiers, ", "))
+	return result
+}
+
+// CheckCAA 查询并检查单个域名的CAA记录
+func CheckCAA(resolver certm.DNSResolver, domain string, caIdentifiers []string) (*CAAResult, error) {
+	name, records, err := LookupCAA(resolver, domain)
+	if err != nil {
+		return nil, err
+	}
+	result := EvaluateCAA(domain, records, caIdentifiers)
+	result.Name = name
+	return result, nil
+}
+
+// CheckCertCAA 检查证书的全部DNS SAN，caIdentifiers 为空时根据颁发者推断
+func CheckCertCAA(resolver certm.DNSResolver, data *certm.CertOutputData, caIdentifiers []string) ([]*CAAResult, error) {
+	leaf, err := data.Leaf()
+	if err != nil {
+		return nil, fmt.Errorf("dnscheck: %w", err)
+	}
+	if len(caIdentifiers) == 0 {
+		if caIdentifiers = CAAIdentifiers(leaf); len(caIdentifiers) == 0 {
+			return nil, fmt.Errorf("dnscheck: unknown CAA identifier for issuer %q", leaf.Issuer.String())
+		}
+	}
+	results := make([]*CAAResult, 0, len(leaf.DNSNames))
+	for _, name := range leaf.DNSNames {
+		result, err := CheckCAA(resolver, name, caIdentifiers)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
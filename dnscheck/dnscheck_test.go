@@ -0,0 +1,205 @@
+package dnscheck
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	certm "github.com/trustasia-com/certm-plugin-sdk"
+	"github.com/trustasia-com/certm-plugin-sdk/certutil"
+)
+
+type fakeResolver struct {
+	records       map[string][]certm.DNSRecord
+	authenticated bool
+	queries       []string
+}
+
+func (f *fakeResolver) DNSLookup(req *certm.DNSRequest) (*certm.DNSResponse, error) {
+	f.queries = append(f.queries, req.Name)
+	var records []certm.DNSRecord
+	for _, r := range f.records[req.Name] {
+		if r.Type == req.Type {
+			records = append(records, r)
+		}
+	}
+	return &certm.DNSResponse{Records: records, Authenticated: f.authenticated}, nil
+}
+
+func caa(name, value string) certm.DNSRecord {
+	return certm.DNSRecord{Name: name, Type: certm.DNSRecordCAA, Value: value}
+}
+
+func newChain(t *testing.T) []*x509.Certificate {
+	t.Helper()
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "R3", Organization: []string{"Let's Encrypt"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	caDER, _ := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	ca, _ := x509.ParseCertificate(caDER)
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		DNSNames:     []string{"example.com", "*.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(12 * time.Hour),
+	}, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, _ := x509.ParseCertificate(der)
+	return []*x509.Certificate{leaf, ca}
+}
+
+func TestParseCAA(t *testing.T) {
+	r, err := ParseCAA(`128 issue "letsencrypt.org; validationmethods=dns-01"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Critical() || r.Tag != CAATagIssue || r.IssuerDomain() != "letsencrypt.org" {
+		t.Errorf("record = %+v", r)
+	}
+	if _, err := ParseCAA("issue"); err == nil {
+		t.Error("expected error")
+	}
+
+	// 解析器输出中的连续空格或制表符
+	for _, value := range []string{`0  issue "ca.example.net"`, "0\tissue\t\"ca.example.net\"", ` 0 issue  "ca.example.net" `} {
+		r, err := ParseCAA(value)
+		if err != nil {
+			t.Fatalf("%q: %v", value, err)
+		}
+		if r.Flags != 0 || r.Tag != CAATagIssue || r.Value != "ca.example.net" {
+			t.Errorf("%q: record = %+v", value, r)
+		}
+	}
+	r, err = ParseCAA(`0 iodef "mailto:a b@example.com"`)
+	if err != nil || r.Value != "mailto:a b@example.com" {
+		t.Errorf("record = %+v, err = %v", r, err)
+	}
+}
+
+func TestEvaluateCAA(t *testing.T) {
+	records := []CAARecord{
+		{Tag: CAATagIssue, Value: "letsencrypt.org"},
+		{Tag: CAATagIssueWild, Value: ";"},
+		{Tag: CAATagIODEF, Value: "mailto:security@example.com"},
+	}
+	tests := []struct {
+		domain string
+		ids    []string
+		want   bool
+	}{
+		{"example.com", []string{"letsencrypt.org"}, true},
+		{"example.com", []string{"digicert.com"}, false},
+		{"*.example.com", []string{"letsencrypt.org"}, false},
+	}
+	for _, tt := range tests {
+		if got := EvaluateCAA(tt.domain, records, tt.ids); got.Permitted != tt.want {
+			t.Errorf("%s %v: Permitted = %v, want %v", tt.domain, tt.ids, got.Permitted, tt.want)
+		}
+	}
+
+	if !EvaluateCAA("example.com", nil, []string{"x"}).Permitted {
+		t.Error("no records should permit")
+	}
+	if !EvaluateCAA("example.com", records[2:], []string{"x"}).Permitted {
+		t.Error("iodef only should permit")
+	}
+	critical := []CAARecord{{Flags: 128, Tag: "future"}, {Tag: CAATagIssue, Value: "x"}}
+	if r := EvaluateCAA("example.com", critical, []string{"x"}); r.Permitted || r.TrustReason() == nil {
+		t.Error("unknown critical tag should forbid issuance")
+	}
+}
+
+func TestCheckCertCAA(t *testing.T) {
+	chain := newChain(t)
+	resolver := &fakeResolver{records: map[string][]certm.DNSRecord{
+		"example.com": {caa("example.com", `0 issue "letsencrypt.org"`), caa("example.com", `0 issuewild "digicert.com"`)},
+	}}
+	data := &certm.CertOutputData{ChainPEM: []string{certutil.EncodeCertificatePEM(chain[0])}}
+
+	results, err := CheckCertCAA(resolver, data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || !results[0].Permitted || results[1].Permitted {
+		t.Fatalf("results = %+v %+v", results[0], results[1])
+	}
+	if results[0].Name != "example.com" {
+		t.Errorf("Name = %s", results[0].Name)
+	}
+	reason := results[1].TrustReason()
+	if reason == nil || reason.Code != certm.TrustStatusCAANotPermitted || reason.Severity != certm.TrustSeverityWarning {
+		t.Errorf("reason = %+v", reason)
+	}
+
+	// 逐级向上查找
+	resolver.queries = nil
+	result, err := CheckCAA(resolver, "a.b.example.com", []string{"letsencrypt.org"})
+	if err != nil || !result.Permitted || result.Name != "example.com" {
+		t.Errorf("result = %+v, err = %v", result, err)
+	}
+	if len(resolver.queries) != 3 {
+		t.Errorf("queries = %v", resolver.queries)
+	}
+}
+
+func TestTLSA(t *testing.T) {
+	chain := newChain(t)
+	data := &certm.CertOutputData{ChainPEM: []string{certutil.EncodeCertificatePEM(chain[0])}}
+
+	name, record, err := GenerateTLSA(data, "example.com", 443)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "_443._tcp.example.com" || len(record.Data) != 32 {
+		t.Fatalf("name = %s, record = %s", name, record)
+	}
+	parsed, err := ParseTLSA(record.String())
+	if err != nil || parsed.String() != record.String() {
+		t.Fatalf("round trip: %v %v", parsed, err)
+	}
+
+	ta, _ := NewTLSARecord(chain[1], TLSAUsageDANETA, TLSASelectorCert, TLSAMatchingSHA512)
+	if !ta.Matches(chain) || ta.Matches(chain[:1]) {
+		t.Error("DANE-TA record should match the CA only")
+	}
+
+	resolver := &fakeResolver{authenticated: true, records: map[string][]certm.DNSRecord{
+		name: {{Name: name, Type: certm.DNSRecordTLSA, Value: record.String()}},
+	}}
+	result, err := CheckTLSA(resolver, "example.com", 443, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Configured() || len(result.Matched) != 1 || !result.Authenticated || result.TrustReason() != nil {
+		t.Errorf("result = %+v", result)
+	}
+
+	// 换证后记录不匹配
+	other := newChain(t)
+	result, _ = CheckTLSA(resolver, "example.com", 443, other)
+	if reason := result.TrustReason(); reason == nil || reason.Code != certm.TrustStatusTLSAMismatch {
+		t.Errorf("reason = %+v", reason)
+	}
+
+	// 未配置记录
+	result, _ = CheckTLSA(resolver, "example.org", 443, chain)
+	if result.Configured() || result.TrustReason() != nil {
+		t.Errorf("result = %+v", result)
+	}
+}
@@ -0,0 +1,194 @@
+package dnscheck
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	certm "github.com/trustasia-com/certm-plugin-sdk"
+)
+
+// TLSA证书用途
+const (
+	TLSAUsagePKIXTA uint8 = 0 // CA约束，需通过PKIX校验
+	TLSAUsagePKIXEE uint8 = 1 // 终端证书约束，需通过PKIX校验
+	TLSAUsageDANETA uint8 = 2 // 信任锚声明
+	TLSAUsageDANEEE uint8 = 3 // 终端证书声明
+)
+
+// TLSA选择器
+const (
+	TLSASelectorCert uint8 = 0 // 完整证书
+	TLSASelectorSPKI uint8 = 1 // 主题公钥信息
+)
+
+// TLSA匹配类型
+const (
+	TLSAMatchingExact  uint8 = 0 // 原始数据
+	TLSAMatchingSHA256 uint8 = 1 // SHA-256
+	TLSAMatchingSHA512 uint8 = 2 // SHA-512
+)
+
+// TLSARecord TLSA记录
+type TLSARecord struct {
+	Usage        uint8  `json:"usage"`         // 证书用途
+	Selector     uint8  `json:"selector"`      // 选择器
+	MatchingType uint8  `json:"matching_type"` // 匹配类型
+	Data         []byte `json:"data"`          // 关联数据
+}
+
+// ParseTLSA 解析文本格式的TLSA记录，如 3 1 1 0123abcd...
+func ParseTLSA(value string) (*TLSARecord, error) {
+	fields := strings.Fields(value)
+	if len(fields) < 4 {
+		return nil, fmt.Errorf("dnscheck: invalid TLSA record %q", value)
+	}
+	var params [3]uint8
+	for i := range params {
+		n, err := strconv.ParseUint(fields[i], 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("dnscheck: invalid TLSA record %q", value)
+		}
+		params[i] = uint8(n)
+	}
+	// 关联数据可能按空格分段
+	data, err := hex.DecodeString(strings.Join(fields[3:], ""))
+	if err != nil {
+		return nil, fmt.Errorf("dnscheck: invalid TLSA data in %q", value)
+	}
+	return &TLSARecord{Usage: params[0], Selector: params[1], MatchingType: params[2], Data: data}, nil
+}
+
+// NewTLSARecord 根据证书生成TLSA记录
+func NewTLSARecord(cert *x509.Certificate, usage, selector, matchingType uint8) (*TLSARecord, error) {
+	data, err := tlsaData(cert, selector, matchingType)
+	if err != nil {
+		return nil, err
+	}
+	return &TLSARecord{Usage: usage, Selector: selector, MatchingType: matchingType, Data: data}, nil
+}
+
+// String 返回文本格式
+func (r *TLSARecord) String() string {
+	return fmt.Sprintf("%d %d %d %s", r.Usage, r.Selector, r.MatchingType, hex.EncodeToString(r.Data))
+}
+
+// Matches 判断记录是否与证书链匹配，链中第一个为叶子证书
+//
+// 终端证书用途仅匹配叶子证书，CA用途匹配链中其余证书；不进行PKIX校验。
+func (r *TLSARecord) Matches(chain []*x509.Certificate) bool {
+	if len(chain) == 0 {
+		return false
+	}
+	candidates := chain[:1]
+	if r.Usage == TLSAUsagePKIXTA || r.Usage == TLSAUsageDANETA {
+		candidates = chain[1:]
+	}
+	for _, cert := range candidates {
+		data, err := tlsaData(cert, r.Selector, r.MatchingType)
+		if err == nil && bytes.Equal(data, r.Data) {
+			return true
+		}
+	}
+	return false
+}
+
+// tlsaData 计算证书的关联数据
+func tlsaData(cert *x509.Certificate, selector, matchingType uint8) ([]byte, error) {
+	var raw []byte
+	switch selector {
+	case TLSASelectorCert:
+		raw = cert.Raw
+	case TLSASelectorSPKI:
+		raw = cert.RawSubjectPublicKeyInfo
+	default:
+		return nil, fmt.Errorf("dnscheck: unsupported TLSA selector %d", selector)
+	}
+	switch matchingType {
+	case TLSAMatchingExact:
+		return raw, nil
+	case TLSAMatchingSHA256:
+		sum := sha256.Sum256(raw)
+		return sum[:], nil
+	case TLSAMatchingSHA512:
+		sum := sha512.Sum512(raw)
+		return sum[:], nil
+	default:
+		return nil, fmt.Errorf("dnscheck: unsupported TLSA matching type %d", matchingType)
+	}
+}
+
+// TLSAName 返回TLSA记录名称，如 _443._tcp.example.com
+func TLSAName(host string, port int) string {
+	return fmt.Sprintf("_%d._tcp.%s", port, strings.TrimSuffix(host, "."))
+}
+
+// TLSAResult TLSA检查结果
+type TLSAResult struct {
+	Name          string       `json:"name"`          // 记录名称
+	Records       []TLSARecord `json:"records"`       // 查询到的记录
+	Matched       []TLSARecord `json:"matched"`       // 与证书链匹配的记录
+	Authenticated bool         `json:"authenticated"` // 是否通过DNSSEC验证
+}
+
+// Configured 判断是否配置了TLSA记录
+func (r *TLSAResult) Configured() bool {
+	return len(r.Records) > 0
+}
+
+// TrustReason 配置了TLSA记录但均不匹配时返回对应的信任原因
+func (r *TLSAResult) TrustReason() *certm.TrustReason {
+	if !r.Configured() || len(r.Matched) > 0 {
+		return nil
+	}
+	msg := fmt.Sprintf("none of the %d TLSA records at %s match the certificate chain", len(r.Records), r.Name)
+	if !r.Authenticated {
+		msg += " (records are not DNSSEC authenticated)"
+	}
+	reason := certm.NewTrustReason(certm.TrustStatusTLSAMismatch, msg)
+	return &reason
+}
+
+// CheckTLSA 查询主机端口的TLSA记录并与证书链比对
+func CheckTLSA(resolver certm.DNSResolver, host string, port int, chain []*x509.Certificate) (*TLSAResult, error) {
+	result := &TLSAResult{Name: TLSAName(host, port)}
+	resp, err := resolver.DNSLookup(&certm.DNSRequest{Name: result.Name, Type: certm.DNSRecordTLSA})
+	if err != nil {
+		return nil, fmt.Errorf("dnscheck: lookup TLSA %s: %w", result.Name, err)
+	}
+	result.Authenticated = resp.Authenticated
+	for _, rr := range resp.Records {
+		if rr.Type != certm.DNSRecordTLSA {
+			continue
+		}
+		record, err := ParseTLSA(rr.Value)
+		if err != nil {
+			return nil, err
+		}
+		result.Records = append(result.Records, *record)
+		if record.Matches(chain) {
+			result.Matched = append(result.Matched, *record)
+		}
+	}
+	return result, nil
+}
+
+// GenerateTLSA 为新证书生成 3 1 1（DANE-EE、公钥、SHA-256）TLSA记录，返回记录名称与记录
+//
+// 公钥不变时续期无需更新记录；更换密钥前应先发布新记录。
+func GenerateTLSA(data *certm.CertOutputData, host string, port int) (string, *TLSARecord, error) {
+	leaf, err := data.Leaf()
+	if err != nil {
+		return "", nil, fmt.Errorf("dnscheck: %w", err)
+	}
+	record, err := NewTLSARecord(leaf, TLSAUsageDANEEE, TLSASelectorSPKI, TLSAMatchingSHA256)
+	if err != nil {
+		return "", nil, err
+	}
+	return TLSAName(host, port), record, nil
+}
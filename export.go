@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/trustasia-com/certm-plugin-sdk/certutil"
 	"github.com/trustasia-com/certm-plugin-sdk/helper"
 )
 
@@ -39,7 +40,7 @@ func getConfigSchema(ctxPtr uint32) (ptr uint32) {
 
 	// 1. 读取并解析Context
 	certmCtx := parseCertmContext(ctxPtr)
-	ctx := SetContextKey(context.Background(), certmCtx, certmCtx.Language, certmCtx.ProjectID)
+	ctx := certmCtx.newContext()
 
 	// 2. 调用组件方法获取Schema
 	fields, err := component.GetConfigSchema(ctx)
@@ -65,7 +66,7 @@ func getDynamicOptions(ctxPtr, configPtr, keyPtr uint32) (ptr uint32) {
 
 	// 1. 读取并解析Context
 	certmCtx := parseCertmContext(ctxPtr)
-	ctx := SetContextKey(context.Background(), certmCtx, certmCtx.Language, certmCtx.ProjectID)
+	ctx := certmCtx.newContext()
 
 	// 2. 解析参数
 	configData := readFromMemory(configPtr)
@@ -104,7 +105,7 @@ func validateConfig(ctxPtr, configPtr uint32) (ptr uint32) {
 
 	// 1. 读取并解析Context
 	certmCtx := parseCertmContext(ctxPtr)
-	ctx := SetContextKey(context.Background(), certmCtx, certmCtx.Language, certmCtx.ProjectID)
+	ctx := certmCtx.newContext()
 
 	// 2. 解析参数
 	configData := readFromMemory(configPtr)
@@ -140,7 +141,7 @@ func execute(ctxPtr, configPtr, inputPtr uint32) (ptr uint32) {
 
 	// 1. 读取并解析Context
 	certmCtx := parseCertmContext(ctxPtr)
-	ctx := SetContextKey(context.Background(), certmCtx, certmCtx.Language, certmCtx.ProjectID)
+	ctx := certmCtx.newContext()
 
 	// 2. 解析参数
 	configData := readFromMemory(configPtr)
@@ -191,6 +192,29 @@ func (c *CertmContext) GetCertAssetListOfContainer(projectID, containerID int) (
 	return *list, nil
 }
 
+// ListCertContainers 分页获取证书容器列表
+func (c *CertmContext) ListCertContainers(projectID int, opts *ListOptions) (*Page[*CertContainerInfo], error) {
+	return call[Page[*CertContainerInfo]]("db_list_cert_container", projectID, opts.normalize())
+}
+
+// ListCertAssetsOfContainer 分页获取证书资产列表
+func (c *CertmContext) ListCertAssetsOfContainer(projectID, containerID int, opts *ListOptions) (*Page[*CertAssetInfo], error) {
+	return call[Page[*CertAssetInfo]]("db_list_cert_asset_of_container", projectID, containerID, opts.normalize())
+}
+
+// FindCertAssets 按域名、指纹或序列号查找证书资产
+func (c *CertmContext) FindCertAssets(projectID int, query *CertAssetQuery) ([]*CertAssetInfo, error) {
+	q, err := query.normalize()
+	if err != nil {
+		return nil, err
+	}
+	list, err := call[[]*CertAssetInfo]("db_find_cert_asset", projectID, q)
+	if err != nil {
+		return nil, err
+	}
+	return *list, nil
+}
+
 // GetCertAssetDetail 获取证书资产详情
 func (c *CertmContext) GetCertAssetDetail(projectID, assetID int) (*CertAssetDetail, error) {
 	asset, err := call[*CertAssetDetail]("db_get_cert_asset_detail", projectID, assetID)
@@ -200,6 +224,69 @@ func (c *CertmContext) GetCertAssetDetail(projectID, assetID int) (*CertAssetDet
 	return *asset, nil
 }
 
+// GetCertAssetHistory 获取证书容器的历史证书资产，按签发时间倒序
+func (c *CertmContext) GetCertAssetHistory(projectID, containerID int) ([]*CertAssetHistory, error) {
+	list, err := call[[]*CertAssetHistory]("db_get_cert_asset_history", projectID, containerID)
+	if err != nil {
+		return nil, err
+	}
+	return *list, nil
+}
+
+// GetCertAssetsBySHA1 按SHA1列表获取证书资产，未找到的SHA1将被忽略
+func (c *CertmContext) GetCertAssetsBySHA1(projectID int, sha1s []string) ([]*CertAssetHistory, error) {
+	normalized := make([]string, 0, len(sha1s))
+	for _, s := range sha1s {
+		if s = certutil.NormalizeFingerprint(s); s != "" {
+			normalized = append(normalized, s)
+		}
+	}
+	if len(normalized) == 0 {
+		return nil, nil
+	}
+	list, err := call[[]*CertAssetHistory]("db_get_cert_assets_by_sha1", projectID, normalized)
+	if err != nil {
+		return nil, err
+	}
+	return *list, nil
+}
+
+// CreateCertContainer 创建证书容器
+func (c *CertmContext) CreateCertContainer(projectID int, req *CreateCertContainerRequest) (*CertContainerInfo, error) {
+	if req == nil || req.CommonName == "" {
+		return nil, fmt.Errorf("common name is required")
+	}
+	return call[CertContainerInfo]("db_create_cert_container", projectID, req)
+}
+
+// ImportCertAsset 导入证书资产到证书容器
+func (c *CertmContext) ImportCertAsset(projectID, containerID int, data *CertOutputData) (*CertAssetInfo, error) {
+	if data == nil {
+		return nil, fmt.Errorf("certificate data is required")
+	}
+	if _, err := data.Leaf(); err != nil {
+		return nil, err
+	}
+	if data.SHA1 != "" {
+		if err := data.VerifySHA1(); err != nil {
+			return nil, err
+		}
+	}
+	return call[CertAssetInfo]("db_import_cert_asset", projectID, containerID, data)
+}
+
+// RevokeCertAsset 标记证书资产已吊销
+func (c *CertmContext) RevokeCertAsset(projectID, assetID int, reason string) error {
+	_, err := call[json.RawMessage]("db_revoke_cert_asset", projectID, assetID, reason)
+	return err
+}
+
+// AttachLabels 为证书容器或证书资产添加标签，已存在的键将被覆盖
+func (c *CertmContext) AttachLabels(projectID int, resource ResourceType, id int, labels map[string]string) error {
+	_, err := call[json.RawMessage]("db_attach_labels", projectID, resource, id, labels)
+	return err
+}
+
 // GetDeployerList 获取部署器列表
 func (c *CertmContext) GetDeployerList(projectID int, targetID string) ([]*DeployerInfo, error) {
 	list, err := call[[]*DeployerInfo]("db_get_deployer_list", projectID, targetID)
@@ -218,15 +305,99 @@ func (c *CertmContext) GetDeployerDetail(projectID, deployerID int) (*DeployerDe
 	return *deployer, nil
 }
 
+// GetCheckTargetList 获取检测目标列表
+func (c *CertmContext) GetCheckTargetList(projectID int) ([]*CheckTargetInfo, error) {
+	list, err := call[[]*CheckTargetInfo]("db_get_check_target_list", projectID)
+	if err != nil {
+		return nil, err
+	}
+	return *list, nil
+}
+
+// GetCheckTargetDetail 获取检测目标详情
+func (c *CertmContext) GetCheckTargetDetail(projectID, targetID int) (*CheckTargetDetail, error) {
+	target, err := call[*CheckTargetDetail]("db_get_check_target_detail", projectID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	return *target, nil
+}
+
+// GetLatestCheckResults 获取检测目标最近一次的检测结果
+func (c *CertmContext) GetLatestCheckResults(projectID, targetID int) ([]*CheckEndpointResult, error) {
+	list, err := call[[]*CheckEndpointResult]("db_get_latest_check_results", projectID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	return *list, nil
+}
+
+// GetWorkflowSteps 获取工作流步骤列表
+func (c *CertmContext) GetWorkflowSteps(projectID, workflowID int) ([]*WorkflowStepInfo, error) {
+	list, err := call[[]*WorkflowStepInfo]("db_get_workflow_steps", projectID, workflowID)
+	if err != nil {
+		return nil, err
+	}
+	return *list, nil
+}
+
 // GetNoticeRuleList 获取告警规则列表
-func (c *CertmContext) GetNoticeRuleList() ([]*NoticeRuleInfo, error) {
-	list, err := call[[]*NoticeRuleInfo]("db_get_notice_rule_list")
+func (c *CertmContext) GetNoticeRuleList(projectID int) ([]*NoticeRuleInfo, error) {
+	list, err := call[[]*NoticeRuleInfo]("db_get_notice_rule_list", projectID)
 	if err != nil {
 		return nil, err
 	}
 	return *list, nil
 }
 
+// GetNoticeRuleDetail 获取告警规则详情
+func (c *CertmContext) GetNoticeRuleDetail(projectID, ruleID int) (*NoticeRuleDetail, error) {
+	rule, err := call[*NoticeRuleDetail]("db_get_notice_rule_detail", projectID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	return *rule, nil
+}
+
+// HTTPDo 通过主机发起HTTP请求
+func (c *CertmContext) HTTPDo(req *HTTPRequest) (*HTTPResponse, error) {
+	return call[HTTPResponse]("http_request", req)
+}
+
+// TLSProbe 通过主机完成TLS握手并返回对端证书链
+func (c *CertmContext) TLSProbe(req *TLSProbeRequest) (*TLSProbeResponse, error) {
+	return call[TLSProbeResponse]("tls_probe", req)
+}
+
+// DNSLookup 通过主机查询DNS记录
+func (c *CertmContext) DNSLookup(req *DNSRequest) (*DNSResponse, error) {
+	return call[DNSResponse]("dns_lookup", req)
+}
+
+// GetState 读取插件状态
+func (c *CertmContext) GetState(key string) ([]byte, error) {
+	value, err := call[[]byte]("state_get", key)
+	if err != nil {
+		return nil, err
+	}
+	return *value, nil
+}
+
+// SetState 写入插件状态
+func (c *CertmContext) SetState(key string, value []byte, ttl int) error {
+	_, err := call[json.RawMessage]("state_set", key, value, ttl)
+	return err
+}
+
+// ResolveSecret 通过主机解析密钥引用
+func (c *CertmContext) ResolveSecret(ref string) (string, error) {
+	value, err := call[string]("secret_resolve", ref)
+	if err != nil {
+		return "", err
+	}
+	return *value, nil
+}
+
 // sprintf 简化的格式化字符串（兼容TinyGo）
 func sprintf(format string, args ...any) string {
 	// 简化版本：如果有参数就用fmt.Sprintf，否则直接返回
@@ -251,6 +422,17 @@ func (c *CertmContext) Info(format string, args ...any) {
 	hostLogImpl("info", sprintf(format, args...))
 }
 
+// newContext 构建组件调用上下文
+func (c *CertmContext) newContext() context.Context {
+	ctx := SetContextKey(context.Background(), NewCachedDataAccess(c, c.Cache), c.Language, c.ProjectID)
+	ctx = SetHTTPClient(ctx, c)
+	ctx = SetTLSProber(ctx, c)
+	ctx = SetDNSResolver(ctx, c)
+	ctx = SetWorkflowRun(ctx, c.Workflow)
+	ctx = SetSecretResolver(ctx, c)
+	return SetStateStore(ctx, c)
+}
+
 // parseCertmContext 从内存指针解析Context
 func parseCertmContext(ptr uint32) *CertmContext {
 	ctx := &CertmContext{}
@@ -262,7 +444,7 @@ func parseCertmContext(ptr uint32) *CertmContext {
 	// 从内存读取Context数据
 	data := readFromMemory(ptr)
 
-	// Context数据格式: {"language": "zh-CN", "project_id": 123}
+	// Context数据格式: {"language": "zh-CN", "project_id": 123, "workflow": {...}, "cache": {...}}
 	if err := json.Unmarshal(data, ctx); err != nil {
 		return ctx
 	}
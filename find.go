@@ -0,0 +1,54 @@
+package certm
+
+import (
+	"crypto/x509"
+	"errors"
+	"strings"
+
+	"github.com/trustasia-com/certm-plugin-sdk/certutil"
+)
+
+// ErrEmptyQuery 查询条件为空
+var ErrEmptyQuery = errors.New("certm: empty cert asset query")
+
+// CertAssetQuery 证书资产查询条件，多个条件同时设置时需全部满足
+type CertAssetQuery struct {
+	Domain         string `json:"domain,omitempty"`          // 域名，匹配SAN或覆盖该域名的通配符SAN
+	ExactDomain    bool   `json:"exact_domain,omitempty"`    // 仅精确匹配域名，不匹配通配符
+	SHA1           string `json:"sha1,omitempty"`            // SHA1指纹
+	SHA256         string `json:"sha256,omitempty"`          // SHA256指纹
+	Serial         string `json:"serial,omitempty"`          // 序列号（十六进制）
+	IncludeExpired bool   `json:"include_expired,omitempty"` // 是否包含已过期证书
+}
+
+// QueryByCertificate 以证书SHA256指纹构建查询条件，用于定位端点当前使用的托管证书
+func QueryByCertificate(cert *x509.Certificate) *CertAssetQuery {
+	fp, _ := certutil.Fingerprint(cert, certutil.FingerprintSHA256)
+	return &CertAssetQuery{SHA256: fp, IncludeExpired: true}
+}
+
+// normalize 返回规范化后的副本：域名转小写，指纹去除分隔符，序列号去除前导零
+func (q *CertAssetQuery) normalize() (*CertAssetQuery, error) {
+	if q == nil {
+		return nil, ErrEmptyQuery
+	}
+	n := *q
+	n.Domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(n.Domain)), ".")
+	n.SHA1 = certutil.NormalizeFingerprint(n.SHA1)
+	n.SHA256 = certutil.NormalizeFingerprint(n.SHA256)
+	if serial := certutil.NormalizeFingerprint(n.Serial); serial != "" {
+		// 与 certutil.SerialHex 保持一致：按字节补齐，零值为 00
+		serial = strings.TrimLeft(strings.TrimPrefix(serial, "0x"), "0")
+		if len(serial)%2 == 1 {
+			serial = "0" + serial
+		}
+		if serial == "" {
+			serial = "00"
+		}
+		n.Serial = serial
+	}
+	if n.Domain == "" && n.SHA1 == "" && n.SHA256 == "" && n.Serial == "" {
+		return nil, ErrEmptyQuery
+	}
+	return &n, nil
+}
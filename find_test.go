@@ -0,0 +1,31 @@
+package certm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCertAssetQueryNormalize(t *testing.T) {
+	q, err := (&CertAssetQuery{
+		Domain: " WWW.Example.COM. ",
+		SHA1:   "AB:CD:EF",
+		Serial: "0x00:0A:bc",
+	}).normalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Domain != "www.example.com" || q.SHA1 != "abcdef" || q.Serial != "0abc" {
+		t.Errorf("normalize = %+v", q)
+	}
+
+	q, _ = (&CertAssetQuery{Serial: "000"}).normalize()
+	if q.Serial != "00" {
+		t.Errorf("Serial = %s", q.Serial)
+	}
+
+	for _, q := range []*CertAssetQuery{nil, {}, {IncludeExpired: true, Domain: " "}} {
+		if _, err := q.normalize(); !errors.Is(err, ErrEmptyQuery) {
+			t.Errorf("normalize(%+v) err = %v", q, err)
+		}
+	}
+}
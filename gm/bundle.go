@@ -0,0 +1,209 @@
+package gm
+
+import (
+	"crypto/aes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/trustasia-com/certm-plugin-sdk/hostmatch"
+	"github.com/trustasia-com/certm-plugin-sdk/internal/pkcs"
+)
+
+// NTLSCiphers Tongsuo NTLS（GM/T 0024 TLCP）常用密码套件
+const NTLSCiphers = "ECC-SM2-SM4-GCM-SM3:ECC-SM2-SM4-CBC-SM3:ECDHE-SM2-SM4-GCM-SM3:ECDHE-SM2-SM4-CBC-SM3"
+
+// Bundle 部署文件包
+type Bundle struct {
+	Files  map[string][]byte // 文件名 -> 内容
+	Config string            // 配置片段
+}
+
+// PEMFiles 双证书PEM文件：签名证书链、签名私钥、加密证书、加密私钥
+type PEMFiles struct {
+	SignCertPEM string // 签名证书链（含中间证书）
+	SignKeyPEM  string // 签名私钥（PKCS#8）
+	EncCertPEM  string // 加密证书（含中间证书）
+	EncKeyPEM   string // 加密私钥（PKCS#8）
+}
+
+// PEMFiles 导出双证书PEM文件，适用于各类国密负载均衡与网关
+func (d *DualCert) PEMFiles() (*PEMFiles, error) {
+	signKey, err := keyPEM(d.SignKey)
+	if err != nil {
+		return nil, err
+	}
+	encKey, err := keyPEM(d.EncKey)
+	if err != nil {
+		return nil, err
+	}
+	return &PEMFiles{
+		SignCertPEM: chainPEM(d.SignCert, d.Intermediates),
+		SignKeyPEM:  signKey,
+		EncCertPEM:  chainPEM(d.EncCert, d.Intermediates),
+		EncKeyPEM:   encKey,
+	}, nil
+}
+
+// NginxOptions Tongsuo/GmSSL 风格 nginx 配置选项
+type NginxOptions struct {
+	Dir        string // 证书目录，默认 /etc/nginx/ssl
+	Name       string // 文件名前缀，默认使用签名证书通用名称，仅保留 [a-z0-9._-]
+	ServerName string // server_name，默认使用签名证书通用名称，须为有效域名
+	Port       int    // 监听端口，默认 443
+}
+
+// NginxBundle 生成 Tongsuo NTLS nginx 部署文件及 server 配置片段
+func (d *DualCert) NginxBundle(opts *NginxOptions) (*Bundle, error) {
+	if opts == nil {
+		opts = &NginxOptions{}
+	}
+	dir := opts.Dir
+	if dir == "" {
+		dir = "/etc/nginx/ssl"
+	}
+	if strings.ContainsFunc(dir, func(r rune) bool { return r <= ' ' || strings.ContainsRune(";{}\"'\\", r) }) {
+		return nil, fmt.Errorf("sm2: invalid nginx certificate directory %q", dir)
+	}
+	name := opts.Name
+	if name == "" {
+		name = d.SignCert.Subject.CommonName
+	}
+	name = fileName(name)
+	serverName := opts.ServerName
+	if serverName == "" {
+		serverName = d.SignCert.Subject.CommonName
+	}
+	// 防止通用名称或配置中的 ;、{、换行等字符注入额外的 nginx 指令
+	if !hostmatch.ValidDomain(serverName) || serverName != strings.TrimSpace(serverName) {
+		return nil, fmt.Errorf("sm2: invalid nginx server name %q", serverName)
+	}
+	port := opts.Port
+	if port == 0 {
+		port = 443
+	}
+
+	files, err := d.PEMFiles()
+	if err != nil {
+		return nil, err
+	}
+	signCert, signKey := name+"_sign.crt", name+"_sign.key"
+	encCert, encKey := name+"_enc.crt", name+"_enc.key"
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "server {\n")
+	fmt.Fprintf(&b, "    listen %d ssl;\n", port)
+	fmt.Fprintf(&b, "    server_name %s;\n\n", serverName)
+	fmt.Fprintf(&b, "    enable_ntls on;\n")
+	fmt.Fprintf(&b, "    ssl_sign_certificate     %s;\n", path.Join(dir, signCert))
+	fmt.Fprintf(&b, "    ssl_sign_certificate_key %s;\n", path.Join(dir, signKey))
+	fmt.Fprintf(&b, "    ssl_enc_certificate      %s;\n", path.Join(dir, encCert))
+	fmt.Fprintf(&b, "    ssl_enc_certificate_key  %s;\n", path.Join(dir, encKey))
+	fmt.Fprintf(&b, "    ssl_ciphers \"%s\";\n", NTLSCiphers)
+	fmt.Fprintf(&b, "}\n")
+
+	return &Bundle{
+		Files: map[string][]byte{
+			signCert: []byte(files.SignCertPEM),
+			signKey:  []byte(files.SignKeyPEM),
+			encCert:  []byte(files.EncCertPEM),
+			encKey:   []byte(files.EncKeyPEM),
+		},
+		Config: b.String(),
+	}, nil
+}
+
+// PFXEncryption 国密PFX加密方案
+type PFXEncryption string
+
+const (
+	// PFXSM4 PBKDF2-HMAC-SM3 + SM4-CBC，MAC使用SM3，适用于 GmSSL 等国密工具链
+	PFXSM4 PFXEncryption = "sm4"
+	// PFXCompat AES-256-CBC + PBKDF2-HMAC-SHA256，MAC使用SHA256，适用于 OpenSSL 3/Tongsuo
+	PFXCompat PFXEncryption = "compat"
+)
+
+// PFXOptions 国密PFX选项
+type PFXOptions struct {
+	Password   string        // 口令
+	Encryption PFXEncryption // 加密方案，默认 PFXSM4
+	Iterations int           // 迭代次数，默认 2048
+}
+
+// PFX 将签名与加密双证书打包为一个PKCS#12文件，友好名称分别为 sign 与 enc
+func (d *DualCert) PFX(opts *PFXOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &PFXOptions{}
+	}
+	iterations := opts.Iterations
+	if iterations <= 0 {
+		iterations = 2048
+	}
+
+	signKey, err := MarshalPKCS8PrivateKey(d.SignKey)
+	if err != nil {
+		return nil, err
+	}
+	encKey, err := MarshalPKCS8PrivateKey(d.EncKey)
+	if err != nil {
+		return nil, err
+	}
+	signCerts := [][]byte{d.SignCert.Raw}
+	for _, cert := range d.Intermediates {
+		signCerts = append(signCerts, cert.Raw)
+	}
+
+	pfxOpts := &pkcs.PFXOptions{Password: opts.Password, MacIterations: iterations}
+	switch opts.Encryption {
+	case PFXCompat:
+		enc := &pkcs.PBES2{PRF: sha256.New, PRFOID: pkcs.OIDHMACWithSHA256, Cipher: aes.NewCipher, CipherOID: pkcs.OIDAES256CBC, KeyLen: 32, Iterations: iterations, SaltLen: 16}
+		pfxOpts.KeyEncryptor, pfxOpts.CertEncryptor = enc, enc
+		pfxOpts.MacHash, pfxOpts.MacOID = sha256.New, pkcs.OIDSHA256
+	default:
+		enc := &pkcs.PBES2{PRF: NewSM3, PRFOID: OIDHMACSM3, Cipher: NewSM4Cipher, CipherOID: OIDSM4CBC, KeyLen: 16, Iterations: iterations, SaltLen: 16}
+		pfxOpts.KeyEncryptor, pfxOpts.CertEncryptor = enc, enc
+		pfxOpts.MacHash, pfxOpts.MacOID = NewSM3, OIDSM3
+	}
+
+	return pkcs.EncodePFXEntries([]pkcs.PFXEntry{
+		{Key: signKey, Certs: signCerts, FriendlyName: "sign"},
+		{Key: encKey, Certs: [][]byte{d.EncCert.Raw}, FriendlyName: "enc"},
+	}, pfxOpts)
+}
+
+// keyPEM 私钥编码为PKCS#8 PEM
+func keyPEM(key *PrivateKey) (string, error) {
+	der, err := MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+// chainPEM 证书与中间证书编码为PEM
+func chainPEM(cert *x509.Certificate, intermediates []*x509.Certificate) string {
+	var b strings.Builder
+	_ = pem.Encode(&b, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	for _, c := range intermediates {
+		_ = pem.Encode(&b, &pem.Block{Type: "CERTIFICATE", Bytes: c.Raw})
+	}
+	return b.String()
+}
+
+// fileName 将通用名称转换为文件名，仅保留 [a-z0-9._-]，其他字符（如通配符）替换为下划线
+func fileName(cn string) string {
+	name := []byte(strings.ToLower(cn))
+	for i, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '.' || c == '_' || c == '-') {
+			name[i] = '_'
+		}
+	}
+	// 去除开头的点，避免生成隐藏文件或 .. 路径
+	if s := strings.TrimLeft(string(name), "."); s != "" {
+		return s
+	}
+	return "server"
+}
@@ -0,0 +1,151 @@
+package gm
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+type certificate struct {
+	Raw                asn1.RawContent
+	TBSCertificate     tbsCertificate
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	SignatureValue     asn1.BitString
+}
+
+type tbsCertificate struct {
+	Raw                asn1.RawContent
+	Version            int `asn1:"optional,explicit,default:0,tag:0"`
+	SerialNumber       *big.Int
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Issuer             asn1.RawValue
+	Validity           asn1.RawValue
+	Subject            asn1.RawValue
+	PublicKey          publicKeyInfo
+	IssuerUniqueID     asn1.BitString   `asn1:"optional,tag:1"`
+	SubjectUniqueID    asn1.BitString   `asn1:"optional,tag:2"`
+	Extensions         []pkix.Extension `asn1:"omitempty,optional,explicit,tag:3"`
+}
+
+var (
+	placeholderOnce sync.Once
+	placeholderSPKI []byte
+)
+
+// placeholderPublicKey 用于替换SM2公钥的P-256占位公钥（基点G）
+func placeholderPublicKey() []byte {
+	placeholderOnce.Do(func() {
+		curve := elliptic.P256()
+		placeholderSPKI, _ = x509.MarshalPKIXPublicKey(&ecdsa.PublicKey{Curve: curve, X: curve.Params().Gx, Y: curve.Params().Gy})
+	})
+	return placeholderSPKI
+}
+
+// ParseCertificate 解析SM2证书
+//
+// 标准库无法识别SM2曲线，解析时先将公钥替换为占位公钥交由 x509 解析其余字段，
+// 再还原原始DER与SM2公钥。返回证书的 PublicKey 为 *PublicKey，
+// 签名校验需使用 CheckSignature 而非 x509.Certificate.CheckSignatureFrom。
+func ParseCertificate(der []byte) (*x509.Certificate, error) {
+	var c certificate
+	if rest, err := asn1.Unmarshal(der, &c); err != nil {
+		return nil, fmt.Errorf("sm2: parse certificate: %w", err)
+	} else if len(rest) != 0 {
+		return nil, errors.New("sm2: trailing data after certificate")
+	}
+	pub, err := parsePublicKey(&c.TBSCertificate.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// 替换公钥后重新编码
+	tbs := c.TBSCertificate
+	tbs.Raw = nil
+	tbs.PublicKey = publicKeyInfo{Raw: placeholderPublicKey()}
+	tbsDER, err := asn1.Marshal(tbs)
+	if err != nil {
+		return nil, err
+	}
+	substitute, err := asn1.Marshal(certificate{
+		TBSCertificate:     tbsCertificate{Raw: tbsDER},
+		SignatureAlgorithm: c.SignatureAlgorithm,
+		SignatureValue:     c.SignatureValue,
+	})
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(substitute)
+	if err != nil {
+		return nil, fmt.Errorf("sm2: parse certificate: %w", err)
+	}
+
+	cert.Raw = c.Raw
+	cert.RawTBSCertificate = c.TBSCertificate.Raw
+	cert.RawSubjectPublicKeyInfo = c.TBSCertificate.PublicKey.Raw
+	cert.PublicKey = pub
+	cert.PublicKeyAlgorithm = x509.UnknownPublicKeyAlgorithm
+	cert.Signature = c.SignatureValue.RightAlign()
+	return cert, nil
+}
+
+// ParseCertificatesPEM 解析PEM格式SM2证书，每个字符串可包含多个证书块
+func ParseCertificatesPEM(pems ...string) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for _, s := range pems {
+		rest := []byte(s)
+		for {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+			cert, err := ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			certs = append(certs, cert)
+		}
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("sm2: no certificate found")
+	}
+	return certs, nil
+}
+
+// PublicKeyOf 获取证书中的SM2公钥
+func PublicKeyOf(cert *x509.Certificate) (*PublicKey, error) {
+	pub, ok := cert.PublicKey.(*PublicKey)
+	if !ok {
+		return nil, errors.New("sm2: certificate public key is not SM2")
+	}
+	return pub, nil
+}
+
+// CheckSignature 校验 cert 是否由 parent 使用 SM2-with-SM3 签发
+func CheckSignature(cert, parent *x509.Certificate) error {
+	var c certificate
+	if _, err := asn1.Unmarshal(cert.Raw, &c); err != nil {
+		return fmt.Errorf("sm2: parse certificate: %w", err)
+	}
+	if !c.SignatureAlgorithm.Algorithm.Equal(OIDSM2WithSM3) {
+		return fmt.Errorf("sm2: unsupported signature algorithm %v", c.SignatureAlgorithm.Algorithm)
+	}
+	pub, err := PublicKeyOf(parent)
+	if err != nil {
+		return err
+	}
+	if !Verify(pub, cert.RawTBSCertificate, cert.Signature) {
+		return errors.New("sm2: certificate signature verification failed")
+	}
+	return nil
+}
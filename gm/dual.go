@@ -0,0 +1,114 @@
+package gm
+
+import (
+	"bytes"
+	"crypto/x509"
+	"errors"
+
+	certm "github.com/trustasia-com/certm-plugin-sdk"
+)
+
+var (
+	ErrNoEncCert         = errors.New("sm2: encryption certificate is missing")                                                    // 缺少加密证书
+	ErrNoEncKey          = errors.New("sm2: encryption private key is missing")                                                    // 缺少加密私钥
+	ErrSignKeyMismatch   = errors.New("sm2: signing private key does not match signing certificate")                               // 签名私钥与签名证书不匹配
+	ErrEncKeyMismatch    = errors.New("sm2: encryption private key does not match encryption certificate")                         // 加密私钥与加密证书不匹配
+	ErrSameKeyPair       = errors.New("sm2: signing and encryption certificates share the same key pair")                          // 签名与加密证书使用相同密钥对
+	ErrSubjectMismatch   = errors.New("sm2: signing and encryption certificates have different subjects")                          // 主题不一致
+	ErrIssuerMismatch    = errors.New("sm2: signing and encryption certificates have different issuers")                           // 颁发者不一致
+	ErrSignKeyUsage      = errors.New("sm2: signing certificate lacks digitalSignature key usage")                                 // 签名证书密钥用途错误
+	ErrEncKeyUsage       = errors.New("sm2: encryption certificate lacks keyEncipherment/dataEncipherment/keyAgreement key usage") // 加密证书密钥用途错误
+	ErrIssuerNotVerified = errors.New("sm2: certificate is not signed by the issuer in the chain")                                 // 证书未由链中颁发者签发
+)
+
+// DualCert SM2双证书
+type DualCert struct {
+	SignCert      *x509.Certificate   // 签名证书
+	SignKey       *PrivateKey         // 签名私钥
+	EncCert       *x509.Certificate   // 加密证书
+	EncKey        *PrivateKey         // 加密私钥
+	Intermediates []*x509.Certificate // 中间证书
+}
+
+// ParseDualCert 从证书数据解析SM2双证书
+//
+// ChainPEM/KeyPEM 为签名证书链与签名私钥，EncCertPEM/EncKeyPEM 为加密证书与加密私钥。
+// 加密私钥需为明文PEM，GM/T 0010 数字信封格式需由证书来源组件先行解封。
+func ParseDualCert(data *certm.CertOutputData) (*DualCert, error) {
+	chain, err := ParseCertificatesPEM(data.ChainPEM...)
+	if err != nil {
+		return nil, err
+	}
+	signKey, err := ParsePrivateKeyPEM(data.KeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	if data.EncCertPEM == "" {
+		return nil, ErrNoEncCert
+	}
+	if data.EncKeyPEM == "" {
+		return nil, ErrNoEncKey
+	}
+	encCerts, err := ParseCertificatesPEM(data.EncCertPEM)
+	if err != nil {
+		return nil, err
+	}
+	encKey, err := ParsePrivateKeyPEM(data.EncKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DualCert{
+		SignCert:      chain[0],
+		SignKey:       signKey,
+		EncCert:       encCerts[0],
+		EncKey:        encKey,
+		Intermediates: chain[1:],
+	}, nil
+}
+
+// Verify 校验签名证书与加密证书是否为同一主体的一对证书（GM/T 0015）
+//
+// 校验项：私钥与证书匹配、两证书使用不同密钥对、主题与颁发者一致、密钥用途正确，
+// 若证书链包含颁发者则同时校验两张证书的签名。
+func (d *DualCert) Verify() error {
+	signPub, err := PublicKeyOf(d.SignCert)
+	if err != nil {
+		return err
+	}
+	encPub, err := PublicKeyOf(d.EncCert)
+	if err != nil {
+		return err
+	}
+
+	if !signPub.Equal(d.SignKey.Public()) {
+		return ErrSignKeyMismatch
+	}
+	if !encPub.Equal(d.EncKey.Public()) {
+		return ErrEncKeyMismatch
+	}
+	if signPub.Equal(encPub) {
+		return ErrSameKeyPair
+	}
+	if !bytes.Equal(d.SignCert.RawSubject, d.EncCert.RawSubject) {
+		return ErrSubjectMismatch
+	}
+	if !bytes.Equal(d.SignCert.RawIssuer, d.EncCert.RawIssuer) {
+		return ErrIssuerMismatch
+	}
+
+	if d.SignCert.KeyUsage != 0 && d.SignCert.KeyUsage&(x509.KeyUsageDigitalSignature|x509.KeyUsageContentCommitment) == 0 {
+		return ErrSignKeyUsage
+	}
+	if d.EncCert.KeyUsage != 0 && d.EncCert.KeyUsage&(x509.KeyUsageKeyEncipherment|x509.KeyUsageDataEncipherment|x509.KeyUsageKeyAgreement) == 0 {
+		return ErrEncKeyUsage
+	}
+
+	if len(d.Intermediates) > 0 {
+		issuer := d.Intermediates[0]
+		if CheckSignature(d.SignCert, issuer) != nil || CheckSignature(d.EncCert, issuer) != nil {
+			return ErrIssuerNotVerified
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package gm
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"maps"
+	"math/big"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	certm "github.com/trustasia-com/certm-plugin-sdk"
+)
+
+// createSM2Certificate 借助 x509.CreateCertificate 生成模板，再替换为SM2公钥并以SM2-with-SM3签名
+func createSM2Certificate(t *testing.T, tmpl, parent *x509.Certificate, pub *PublicKey, signer *PrivateKey) *x509.Certificate {
+	t.Helper()
+	placeholder, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if parent == nil {
+		parent = tmpl
+	} else {
+		// 颁发者公钥为SM2，模板阶段跳过与占位私钥的匹配检查
+		p := *parent
+		p.PublicKey = nil
+		parent = &p
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &placeholder.PublicKey, placeholder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var c certificate
+	if _, err := asn1.Unmarshal(der, &c); err != nil {
+		t.Fatal(err)
+	}
+	spki, _ := MarshalPublicKey(pub)
+	sigAlgo := pkix.AlgorithmIdentifier{Algorithm: OIDSM2WithSM3}
+
+	tbs := c.TBSCertificate
+	tbs.Raw = nil
+	tbs.SignatureAlgorithm = sigAlgo
+	tbs.PublicKey = publicKeyInfo{Raw: spki}
+	tbsDER, _ := asn1.Marshal(tbs)
+	sig, err := signer.Sign(rand.Reader, tbsDER, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certDER, _ := asn1.Marshal(certificate{
+		TBSCertificate:     tbsCertificate{Raw: tbsDER},
+		SignatureAlgorithm: sigAlgo,
+		SignatureValue:     asn1.BitString{Bytes: sig, BitLength: len(sig) * 8},
+	})
+	cert, err := ParseCertificate(certDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func certPEM(cert *x509.Certificate) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+}
+
+func newTestDualCert(t *testing.T, encUsage x509.KeyUsage) *certm.CertOutputData {
+	t.Helper()
+	now := time.Now()
+
+	caKey, _ := GenerateKey(rand.Reader)
+	ca := createSM2Certificate(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "GM Test CA"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil, &caKey.PublicKey, caKey)
+
+	newLeaf := func(serial int64, usage x509.KeyUsage) (*x509.Certificate, *PrivateKey) {
+		key, _ := GenerateKey(rand.Reader)
+		cert := createSM2Certificate(t, &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "gm.example.com"},
+			DNSNames:     []string{"gm.example.com"},
+			NotBefore:    now.Add(-time.Hour),
+			NotAfter:     now.Add(time.Hour),
+			KeyUsage:     usage,
+		}, ca, &key.PublicKey, caKey)
+		return cert, key
+	}
+	signCert, signKey := newLeaf(2, x509.KeyUsageDigitalSignature)
+	encCert, encKey := newLeaf(3, encUsage)
+
+	signKeyPEM, _ := keyPEM(signKey)
+	encKeyPEM, _ := keyPEM(encKey)
+	return &certm.CertOutputData{
+		CommonName: "gm.example.com",
+		KeyPEM:     signKeyPEM,
+		ChainPEM:   []string{certPEM(signCert), certPEM(ca)},
+		EncKeyPEM:  encKeyPEM,
+		EncCertPEM: certPEM(encCert),
+	}
+}
+
+func TestDualCert_Verify(t *testing.T) {
+	data := newTestDualCert(t, x509.KeyUsageKeyEncipherment|x509.KeyUsageDataEncipherment)
+	dual, err := ParseDualCert(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dual.Verify(); err != nil {
+		t.Fatalf("Expected valid dual certificate, got %v", err)
+	}
+
+	// 交换私钥
+	dual.SignKey, dual.EncKey = dual.EncKey, dual.SignKey
+	if err := dual.Verify(); !errors.Is(err, ErrSignKeyMismatch) {
+		t.Errorf("Expected ErrSignKeyMismatch, got %v", err)
+	}
+
+	// 加密证书密钥用途错误
+	data = newTestDualCert(t, x509.KeyUsageDigitalSignature)
+	dual, _ = ParseDualCert(data)
+	if err := dual.Verify(); !errors.Is(err, ErrEncKeyUsage) {
+		t.Errorf("Expected ErrEncKeyUsage, got %v", err)
+	}
+
+	data.EncCertPEM = ""
+	if _, err := ParseDualCert(data); !errors.Is(err, ErrNoEncCert) {
+		t.Errorf("Expected ErrNoEncCert, got %v", err)
+	}
+}
+
+func TestDualCert_Bundles(t *testing.T) {
+	dual, err := ParseDualCert(newTestDualCert(t, x509.KeyUsageKeyEncipherment))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bundle, err := dual.NginxBundle(&NginxOptions{Dir: "/opt/ssl"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bundle.Files) != 4 {
+		t.Errorf("Expected 4 files, got %d", len(bundle.Files))
+	}
+	for _, want := range []string{
+		"enable_ntls on;",
+		"ssl_sign_certificate     /opt/ssl/gm.example.com_sign.crt;",
+		"ssl_enc_certificate_key  /opt/ssl/gm.example.com_enc.key;",
+		"server_name gm.example.com;",
+	} {
+		if !strings.Contains(bundle.Config, want) {
+			t.Errorf("Expected config to contain %q:\n%s", want, bundle.Config)
+		}
+	}
+	if _, err := ParsePrivateKeyPEM(string(bundle.Files["gm.example.com_enc.key"])); err != nil {
+		t.Errorf("Expected parsable encryption key, got %v", err)
+	}
+
+	// 防止注入 nginx 指令及路径穿越
+	for _, opts := range []*NginxOptions{
+		{ServerName: "example.com; include /etc/passwd"},
+		{ServerName: "example.com {"},
+		{ServerName: "example.com\n"},
+		{Dir: "/opt/ssl;"},
+	} {
+		if _, err := dual.NginxBundle(opts); err == nil {
+			t.Errorf("Expected error for %+v", opts)
+		}
+	}
+	bundle, err = dual.NginxBundle(&NginxOptions{Name: "../Evil\\*.Example.com\x00"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := bundle.Files["_evil__.example.com__sign.crt"]; !ok {
+		t.Errorf("Expected sanitized file names, got %v", slices.Collect(maps.Keys(bundle.Files)))
+	}
+
+	for _, enc := range []PFXEncryption{PFXSM4, PFXCompat} {
+		pfx, err := dual.PFX(&PFXOptions{Password: "secret", Encryption: enc, Iterations: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var raw asn1.RawValue
+		if _, err := asn1.Unmarshal(pfx, &raw); err != nil {
+			t.Errorf("Invalid PFX for %s: %v", enc, err)
+		}
+	}
+}
@@ -0,0 +1,335 @@
+// Package gm 提供国密 SM2/SM3/SM4 算法及SM2双证书（签名+加密）的解析、校验与打包工具
+package gm
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+)
+
+var (
+	OIDSM2         = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 301}    // SM2椭圆曲线
+	OIDSM2WithSM3  = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 501}    // SM2-with-SM3签名算法
+	OIDSM3         = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 401}    // SM3
+	OIDHMACSM3     = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 401, 2} // HMAC-SM3
+	OIDSM4CBC      = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 104, 2} // SM4-CBC
+	oidECPublicKey = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+)
+
+// DefaultUID GM/T 0009 默认用户标识
+var DefaultUID = []byte("1234567812345678")
+
+var (
+	sm2Once  sync.Once
+	sm2Curve *elliptic.CurveParams
+)
+
+// P256SM2 返回 GM/T 0003 推荐的SM2椭圆曲线（a = p - 3）
+func P256SM2() elliptic.Curve {
+	sm2Once.Do(func() {
+		hex := func(s string) *big.Int {
+			n, _ := new(big.Int).SetString(s, 16)
+			return n
+		}
+		sm2Curve = &elliptic.CurveParams{
+			Name:    "SM2-P-256",
+			BitSize: 256,
+			P:       hex("FFFFFFFEFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF00000000FFFFFFFFFFFFFFFF"),
+			N:       hex("FFFFFFFEFFFFFFFFFFFFFFFFFFFFFFFF7203DF6B21C6052B53BBF40939D54123"),
+			B:       hex("28E9FA9E9D9F5E344D5A9E4BCF6509A7F39789F515AB8F92DDBCBD414D940E93"),
+			Gx:      hex("32C4AE2C1F1981195F9904466A39C9948FE30BBFF2660BE1715A4589334C74C7"),
+			Gy:      hex("BC3736A2F4F6779C59BDCEE36B692153D0A9877CC62A474002DF32E52139F0A0"),
+		}
+	})
+	return sm2Curve
+}
+
+// PublicKey SM2公钥
+type PublicKey struct {
+	X, Y *big.Int
+}
+
+// PrivateKey SM2私钥
+type PrivateKey struct {
+	PublicKey
+	D *big.Int
+}
+
+// Equal 判断公钥是否相同
+func (pub *PublicKey) Equal(x crypto.PublicKey) bool {
+	other, ok := x.(*PublicKey)
+	return ok && pub.X.Cmp(other.X) == 0 && pub.Y.Cmp(other.Y) == 0
+}
+
+// Bytes 返回非压缩格式公钥 04||X||Y
+func (pub *PublicKey) Bytes() []byte {
+	out := make([]byte, 65)
+	out[0] = 4
+	pub.X.FillBytes(out[1:33])
+	pub.Y.FillBytes(out[33:])
+	return out
+}
+
+// Public 实现 crypto.Signer 接口
+func (priv *PrivateKey) Public() crypto.PublicKey {
+	return &priv.PublicKey
+}
+
+// Sign 实现 crypto.Signer 接口，digest 为待签名的原始消息，使用默认用户标识
+func (priv *PrivateKey) Sign(random io.Reader, msg []byte, _ crypto.SignerOpts) ([]byte, error) {
+	return SignWithUID(random, priv, DefaultUID, msg)
+}
+
+// GenerateKey 生成SM2密钥对，公钥计算为常量时间
+func GenerateKey(random io.Reader) (*PrivateKey, error) {
+	n := new(big.Int).Sub(P256SM2().Params().N, big.NewInt(2))
+	d, err := rand.Int(random, n)
+	if err != nil {
+		return nil, err
+	}
+	d.Add(d, big.NewInt(1)) // d ∈ [1, n-2]
+	x, y := scalarBaseMult(d.FillBytes(make([]byte, 32)))
+	return &PrivateKey{PublicKey: PublicKey{X: x, Y: y}, D: d}, nil
+}
+
+// ZA 计算用户身份杂凑值 Z = SM3(ENTL || ID || a || b || xG || yG || xA || yA)
+func ZA(pub *PublicKey, uid []byte) []byte {
+	params := P256SM2().Params()
+	a := new(big.Int).Sub(params.P, big.NewInt(3))
+
+	h := NewSM3()
+	var entl [2]byte
+	binary.BigEndian.PutUint16(entl[:], uint16(len(uid)*8))
+	h.Write(entl[:])
+	h.Write(uid)
+	for _, v := range []*big.Int{a, params.B, params.Gx, params.Gy, pub.X, pub.Y} {
+		h.Write(v.FillBytes(make([]byte, 32)))
+	}
+	return h.Sum(nil)
+}
+
+// digest 计算 e = SM3(Z || M)
+func digest(pub *PublicKey, uid, msg []byte) *big.Int {
+	h := NewSM3()
+	h.Write(ZA(pub, uid))
+	h.Write(msg)
+	return new(big.Int).SetBytes(h.Sum(nil))
+}
+
+type sm2Signature struct {
+	R, S *big.Int
+}
+
+// SignWithUID 使用指定用户标识对消息签名，返回DER编码签名
+//
+// 涉及私钥与随机数 k 的运算均为常量时间。
+func SignWithUID(random io.Reader, priv *PrivateKey, uid, msg []byte) ([]byte, error) {
+	n := P256SM2().Params().N
+	if priv.D.Sign() <= 0 || priv.D.Cmp(new(big.Int).Sub(n, big.NewInt(1))) >= 0 {
+		return nil, errors.New("sm2: invalid private key")
+	}
+	e := digest(&priv.PublicKey, uid, msg)
+	d := priv.D.FillBytes(make([]byte, 32))
+	for {
+		k, err := rand.Int(random, n)
+		if err != nil {
+			return nil, err
+		}
+		if k.Sign() == 0 {
+			continue
+		}
+		kb := k.FillBytes(make([]byte, 32))
+		x1, _ := scalarBaseMult(kb)
+		r := new(big.Int).Add(e, x1)
+		r.Mod(r, n)
+		if r.Sign() == 0 {
+			continue
+		}
+		s, ok := signScalar(d, kb, r.FillBytes(make([]byte, 32)))
+		if !ok {
+			continue
+		}
+		return asn1.Marshal(sm2Signature{R: r, S: new(big.Int).SetBytes(s)})
+	}
+}
+
+// Verify 使用默认用户标识验证DER编码签名
+func Verify(pub *PublicKey, msg, sig []byte) bool {
+	return VerifyWithUID(pub, DefaultUID, msg, sig)
+}
+
+// VerifyWithUID 使用指定用户标识验证DER编码签名，输入均为公开数据，使用通用曲线运算
+func VerifyWithUID(pub *PublicKey, uid, msg, sig []byte) bool {
+	var s sm2Signature
+	if rest, err := asn1.Unmarshal(sig, &s); err != nil || len(rest) != 0 {
+		return false
+	}
+	curve := P256SM2()
+	n := curve.Params().N
+	one := big.NewInt(1)
+	if s.R.Cmp(one) < 0 || s.R.Cmp(n) >= 0 || s.S.Cmp(one) < 0 || s.S.Cmp(n) >= 0 {
+		return false
+	}
+
+	t := new(big.Int).Add(s.R, s.S)
+	t.Mod(t, n)
+	if t.Sign() == 0 {
+		return false
+	}
+	x1, y1 := curve.ScalarBaseMult(s.S.FillBytes(make([]byte, 32)))
+	x2, y2 := curve.ScalarMult(pub.X, pub.Y, t.FillBytes(make([]byte, 32)))
+	x, _ := curve.Add(x1, y1, x2, y2)
+
+	r := new(big.Int).Add(digest(pub, uid, msg), x)
+	r.Mod(r, n)
+	return r.Cmp(s.R) == 0
+}
+
+// ecPrivateKey RFC 5915 ECPrivateKey
+type ecPrivateKey struct {
+	Version       int
+	PrivateKey    []byte
+	NamedCurveOID asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
+	PublicKey     asn1.BitString        `asn1:"optional,explicit,tag:1"`
+}
+
+// pkcs8 RFC 5208 PrivateKeyInfo
+type pkcs8 struct {
+	Version    int
+	Algo       pkix.AlgorithmIdentifier
+	PrivateKey []byte
+}
+
+// ParsePrivateKeyPEM 解析PEM格式SM2私钥，支持PKCS#8及SEC1格式
+func ParsePrivateKeyPEM(keyPEM string) (*PrivateKey, error) {
+	rest := []byte(keyPEM)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return nil, errors.New("sm2: no private key found")
+		}
+		switch block.Type {
+		case "PRIVATE KEY":
+			return ParsePKCS8PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY", "SM2 PRIVATE KEY":
+			return parseECPrivateKey(block.Bytes)
+		}
+	}
+}
+
+// ParsePKCS8PrivateKey 解析PKCS#8格式SM2私钥
+func ParsePKCS8PrivateKey(der []byte) (*PrivateKey, error) {
+	var p pkcs8
+	if _, err := asn1.Unmarshal(der, &p); err != nil {
+		return nil, fmt.Errorf("sm2: parse pkcs8: %w", err)
+	}
+	if !isSM2Algorithm(p.Algo) {
+		return nil, errors.New("sm2: not an SM2 private key")
+	}
+	return parseECPrivateKey(p.PrivateKey)
+}
+
+// parseECPrivateKey 解析SEC1格式SM2私钥
+func parseECPrivateKey(der []byte) (*PrivateKey, error) {
+	var k ecPrivateKey
+	if _, err := asn1.Unmarshal(der, &k); err != nil {
+		return nil, fmt.Errorf("sm2: parse ec private key: %w", err)
+	}
+	if len(k.NamedCurveOID) > 0 && !k.NamedCurveOID.Equal(OIDSM2) {
+		return nil, fmt.Errorf("sm2: unexpected curve %v", k.NamedCurveOID)
+	}
+	d := new(big.Int).SetBytes(k.PrivateKey)
+	if d.Sign() <= 0 || d.Cmp(P256SM2().Params().N) >= 0 {
+		return nil, errors.New("sm2: invalid private key")
+	}
+	x, y := scalarBaseMult(d.FillBytes(make([]byte, 32)))
+	return &PrivateKey{PublicKey: PublicKey{X: x, Y: y}, D: d}, nil
+}
+
+// MarshalPKCS8PrivateKey 编码为PKCS#8格式
+func MarshalPKCS8PrivateKey(priv *PrivateKey) ([]byte, error) {
+	ec, err := asn1.Marshal(ecPrivateKey{
+		Version:    1,
+		PrivateKey: priv.D.FillBytes(make([]byte, 32)),
+		PublicKey:  asn1.BitString{Bytes: priv.PublicKey.Bytes(), BitLength: 65 * 8},
+	})
+	if err != nil {
+		return nil, err
+	}
+	params, err := asn1.Marshal(OIDSM2)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(pkcs8{
+		Algo:       pkix.AlgorithmIdentifier{Algorithm: oidECPublicKey, Parameters: asn1.RawValue{FullBytes: params}},
+		PrivateKey: ec,
+	})
+}
+
+// MarshalPublicKey 编码为 SubjectPublicKeyInfo
+func MarshalPublicKey(pub *PublicKey) ([]byte, error) {
+	params, err := asn1.Marshal(OIDSM2)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(publicKeyInfo{
+		Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidECPublicKey, Parameters: asn1.RawValue{FullBytes: params}},
+		PublicKey: asn1.BitString{Bytes: pub.Bytes(), BitLength: 65 * 8},
+	})
+}
+
+type publicKeyInfo struct {
+	Raw       asn1.RawContent
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// parsePublicKey 解析 SubjectPublicKeyInfo 中的SM2公钥
+func parsePublicKey(spki *publicKeyInfo) (*PublicKey, error) {
+	if !isSM2Algorithm(spki.Algorithm) {
+		return nil, errors.New("sm2: not an SM2 public key")
+	}
+	data := spki.PublicKey.RightAlign()
+	if len(data) != 65 || data[0] != 4 {
+		return nil, errors.New("sm2: unsupported public key encoding")
+	}
+	pub := &PublicKey{X: new(big.Int).SetBytes(data[1:33]), Y: new(big.Int).SetBytes(data[33:])}
+	if !P256SM2().IsOnCurve(pub.X, pub.Y) {
+		return nil, errors.New("sm2: public key is not on curve")
+	}
+	return pub, nil
+}
+
+// isSM2Algorithm 判断算法标识是否为SM2（id-ecPublicKey+SM2曲线 或 直接使用SM2 OID）
+func isSM2Algorithm(algo pkix.AlgorithmIdentifier) bool {
+	if algo.Algorithm.Equal(OIDSM2) {
+		return true
+	}
+	if !algo.Algorithm.Equal(oidECPublicKey) {
+		return false
+	}
+	var curve asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(algo.Parameters.FullBytes, &curve); err != nil {
+		return false
+	}
+	return curve.Equal(OIDSM2)
+}
+
+// IsSM2Certificate 判断DER证书的公钥是否为SM2
+func IsSM2Certificate(der []byte) bool {
+	var c certificate
+	if _, err := asn1.Unmarshal(der, &c); err != nil {
+		return false
+	}
+	return isSM2Algorithm(c.TBSCertificate.PublicKey.Algorithm)
+}
@@ -0,0 +1,352 @@
+package gm
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"math/big"
+	"math/bits"
+	"sync"
+)
+
+// 常量时间的SM2曲线运算，用于涉及私钥及签名随机数的计算。
+// elliptic.CurveParams 与 math/big 的运算耗时依赖输入，仅用于公开数据（如验签）。
+
+// limbs 256位整数，小端序 64 位分组
+type limbs [4]uint64
+
+// montField 模数固定的常量时间 Montgomery 运算，元素为 Montgomery 形式
+type montField struct {
+	m     limbs  // 模数，须为大于 2^255 的奇素数
+	m0inv uint64 // -m^-1 mod 2^64
+	rr    limbs  // R^2 mod m，R = 2^256
+	one   limbs  // R mod m，即 Montgomery 形式的 1
+	exp   limbs  // m - 2，用于费马小定理求逆
+}
+
+func newMontField(m *big.Int) *montField {
+	f := &montField{m: limbsFromBig(m)}
+	inv := uint64(1)
+	for range 6 {
+		inv *= 2 - f.m[0]*inv
+	}
+	f.m0inv = -inv
+	r := new(big.Int).Lsh(big.NewInt(1), 256)
+	f.one = limbsFromBig(new(big.Int).Mod(r, m))
+	f.rr = limbsFromBig(new(big.Int).Mod(new(big.Int).Mul(r, r), m))
+	f.exp = limbsFromBig(new(big.Int).Sub(m, big.NewInt(2)))
+	return f
+}
+
+// limbsFromBig 转换公开常量
+func limbsFromBig(n *big.Int) limbs {
+	var b [32]byte
+	n.FillBytes(b[:])
+	return limbsFromBytes(b[:])
+}
+
+// limbsFromBytes 解析 32 字节大端序整数
+func limbsFromBytes(b []byte) limbs {
+	var l limbs
+	for i := range l {
+		l[i] = binary.BigEndian.Uint64(b[24-8*i:])
+	}
+	return l
+}
+
+// bytes 返回 32 字节大端序编码
+func (l *limbs) bytes() []byte {
+	out := make([]byte, 32)
+	for i := range l {
+		binary.BigEndian.PutUint64(out[24-8*i:], l[i])
+	}
+	return out
+}
+
+// mulAdd 返回 a*b + c + d 的高低位
+func mulAdd(a, b, c, d uint64) (uint64, uint64) {
+	hi, lo := bits.Mul64(a, b)
+	var carry uint64
+	lo, carry = bits.Add64(lo, c, 0)
+	hi += carry
+	lo, carry = bits.Add64(lo, d, 0)
+	hi += carry
+	return hi, lo
+}
+
+// reduce 由 hi:x 减去至多一次模数，要求 hi:x < 2m
+func (f *montField) reduce(x *limbs, hi uint64) limbs {
+	var d limbs
+	var borrow uint64
+	for i := range d {
+		d[i], borrow = bits.Sub64(x[i], f.m[i], borrow)
+	}
+	_, borrow = bits.Sub64(hi, 0, borrow)
+	// borrow 为 1 表示 hi:x < m，保留原值
+	mask := -borrow
+	var z limbs
+	for i := range z {
+		z[i] = x[i]&mask | d[i]&^mask
+	}
+	return z
+}
+
+func (f *montField) add(x, y *limbs) limbs {
+	var z limbs
+	var carry uint64
+	for i := range z {
+		z[i], carry = bits.Add64(x[i], y[i], carry)
+	}
+	return f.reduce(&z, carry)
+}
+
+func (f *montField) sub(x, y *limbs) limbs {
+	var z limbs
+	var borrow uint64
+	for i := range z {
+		z[i], borrow = bits.Sub64(x[i], y[i], borrow)
+	}
+	mask := -borrow
+	var carry uint64
+	for i := range z {
+		z[i], carry = bits.Add64(z[i], f.m[i]&mask, carry)
+	}
+	return z
+}
+
+// mul Montgomery 乘法 x*y*R^-1 mod m（CIOS）
+func (f *montField) mul(x, y *limbs) limbs {
+	var t [6]uint64
+	for i := range 4 {
+		var c uint64
+		for j := range 4 {
+			c, t[j] = mulAdd(x[j], y[i], t[j], c)
+		}
+		var carry uint64
+		t[4], carry = bits.Add64(t[4], c, 0)
+		t[5] = carry
+
+		m := t[0] * f.m0inv
+		c, _ = mulAdd(m, f.m[0], t[0], 0)
+		for j := 1; j < 4; j++ {
+			c, t[j-1] = mulAdd(m, f.m[j], t[j], c)
+		}
+		t[3], carry = bits.Add64(t[4], c, 0)
+		t[4] = t[5] + carry
+	}
+	z := limbs{t[0], t[1], t[2], t[3]}
+	return f.reduce(&z, t[4])
+}
+
+// inv 求逆 x^(m-2)，指数为公开常量，执行路径与 x 无关
+func (f *montField) inv(x *limbs) limbs {
+	z := f.one
+	for i := 255; i >= 0; i-- {
+		z = f.mul(&z, &z)
+		if f.exp[i/64]>>(i%64)&1 == 1 {
+			z = f.mul(&z, x)
+		}
+	}
+	return z
+}
+
+// fromBytes 将 32 字节大端序整数转换为 Montgomery 形式并约简
+func (f *montField) fromBytes(b []byte) limbs {
+	l := limbsFromBytes(b)
+	return f.mul(&l, &f.rr)
+}
+
+// toBytes 转换为 32 字节大端序整数
+func (f *montField) toBytes(x *limbs) []byte {
+	l := f.mul(x, &limbs{1})
+	return l.bytes()
+}
+
+// isZero 常量时间判断是否为零，返回 1 或 0
+func isZero(x *limbs) int {
+	acc := x[0] | x[1] | x[2] | x[3]
+	return int(1 ^ (acc|-acc)>>63)
+}
+
+// sm2Point 射影坐标点 (X:Y:Z)，无穷远点为 (0:1:0)
+type sm2Point struct {
+	x, y, z limbs
+}
+
+var (
+	sm2FieldOnce      sync.Once
+	sm2P, sm2N        *montField
+	sm2B              limbs
+	sm2G, sm2Infinity sm2Point
+)
+
+func initSM2Field() {
+	sm2FieldOnce.Do(func() {
+		params := P256SM2().Params()
+		sm2P = newMontField(params.P)
+		sm2N = newMontField(params.N)
+		sm2B = sm2P.fromBytes(params.B.FillBytes(make([]byte, 32)))
+		sm2G = sm2Point{
+			x: sm2P.fromBytes(params.Gx.FillBytes(make([]byte, 32))),
+			y: sm2P.fromBytes(params.Gy.FillBytes(make([]byte, 32))),
+			z: sm2P.one,
+		}
+		sm2Infinity = sm2Point{y: sm2P.one}
+	})
+}
+
+// add 完备加法公式（a = -3），见 https://eprint.iacr.org/2015/1060 附录 A.2
+func (q *sm2Point) add(p1, p2 *sm2Point) {
+	f := sm2P
+	t0 := f.mul(&p1.x, &p2.x)
+	t1 := f.mul(&p1.y, &p2.y)
+	t2 := f.mul(&p1.z, &p2.z)
+	t3 := f.add(&p1.x, &p1.y)
+	t4 := f.add(&p2.x, &p2.y)
+	t3 = f.mul(&t3, &t4)
+	t4 = f.add(&t0, &t1)
+	t3 = f.sub(&t3, &t4)
+	t4 = f.add(&p1.y, &p1.z)
+	x3 := f.add(&p2.y, &p2.z)
+	t4 = f.mul(&t4, &x3)
+	x3 = f.add(&t1, &t2)
+	t4 = f.sub(&t4, &x3)
+	x3 = f.add(&p1.x, &p1.z)
+	y3 := f.add(&p2.x, &p2.z)
+	x3 = f.mul(&x3, &y3)
+	y3 = f.add(&t0, &t2)
+	y3 = f.sub(&x3, &y3)
+	z3 := f.mul(&sm2B, &t2)
+	x3 = f.sub(&y3, &z3)
+	z3 = f.add(&x3, &x3)
+	x3 = f.add(&x3, &z3)
+	z3 = f.sub(&t1, &x3)
+	x3 = f.add(&t1, &x3)
+	y3 = f.mul(&sm2B, &y3)
+	t1 = f.add(&t2, &t2)
+	t2 = f.add(&t1, &t2)
+	y3 = f.sub(&y3, &t2)
+	y3 = f.sub(&y3, &t0)
+	t1 = f.add(&y3, &y3)
+	y3 = f.add(&t1, &y3)
+	t1 = f.add(&t0, &t0)
+	t0 = f.add(&t1, &t0)
+	t0 = f.sub(&t0, &t2)
+	t1 = f.mul(&t4, &y3)
+	t2 = f.mul(&t0, &y3)
+	y3 = f.mul(&x3, &z3)
+	y3 = f.add(&y3, &t2)
+	x3 = f.mul(&t3, &x3)
+	x3 = f.sub(&x3, &t1)
+	z3 = f.mul(&t4, &z3)
+	t1 = f.mul(&t3, &t0)
+	z3 = f.add(&z3, &t1)
+	q.x, q.y, q.z = x3, y3, z3
+}
+
+// double 完备倍点公式（a = -3），见 https://eprint.iacr.org/2015/1060 附录 A.2
+func (q *sm2Point) double(p *sm2Point) {
+	f := sm2P
+	t0 := f.mul(&p.x, &p.x)
+	t1 := f.mul(&p.y, &p.y)
+	t2 := f.mul(&p.z, &p.z)
+	t3 := f.mul(&p.x, &p.y)
+	t3 = f.add(&t3, &t3)
+	z3 := f.mul(&p.x, &p.z)
+	z3 = f.add(&z3, &z3)
+	y3 := f.mul(&sm2B, &t2)
+	y3 = f.sub(&y3, &z3)
+	x3 := f.add(&y3, &y3)
+	y3 = f.add(&x3, &y3)
+	x3 = f.sub(&t1, &y3)
+	y3 = f.add(&t1, &y3)
+	y3 = f.mul(&x3, &y3)
+	x3 = f.mul(&x3, &t3)
+	t3 = f.add(&t2, &t2)
+	t2 = f.add(&t2, &t3)
+	z3 = f.mul(&sm2B, &z3)
+	z3 = f.sub(&z3, &t2)
+	z3 = f.sub(&z3, &t0)
+	t3 = f.add(&z3, &z3)
+	z3 = f.add(&z3, &t3)
+	t3 = f.add(&t0, &t0)
+	t0 = f.add(&t3, &t0)
+	t0 = f.sub(&t0, &t2)
+	t0 = f.mul(&t0, &z3)
+	y3 = f.add(&y3, &t0)
+	t0 = f.mul(&p.y, &p.z)
+	t0 = f.add(&t0, &t0)
+	z3 = f.mul(&t0, &z3)
+	x3 = f.sub(&x3, &z3)
+	z3 = f.mul(&t0, &t1)
+	z3 = f.add(&z3, &z3)
+	z3 = f.add(&z3, &z3)
+	q.x, q.y, q.z = x3, y3, z3
+}
+
+// selectPoint 常量时间地在 cond 为 1 时将 p 复制到 q
+func (q *sm2Point) selectPoint(p *sm2Point, cond int) {
+	mask := -uint64(cond)
+	for i := range 4 {
+		q.x[i] = q.x[i]&^mask | p.x[i]&mask
+		q.y[i] = q.y[i]&^mask | p.y[i]&mask
+		q.z[i] = q.z[i]&^mask | p.z[i]&mask
+	}
+}
+
+// affine 返回仿射坐标，无穷远点返回 (0, 0)
+func (q *sm2Point) affine() (x, y *big.Int) {
+	f := sm2P
+	zinv := f.inv(&q.z)
+	ax := f.mul(&q.x, &zinv)
+	ay := f.mul(&q.y, &zinv)
+	return new(big.Int).SetBytes(f.toBytes(&ax)), new(big.Int).SetBytes(f.toBytes(&ay))
+}
+
+// scalarBaseMult 常量时间计算 k*G，k 为 32 字节大端序标量
+//
+// 使用 4 位固定窗口，每个窗口执行相同次数的倍点与加法，查表遍历全部表项。
+func scalarBaseMult(k []byte) (x, y *big.Int) {
+	initSM2Field()
+	var table [16]sm2Point
+	table[0] = sm2Infinity
+	for i := 1; i < 16; i++ {
+		table[i].add(&table[i-1], &sm2G)
+	}
+
+	q := sm2Infinity
+	for _, b := range k {
+		for _, w := range []byte{b >> 4, b & 0x0f} {
+			for range 4 {
+				q.double(&q)
+			}
+			t := sm2Infinity
+			for i := range table {
+				t.selectPoint(&table[i], subtle.ConstantTimeByteEq(byte(i), w))
+			}
+			q.add(&q, &t)
+		}
+	}
+	return q.affine()
+}
+
+// signScalar 常量时间计算 s = (1 + d)^-1 * (k - r*d) mod n
+//
+// 返回的 ok 为 false 表示 r + k = n 或 s = 0，需更换随机数重新签名。
+func signScalar(d, k, r []byte) (s []byte, ok bool) {
+	initSM2Field()
+	f := sm2N
+	dm := f.fromBytes(d)
+	km := f.fromBytes(k)
+	rm := f.fromBytes(r)
+
+	rk := f.add(&rm, &km)
+	t := f.add(&dm, &f.one)
+	t = f.inv(&t)
+	rd := f.mul(&rm, &dm)
+	sm := f.sub(&km, &rd)
+	sm = f.mul(&sm, &t)
+	if isZero(&rk)|isZero(&sm) == 1 {
+		return nil, false
+	}
+	return f.toBytes(&sm), true
+}
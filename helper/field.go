@@ -24,8 +24,12 @@ const (
 	FieldTypeFloatArray   FieldType = "float_array"   // 浮点数数组
 	FieldTypeBooleanArray FieldType = "boolean_array" // 布尔值数组
 	FieldTypeObject       FieldType = "object"        // 对象
+	FieldTypeSecret       FieldType = "secret"        // 密钥，主机保存后以引用 {"secret_ref": "..."} 传入
 )
 
+// SecretRefKey 密钥引用对象的键
+const SecretRefKey = "secret_ref"
+
 // FieldFormat 字段格式
 type FieldFormat string
 
@@ -58,6 +62,7 @@ const (
 	FieldFormatIP       FieldFormat = "ip"       // IP地址：input[type="text"]
 	FieldFormatCIDR     FieldFormat = "cidr"     // CIDR地址范围：input[type="text"]
 	FieldFormatPort     FieldFormat = "port"     // 端口：input[type="number"]
+	FieldFormatDomain   FieldFormat = "domain"   // 域名，支持通配符及国际化域名：input[type="text"]
 )
 
 // FieldOption 字段选项
@@ -502,6 +507,16 @@ func validateFieldType(field Field, value any) (any, error) {
 			}
 			return arr, nil
 		}
+	case FieldTypeSecret:
+		// 用户输入的明文仅在保存前校验时出现，插件执行时应为引用
+		if val, ok := value.(string); ok {
+			return val, nil
+		}
+		if m, ok := value.(map[string]any); ok {
+			if ref, ok := m[SecretRefKey].(string); ok && ref != "" {
+				return m, nil
+			}
+		}
 	case FieldTypeObject:
 		v := reflect.ValueOf(value)
 		if v.Kind() == reflect.Map || v.Kind() == reflect.Struct {
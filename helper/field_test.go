@@ -490,6 +490,22 @@ func TestFieldConfig_Validate(t *testing.T) {
 			t.Errorf("Expected no error for missing optional field, got: %v", err)
 		}
 	})
+
+	t.Run("secret field", func(t *testing.T) {
+		secretSchema := []Field{{Type: FieldTypeSecret, Key: "token", Name: "Token", Required: true}}
+		valid := []any{"plain-input", map[string]any{SecretRefKey: "sec_123"}}
+		for _, v := range valid {
+			if err := (FieldConfig{"token": v}).Validate(secretSchema); err != nil {
+				t.Errorf("Expected no error for %v, got: %v", v, err)
+			}
+		}
+		invalid := []any{map[string]any{SecretRefKey: ""}, map[string]any{"value": "x"}, 123}
+		for _, v := range invalid {
+			if err := (FieldConfig{"token": v}).Validate(secretSchema); GetErrorCode(err) != ValidationErrorInvalidType {
+				t.Errorf("Expected type error for %v, got: %v", v, err)
+			}
+		}
+	})
 }
 
 // TestFieldConfig_ValidateWithOptions 测试带选项的验证
@@ -647,6 +663,27 @@ func TestFieldConfig_ValidateFormat(t *testing.T) {
 			t.Error("Expected error for invalid port")
 		}
 	})
+
+	t.Run("domain", func(t *testing.T) {
+		schema := []Field{
+			{
+				Type:   FieldTypeString,
+				Format: FieldFormatDomain,
+				Key:    "domain",
+				Name:   "Domain",
+			},
+		}
+		for _, domain := range []string{"example.com", "*.example.com", "例子.中国"} {
+			if err := (FieldConfig{"domain": domain}).Validate(schema); err != nil {
+				t.Errorf("Expected no error for %q, got: %v", domain, err)
+			}
+		}
+		for _, domain := range []string{"exa_mple.com", "a.*.example.com", "192.0.2.1"} {
+			if err := (FieldConfig{"domain": domain}).Validate(schema); err == nil {
+				t.Errorf("Expected error for %q", domain)
+			}
+		}
+	})
 }
 
 // TestValidationErrorHelpers 测试错误辅助函数
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"regexp"
+
+	"github.com/trustasia-com/certm-plugin-sdk/hostmatch"
 )
 
 // formatValidators 格式验证器映射表
@@ -20,6 +22,7 @@ var formatValidators = map[FieldFormat]func(Field, string) error{
 	FieldFormatTime:     validateTime,
 	FieldFormatTel:      validateTel,
 	FieldFormatPassword: validatePassword,
+	FieldFormatDomain:   validateDomain,
 }
 
 // emailRegex 邮箱正则表达式（简化版，符合 RFC 5322 的基本要求）
@@ -72,6 +75,14 @@ func validateCIDR(field Field, value string) error {
 	return nil
 }
 
+// validateDomain 验证域名格式，允许最左侧通配符及国际化域名
+func validateDomain(field Field, value string) error {
+	if !hostmatch.ValidDomain(value) {
+		return field.Error(ValidationErrorInvalidFormat, value)
+	}
+	return nil
+}
+
 // validateDate 验证日期格式 (YYYY-MM-DD)
 func validateDate(field Field, value string) error {
 	_, err := time.Parse("2006-01-02", value)
@@ -0,0 +1,39 @@
+package certm
+
+import (
+	"slices"
+
+	"github.com/trustasia-com/certm-plugin-sdk/certutil"
+)
+
+// Deployed 判断是否存在部署成功的记录
+func (h *CertAssetHistory) Deployed() bool {
+	return slices.ContainsFunc(h.Deployments, func(d *AssetDeployment) bool { return d.Deployed })
+}
+
+// SupersededAssets 返回已被替换但仍有部署记录的历史证书，用于清理旧证书
+func SupersededAssets(history []*CertAssetHistory) []*CertAssetHistory {
+	var superseded []*CertAssetHistory
+	for _, h := range history {
+		if !h.Current && h.Deployed() {
+			superseded = append(superseded, h)
+		}
+	}
+	return superseded
+}
+
+// MatchHistory 按SHA1在历史证书中查找，用于识别端点是否仍在使用旧证书
+//
+// 返回匹配的版本及其是否已被替换，未找到或 sha1 为空时返回 nil。
+func MatchHistory(history []*CertAssetHistory, sha1 string) (*CertAssetHistory, bool) {
+	sha1 = certutil.NormalizeFingerprint(sha1)
+	if sha1 == "" {
+		return nil, false
+	}
+	for _, h := range history {
+		if certutil.NormalizeFingerprint(h.SHA1) == sha1 {
+			return h, !h.Current
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,38 @@
+package certm
+
+import "testing"
+
+func TestCertAssetHistory(t *testing.T) {
+	history := []*CertAssetHistory{
+		{CertAssetInfo: CertAssetInfo{ID: 3, SHA1: "cc"}, Current: true,
+			Deployments: []*AssetDeployment{{DeployerID: 1, Deployed: true}}},
+		{CertAssetInfo: CertAssetInfo{ID: 2, SHA1: "bb"},
+			Deployments: []*AssetDeployment{{DeployerID: 1, Deployed: true}, {DeployerID: 2, Error: "timeout"}}},
+		{CertAssetInfo: CertAssetInfo{ID: 1, SHA1: "aa"},
+			Deployments: []*AssetDeployment{{DeployerID: 2, Error: "timeout"}}},
+		{CertAssetInfo: CertAssetInfo{ID: 4}}, // 主机未返回指纹
+	}
+
+	superseded := SupersededAssets(history)
+	if len(superseded) != 1 || superseded[0].ID != 2 {
+		t.Errorf("superseded = %+v", superseded)
+	}
+
+	tests := []struct {
+		sha1 string
+		id   int
+		old  bool
+	}{
+		{"CC", 3, false},
+		{"b:b", 2, true},
+		{"dd", 0, false},
+		{"", 0, false},
+		{" : ", 0, false},
+	}
+	for _, tt := range tests {
+		h, old := MatchHistory(history, tt.sha1)
+		if (h == nil && tt.id != 0) || (h != nil && h.ID != tt.id) || old != tt.old {
+			t.Errorf("MatchHistory(%s) = %+v, %v", tt.sha1, h, old)
+		}
+	}
+}
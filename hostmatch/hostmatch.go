@@ -0,0 +1,160 @@
+// Package hostmatch 提供 RFC 6125 主机名匹配，支持通配符、国际化域名及IP SAN
+package hostmatch
+
+import (
+	"crypto/x509"
+	"errors"
+	"net"
+	"strings"
+	"unicode/utf8"
+)
+
+const acePrefix = "xn--"
+
+// ErrInvalidDomain 域名格式无效
+var ErrInvalidDomain = errors.New("hostmatch: invalid domain name")
+
+// dotReplacer 将全角及表意句号视为标签分隔符（IDNA2003）
+var dotReplacer = strings.NewReplacer("。", ".", "．", ".", "｡", ".")
+
+// ToASCII 将域名转换为小写ASCII形式，非ASCII标签编码为 xn-- 开头的A-label
+//
+// 仅做大小写折叠及Punycode编码，不做Unicode规范化。
+func ToASCII(domain string) (string, error) {
+	domain = strings.TrimSuffix(dotReplacer.Replace(strings.TrimSpace(domain)), ".")
+	if domain == "" {
+		return "", ErrInvalidDomain
+	}
+	labels := strings.Split(strings.ToLower(domain), ".")
+	for i, label := range labels {
+		if label == "" {
+			return "", ErrInvalidDomain
+		}
+		if isASCII(label) {
+			continue
+		}
+		labels[i] = acePrefix + punyEncode(label)
+	}
+	ascii := strings.Join(labels, ".")
+	if len(ascii) > 253 {
+		return "", ErrInvalidDomain
+	}
+	return ascii, nil
+}
+
+// ToUnicode 将域名中的A-label解码为Unicode
+func ToUnicode(domain string) (string, error) {
+	labels := strings.Split(strings.TrimSuffix(strings.ToLower(domain), "."), ".")
+	for i, label := range labels {
+		if !strings.HasPrefix(label, acePrefix) {
+			continue
+		}
+		decoded, err := punyDecode(label[len(acePrefix):])
+		if err != nil {
+			return "", err
+		}
+		labels[i] = decoded
+	}
+	return strings.Join(labels, "."), nil
+}
+
+// Normalize 规范化主机名：IP返回标准形式，域名返回小写A-label形式并去除末尾的点
+func Normalize(host string) (string, error) {
+	host = strings.TrimSpace(host)
+	if ip := parseIP(host); ip != nil {
+		return ip.String(), nil
+	}
+	return ToASCII(host)
+}
+
+// ValidDomain 判断是否为合法域名，允许国际化域名及最左侧的 * 通配符标签
+func ValidDomain(name string) bool {
+	name = strings.TrimSpace(name)
+	if parseIP(name) != nil {
+		return false
+	}
+	ascii, err := ToASCII(strings.TrimPrefix(name, "*."))
+	if err != nil {
+		return false
+	}
+	for _, label := range strings.Split(ascii, ".") {
+		if len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for i := 0; i < len(label); i++ {
+			c := label[i]
+			if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Match 判断规范化后的SAN是否匹配规范化后的主机名
+//
+// 通配符仅允许作为最左侧的完整标签，且只匹配一个标签，如 *.example.com 匹配
+// www.example.com，不匹配 example.com 与 a.b.example.com；*.com 等单标签后缀不匹配。
+func Match(pattern, host string) bool {
+	if pattern == host {
+		return true
+	}
+	suffix, ok := strings.CutPrefix(pattern, "*")
+	if !ok || !strings.HasPrefix(suffix, ".") || strings.Count(suffix, ".") < 2 {
+		return false
+	}
+	i := strings.IndexByte(host, '.')
+	return i > 0 && host[i:] == suffix
+}
+
+// Covers 判断证书是否对主机名或IP有效，仅使用SAN，不回退到通用名称
+func Covers(cert *x509.Certificate, host string) bool {
+	if ip := parseIP(strings.TrimSpace(host)); ip != nil {
+		for _, candidate := range cert.IPAddresses {
+			if candidate.Equal(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	h, err := Normalize(host)
+	if err != nil {
+		return false
+	}
+	for _, name := range cert.DNSNames {
+		pattern, err := Normalize(name)
+		if err != nil {
+			continue
+		}
+		if Match(pattern, h) {
+			return true
+		}
+	}
+	return false
+}
+
+// UncoveredHosts 返回证书未覆盖的主机名，保持原有顺序
+func UncoveredHosts(cert *x509.Certificate, hosts []string) []string {
+	var uncovered []string
+	for _, host := range hosts {
+		if !Covers(cert, host) {
+			uncovered = append(uncovered, host)
+		}
+	}
+	return uncovered
+}
+
+// parseIP 解析IP，支持方括号包裹的IPv6
+func parseIP(host string) net.IP {
+	return net.ParseIP(strings.TrimSuffix(strings.TrimPrefix(host, "["), "]"))
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
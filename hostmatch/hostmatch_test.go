@@ -0,0 +1,109 @@
+package hostmatch
+
+import (
+	"crypto/x509"
+	"net"
+	"slices"
+	"testing"
+)
+
+func TestPunycode(t *testing.T) {
+	tests := []struct {
+		unicode, ascii string
+	}{
+		{"bücher", "bcher-kva"},
+		{"münchen", "mnchen-3ya"},
+		{"中国", "fiqs8s"},
+		{"ليهمابتكلموشعربي؟", "egbpdaj6bu4bxfgehfvwxn"},
+		{"他们为什么不说中文", "ihqwcrb4cv8a8dqg056pqjye"},
+	}
+	for _, tt := range tests {
+		if got := punyEncode(tt.unicode); got != tt.ascii {
+			t.Errorf("punyEncode(%q) = %q, want %q", tt.unicode, got, tt.ascii)
+		}
+		got, err := punyDecode(tt.ascii)
+		if err != nil || got != tt.unicode {
+			t.Errorf("punyDecode(%q) = %q, %v, want %q", tt.ascii, got, err, tt.unicode)
+		}
+	}
+	if _, err := punyDecode("abc!"); err == nil {
+		t.Error("expected error for invalid digit")
+	}
+}
+
+func TestToASCII(t *testing.T) {
+	tests := []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{"Example.COM.", "example.com", false},
+		{"Bücher.example", "xn--bcher-kva.example", false},
+		{"例子。中国", "xn--fsqu00a.xn--fiqs8s", false},
+		{"a..com", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ToASCII(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ToASCII(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+
+	if got, err := ToUnicode("xn--bcher-kva.example"); err != nil || got != "bücher.example" {
+		t.Errorf("ToUnicode = %q, %v", got, err)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, host string
+		want          bool
+	}{
+		{"example.com", "example.com", true},
+		{"*.example.com", "www.example.com", true},
+		{"*.example.com", "example.com", false},
+		{"*.example.com", "a.b.example.com", false},
+		{"*.com", "example.com", false},
+		{"w*.example.com", "www.example.com", false},
+		{"www.*.com", "www.example.com", false},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.host); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestCovers(t *testing.T) {
+	cert := &x509.Certificate{
+		DNSNames:    []string{"example.com", "*.Example.com", "xn--bcher-kva.example"},
+		IPAddresses: []net.IP{net.ParseIP("192.0.2.1"), net.ParseIP("2001:db8::1")},
+	}
+	covered := []string{"EXAMPLE.com", "www.example.com.", "bücher.example", "192.0.2.1", "[2001:db8:0::1]"}
+	for _, host := range covered {
+		if !Covers(cert, host) {
+			t.Errorf("Covers(%q) = false", host)
+		}
+	}
+
+	hosts := []string{"www.example.com", "a.b.example.com", "other.org", "192.0.2.2", "buecher.example"}
+	want := []string{"a.b.example.com", "other.org", "192.0.2.2", "buecher.example"}
+	if got := UncoveredHosts(cert, hosts); !slices.Equal(got, want) {
+		t.Errorf("UncoveredHosts = %v, want %v", got, want)
+	}
+}
+
+func TestValidDomain(t *testing.T) {
+	valid := []string{"example.com", "*.example.com", "bücher.example", "xn--bcher-kva.example", "localhost"}
+	for _, name := range valid {
+		if !ValidDomain(name) {
+			t.Errorf("ValidDomain(%q) = false", name)
+		}
+	}
+	invalid := []string{"", "192.0.2.1", "-a.example.com", "a_b.example.com", "www.*.example.com", "*", "exa mple.com"}
+	for _, name := range invalid {
+		if ValidDomain(name) {
+			t.Errorf("ValidDomain(%q) = true", name)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package hostmatch
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// RFC 3492 Punycode 参数
+const (
+	punyBase        = 36
+	punyTMin        = 1
+	punyTMax        = 26
+	punySkew        = 38
+	punyDamp        = 700
+	punyInitialBias = 72
+	punyInitialN    = 128
+)
+
+var errPunycode = errors.New("hostmatch: invalid punycode")
+
+// adapt 偏置调整
+func adapt(delta, numPoints int, first bool) int {
+	if first {
+		delta /= punyDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / numPoints
+	k := 0
+	for delta > ((punyBase-punyTMin)*punyTMax)/2 {
+		delta /= punyBase - punyTMin
+		k += punyBase
+	}
+	return k + (punyBase-punyTMin+1)*delta/(delta+punySkew)
+}
+
+// threshold 计算第 k 位的阈值
+func threshold(k, bias int) int {
+	return min(max(k-bias, punyTMin), punyTMax)
+}
+
+func encodeDigit(d int) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+	return byte('0' + d - 26)
+}
+
+func decodeDigit(c byte) (int, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c-'0') + 26, true
+	case c >= 'a' && c <= 'z':
+		return int(c - 'a'), true
+	case c >= 'A' && c <= 'Z':
+		return int(c - 'A'), true
+	default:
+		return 0, false
+	}
+}
+
+// punyEncode 将标签编码为Punycode，不含 xn-- 前缀
+func punyEncode(label string) string {
+	runes := []rune(label)
+	var out []byte
+	for _, r := range runes {
+		if r < utf8.RuneSelf {
+			out = append(out, byte(r))
+		}
+	}
+	basic := len(out)
+	handled := basic
+	if basic > 0 {
+		out = append(out, '-')
+	}
+
+	n, delta, bias := punyInitialN, 0, punyInitialBias
+	for handled < len(runes) {
+		m := int(unicode.MaxRune) + 1
+		for _, r := range runes {
+			if int(r) >= n && int(r) < m {
+				m = int(r)
+			}
+		}
+		delta += (m - n) * (handled + 1)
+		n = m
+		for _, r := range runes {
+			if int(r) < n {
+				delta++
+			}
+			if int(r) != n {
+				continue
+			}
+			q := delta
+			for k := punyBase; ; k += punyBase {
+				t := threshold(k, bias)
+				if q < t {
+					break
+				}
+				out = append(out, encodeDigit(t+(q-t)%(punyBase-t)))
+				q = (q - t) / (punyBase - t)
+			}
+			out = append(out, encodeDigit(q))
+			bias = adapt(delta, handled+1, handled == basic)
+			delta = 0
+			handled++
+		}
+		delta++
+		n++
+	}
+	return string(out)
+}
+
+// punyDecode 解码不含 xn-- 前缀的Punycode标签
+func punyDecode(s string) (string, error) {
+	var output []rune
+	if pos := strings.LastIndexByte(s, '-'); pos >= 0 {
+		for i := 0; i < pos; i++ {
+			if s[i] >= utf8.RuneSelf {
+				return "", errPunycode
+			}
+			output = append(output, rune(s[i]))
+		}
+		s = s[pos+1:]
+	}
+
+	n, i, bias := punyInitialN, 0, punyInitialBias
+	for len(s) > 0 {
+		oldi, w := i, 1
+		for k := punyBase; ; k += punyBase {
+			if len(s) == 0 {
+				return "", errPunycode
+			}
+			digit, ok := decodeDigit(s[0])
+			s = s[1:]
+			if !ok {
+				return "", errPunycode
+			}
+			i += digit * w
+			if i > unicode.MaxRune*punyBase {
+				return "", errPunycode
+			}
+			t := threshold(k, bias)
+			if digit < t {
+				break
+			}
+			w *= punyBase - t
+		}
+		bias = adapt(i-oldi, len(output)+1, oldi == 0)
+		n += i / (len(output) + 1)
+		if n > unicode.MaxRune {
+			return "", errPunycode
+		}
+		i %= len(output) + 1
+		output = append(output[:i], append([]rune{rune(n)}, output[i:]...)...)
+		i++
+	}
+	return string(output), nil
+}
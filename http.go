@@ -0,0 +1,52 @@
+package certm
+
+import (
+	"context"
+	"fmt"
+)
+
+const httpClientCtxKey contextKey = "httpClient"
+
+// HTTPRequest 主机HTTP请求
+type HTTPRequest struct {
+	Method  string            `json:"method"`            // 请求方法: GET/POST
+	URL     string            `json:"url"`               // 请求地址
+	Header  map[string]string `json:"header,omitempty"`  // 请求头
+	Body    []byte            `json:"body,omitempty"`    // 请求体
+	Timeout int               `json:"timeout,omitempty"` // 超时时间(秒)，0使用主机默认值
+}
+
+// HTTPResponse 主机HTTP响应
+type HTTPResponse struct {
+	StatusCode int               `json:"status_code"`      // 状态码
+	Header     map[string]string `json:"header,omitempty"` // 响应头
+	Body       []byte            `json:"body,omitempty"`   // 响应体
+}
+
+// HTTPClient 主机HTTP请求能力，WASM中由主机函数 http_request 实现
+type HTTPClient interface {
+	HTTPDo(req *HTTPRequest) (*HTTPResponse, error)
+}
+
+// GetHTTPClient 获取主机HTTP请求能力
+// nolint:errcheck
+func GetHTTPClient(ctx context.Context) HTTPClient {
+	return ctx.Value(httpClientCtxKey).(HTTPClient)
+}
+
+// SetHTTPClient 设置主机HTTP请求能力
+func SetHTTPClient(ctx context.Context, client HTTPClient) context.Context {
+	return context.WithValue(ctx, httpClientCtxKey, client)
+}
+
+// HTTPGet 通过主机发起GET请求，非2xx状态码返回错误
+func HTTPGet(client HTTPClient, url string) ([]byte, error) {
+	resp, err := client.HTTPDo(&HTTPRequest{Method: "GET", URL: url})
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("http get %s: unexpected status %d", url, resp.StatusCode)
+	}
+	return resp.Body, nil
+}
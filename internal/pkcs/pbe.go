@@ -0,0 +1,252 @@
+// Package pkcs 实现 PKCS#5/PKCS#8/PKCS#12 编码所需的加密与ASN.1结构
+package pkcs
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"hash"
+	"math/big"
+	"unicode/utf16"
+)
+
+var (
+	OIDPBES2  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13} // PBES2
+	OIDPBKDF2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12} // PBKDF2
+
+	OIDHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7} // hmacWithSHA1
+	OIDHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9} // hmacWithSHA256
+
+	OIDAES128CBC = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}  // aes128-CBC
+	OIDAES256CBC = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42} // aes256-CBC
+
+	OIDPBEWithSHAAnd3KeyTripleDESCBC = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 3} // pbeWithSHAAnd3-KeyTripleDES-CBC
+
+	OIDSHA1   = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}             // SHA1
+	OIDSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1} // SHA256
+)
+
+// Encryptor 基于口令的加密算法
+type Encryptor interface {
+	// Encrypt 加密数据，返回算法标识和密文
+	Encrypt(password string, plaintext []byte) (pkix.AlgorithmIdentifier, []byte, error)
+}
+
+// PBES2 PKCS#5 v2.0 加密方案（PBKDF2 + 分组密码CBC模式）
+type PBES2 struct {
+	PRF        func() hash.Hash                       // PBKDF2 伪随机函数使用的哈希
+	PRFOID     asn1.ObjectIdentifier                  // 伪随机函数OID
+	Cipher     func(key []byte) (cipher.Block, error) // 分组密码
+	CipherOID  asn1.ObjectIdentifier                  // 加密算法OID
+	KeyLen     int                                    // 密钥长度
+	Iterations int                                    // 迭代次数
+	SaltLen    int                                    // 盐长度
+}
+
+type pbkdf2Params struct {
+	Salt       []byte
+	Iterations int
+	KeyLength  int                      `asn1:"optional"`
+	PRF        pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+// Encrypt 实现 Encryptor 接口，口令使用UTF-8编码
+func (p *PBES2) Encrypt(password string, plaintext []byte) (pkix.AlgorithmIdentifier, []byte, error) {
+	var algo pkix.AlgorithmIdentifier
+
+	salt := make([]byte, p.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return algo, nil, err
+	}
+	key, err := pbkdf2.Key(p.PRF, password, salt, p.Iterations, p.KeyLen)
+	if err != nil {
+		return algo, nil, err
+	}
+	block, err := p.Cipher(key)
+	if err != nil {
+		return algo, nil, err
+	}
+	iv := make([]byte, block.BlockSize())
+	if _, err := rand.Read(iv); err != nil {
+		return algo, nil, err
+	}
+	ciphertext := EncryptCBC(block, iv, plaintext)
+
+	kdfParams, err := asn1.Marshal(pbkdf2Params{
+		Salt:       salt,
+		Iterations: p.Iterations,
+		PRF:        pkix.AlgorithmIdentifier{Algorithm: p.PRFOID, Parameters: asn1.NullRawValue},
+	})
+	if err != nil {
+		return algo, nil, err
+	}
+	ivParams, err := asn1.Marshal(iv)
+	if err != nil {
+		return algo, nil, err
+	}
+	params, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: pkix.AlgorithmIdentifier{Algorithm: OIDPBKDF2, Parameters: asn1.RawValue{FullBytes: kdfParams}},
+		EncryptionScheme:  pkix.AlgorithmIdentifier{Algorithm: p.CipherOID, Parameters: asn1.RawValue{FullBytes: ivParams}},
+	})
+	if err != nil {
+		return algo, nil, err
+	}
+
+	algo = pkix.AlgorithmIdentifier{Algorithm: OIDPBES2, Parameters: asn1.RawValue{FullBytes: params}}
+	return algo, ciphertext, nil
+}
+
+// PKCS12PBE PKCS#12 附录B基于口令的加密方案（如 pbeWithSHAAnd3-KeyTripleDES-CBC）
+type PKCS12PBE struct {
+	Hash       func() hash.Hash                       // 密钥派生哈希
+	OID        asn1.ObjectIdentifier                  // 算法OID
+	Cipher     func(key []byte) (cipher.Block, error) // 分组密码
+	KeyLen     int                                    // 密钥长度
+	Iterations int                                    // 迭代次数
+	SaltLen    int                                    // 盐长度
+}
+
+type pbeParams struct {
+	Salt       []byte
+	Iterations int
+}
+
+// Encrypt 实现 Encryptor 接口，口令使用BMPString编码
+func (p *PKCS12PBE) Encrypt(password string, plaintext []byte) (pkix.AlgorithmIdentifier, []byte, error) {
+	var algo pkix.AlgorithmIdentifier
+
+	salt := make([]byte, p.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return algo, nil, err
+	}
+	bmpPassword := BMPString(password)
+	key := PKCS12KDF(p.Hash, bmpPassword, salt, p.Iterations, 1, p.KeyLen)
+	block, err := p.Cipher(key)
+	if err != nil {
+		return algo, nil, err
+	}
+	iv := PKCS12KDF(p.Hash, bmpPassword, salt, p.Iterations, 2, block.BlockSize())
+	ciphertext := EncryptCBC(block, iv, plaintext)
+
+	params, err := asn1.Marshal(pbeParams{Salt: salt, Iterations: p.Iterations})
+	if err != nil {
+		return algo, nil, err
+	}
+	algo = pkix.AlgorithmIdentifier{Algorithm: p.OID, Parameters: asn1.RawValue{FullBytes: params}}
+	return algo, ciphertext, nil
+}
+
+// BMPString 将口令编码为以双零结尾的UTF-16BE，空口令返回空值
+func BMPString(s string) []byte {
+	if s == "" {
+		return nil
+	}
+	units := utf16.Encode([]rune(s))
+	out := make([]byte, 0, 2*len(units)+2)
+	for _, u := range units {
+		out = append(out, byte(u>>8), byte(u))
+	}
+	return append(out, 0, 0)
+}
+
+// PKCS12KDF RFC 7292 附录B.2 密钥派生函数
+// id: 1 加密密钥，2 初始向量，3 MAC密钥
+func PKCS12KDF(h func() hash.Hash, password, salt []byte, iterations int, id byte, size int) []byte {
+	hh := h()
+	u := hh.Size()
+	v := hh.BlockSize()
+
+	d := bytes.Repeat([]byte{id}, v)
+	fill := func(src []byte) []byte {
+		if len(src) == 0 {
+			return nil
+		}
+		n := v * ((len(src) + v - 1) / v)
+		out := make([]byte, n)
+		for i := range out {
+			out[i] = src[i%len(src)]
+		}
+		return out
+	}
+	I := append(fill(salt), fill(password)...)
+
+	one := big.NewInt(1)
+	out := make([]byte, 0, size+u)
+	for len(out) < size {
+		hh.Reset()
+		hh.Write(d)
+		hh.Write(I)
+		a := hh.Sum(nil)
+		for j := 1; j < iterations; j++ {
+			hh.Reset()
+			hh.Write(a)
+			a = hh.Sum(a[:0])
+		}
+		out = append(out, a...)
+		if len(out) >= size {
+			break
+		}
+
+		// I_j = (I_j + B + 1) mod 2^(v*8)
+		b := new(big.Int).SetBytes(fill(a)[:v])
+		b.Add(b, one)
+		for j := 0; j < len(I); j += v {
+			ij := new(big.Int).SetBytes(I[j : j+v])
+			ij.Add(ij, b)
+			buf := ij.Bytes()
+			if len(buf) > v {
+				buf = buf[len(buf)-v:]
+			}
+			chunk := I[j : j+v]
+			clear(chunk)
+			copy(chunk[v-len(buf):], buf)
+		}
+	}
+	return out[:size]
+}
+
+// MAC 计算PKCS#12 MAC，密钥由 PKCS12KDF 派生
+func MAC(h func() hash.Hash, password string, salt []byte, iterations int, data []byte) []byte {
+	key := PKCS12KDF(h, BMPString(password), salt, iterations, 3, h().Size())
+	mac := hmac.New(h, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// EncryptCBC 使用PKCS#7填充并以CBC模式加密
+func EncryptCBC(block cipher.Block, iv, plaintext []byte) []byte {
+	bs := block.BlockSize()
+	padLen := bs - len(plaintext)%bs
+	padded := make([]byte, len(plaintext)+padLen)
+	copy(padded, plaintext)
+	for i := len(plaintext); i < len(padded); i++ {
+		padded[i] = byte(padLen)
+	}
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(padded, padded)
+	return padded
+}
+
+// DecryptCBC 以CBC模式解密并去除PKCS#7填充
+func DecryptCBC(block cipher.Block, iv, ciphertext []byte) ([]byte, error) {
+	bs := block.BlockSize()
+	if len(ciphertext) == 0 || len(ciphertext)%bs != 0 {
+		return nil, errors.New("invalid ciphertext length")
+	}
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+	padLen := int(plaintext[len(plaintext)-1])
+	if padLen == 0 || padLen > bs {
+		return nil, errors.New("invalid padding")
+	}
+	return plaintext[:len(plaintext)-padLen], nil
+}
@@ -0,0 +1,65 @@
+package pkcs
+
+import (
+	"crypto/aes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+// TestPKCS12KDF 与 openssl kdf PKCS12KDF 输出对比
+func TestPKCS12KDF(t *testing.T) {
+	tests := []struct {
+		name     string
+		sha256   bool
+		salt     string
+		iter     int
+		id       byte
+		size     int
+		expected string
+	}{
+		{"sha1 key", false, "ffffffffffffffff", 2048, 1, 24, "f5e9a8257057310b83cf9ba5e4a73db6fc3a97a280c01c63"},
+		{"sha256 multi block", true, "0102030405060708", 3, 3, 40, "481d2a734c855e737903a0e07052bbe8968ec8b2d0bf770579a67cbe9ef40b7f467264b2ad809df2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := sha1.New
+			if tt.sha256 {
+				h = sha256.New
+			}
+			salt, _ := hex.DecodeString(tt.salt)
+			got := hex.EncodeToString(PKCS12KDF(h, []byte("sesame"), salt, tt.iter, tt.id, tt.size))
+			if got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestBMPString(t *testing.T) {
+	if got := hex.EncodeToString(BMPString("ab")); got != "006100620000" {
+		t.Errorf("Unexpected BMPString %s", got)
+	}
+	if BMPString("") != nil {
+		t.Error("Expected empty password to encode as nil")
+	}
+}
+
+func TestCBCRoundTrip(t *testing.T) {
+	block, _ := aes.NewCipher(make([]byte, 16))
+	iv := make([]byte, 16)
+	for _, size := range []int{0, 15, 16, 33} {
+		plaintext := make([]byte, size)
+		for i := range plaintext {
+			plaintext[i] = byte(i)
+		}
+		decrypted, err := DecryptCBC(block, iv, EncryptCBC(block, iv, plaintext))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(decrypted) != hex.EncodeToString(plaintext) {
+			t.Errorf("Round trip mismatch for size %d", size)
+		}
+	}
+}
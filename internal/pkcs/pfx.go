@@ -0,0 +1,246 @@
+package pkcs
+
+import (
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"hash"
+	"unicode/utf16"
+)
+
+var (
+	oidData                = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidEncryptedData       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 6}
+	oidCertBag             = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+	oidPKCS8ShroudedKeyBag = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 2}
+	oidX509Certificate     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 1}
+	oidFriendlyName        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 20}
+	oidLocalKeyID          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 21}
+)
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"tag:0,explicit,optional"`
+}
+
+type encryptedData struct {
+	Version              int
+	EncryptedContentInfo encryptedContentInfo
+}
+
+type encryptedContentInfo struct {
+	ContentType                asn1.ObjectIdentifier
+	ContentEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedContent           []byte `asn1:"tag:0,optional"`
+}
+
+type safeBag struct {
+	ID         asn1.ObjectIdentifier
+	Value      asn1.RawValue     `asn1:"tag:0,explicit"`
+	Attributes []pkcs12Attribute `asn1:"set,optional"`
+}
+
+type pkcs12Attribute struct {
+	ID    asn1.ObjectIdentifier
+	Value asn1.RawValue `asn1:"set"`
+}
+
+type certBag struct {
+	ID   asn1.ObjectIdentifier
+	Data []byte `asn1:"tag:0,explicit"`
+}
+
+type digestInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	Digest    []byte
+}
+
+type macData struct {
+	Mac        digestInfo
+	MacSalt    []byte
+	Iterations int `asn1:"optional,default:1"`
+}
+
+type pfxPdu struct {
+	Version  int
+	AuthSafe contentInfo
+	MacData  macData `asn1:"optional"`
+}
+
+// EncryptedPrivateKeyInfo PKCS#8 加密私钥结构
+type EncryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+// EncryptPKCS8 加密PKCS#8私钥，返回 EncryptedPrivateKeyInfo DER
+func EncryptPKCS8(pkcs8 []byte, password string, enc Encryptor) ([]byte, error) {
+	algo, ciphertext, err := enc.Encrypt(password, pkcs8)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(EncryptedPrivateKeyInfo{Algorithm: algo, EncryptedData: ciphertext})
+}
+
+// PFXOptions PKCS#12 编码选项
+type PFXOptions struct {
+	Password      string    // 口令
+	KeyEncryptor  Encryptor // 私钥加密方案
+	CertEncryptor Encryptor // 证书加密方案
+
+	MacHash       func() hash.Hash      // MAC哈希
+	MacOID        asn1.ObjectIdentifier // MAC哈希OID
+	MacIterations int                   // MAC迭代次数
+}
+
+// PFXEntry PKCS#12 私钥条目
+type PFXEntry struct {
+	Key          []byte   // PKCS#8私钥DER
+	Certs        [][]byte // 证书DER，第一个为与私钥对应的证书
+	FriendlyName string   // 友好名称，可为空
+}
+
+// EncodePFX 编码包含单个私钥条目的PKCS#12文件
+func EncodePFX(key []byte, certs [][]byte, friendlyName string, opts *PFXOptions) ([]byte, error) {
+	return EncodePFXEntries([]PFXEntry{{Key: key, Certs: certs, FriendlyName: friendlyName}}, opts)
+}
+
+// EncodePFXEntries 编码PKCS#12文件，可包含多个私钥条目（如国密签名+加密双证书）
+func EncodePFXEntries(entries []PFXEntry, opts *PFXOptions) ([]byte, error) {
+	if len(entries) == 0 {
+		return nil, errors.New("pkcs12: no entries")
+	}
+
+	var certBags, keyBags []safeBag
+	for _, entry := range entries {
+		if len(entry.Key) == 0 || len(entry.Certs) == 0 {
+			return nil, errors.New("pkcs12: private key and certificate are required")
+		}
+
+		localKeyID := make([]byte, 20)
+		if _, err := rand.Read(localKeyID); err != nil {
+			return nil, err
+		}
+		attrs, err := bagAttributes(localKeyID, entry.FriendlyName)
+		if err != nil {
+			return nil, err
+		}
+
+		// 证书包
+		for i, der := range entry.Certs {
+			bag, err := asn1.Marshal(certBag{ID: oidX509Certificate, Data: der})
+			if err != nil {
+				return nil, err
+			}
+			sb := safeBag{ID: oidCertBag, Value: asn1.RawValue{FullBytes: explicit0(bag)}}
+			if i == 0 {
+				sb.Attributes = attrs
+			}
+			certBags = append(certBags, sb)
+		}
+
+		// 私钥包
+		shrouded, err := EncryptPKCS8(entry.Key, opts.Password, opts.KeyEncryptor)
+		if err != nil {
+			return nil, err
+		}
+		keyBags = append(keyBags, safeBag{
+			ID:         oidPKCS8ShroudedKeyBag,
+			Value:      asn1.RawValue{FullBytes: explicit0(shrouded)},
+			Attributes: attrs,
+		})
+	}
+
+	// 1. 证书包整体加密
+	certContents, err := asn1.Marshal(certBags)
+	if err != nil {
+		return nil, err
+	}
+	algo, ciphertext, err := opts.CertEncryptor.Encrypt(opts.Password, certContents)
+	if err != nil {
+		return nil, err
+	}
+	encData, err := asn1.Marshal(encryptedData{
+		EncryptedContentInfo: encryptedContentInfo{
+			ContentType:                oidData,
+			ContentEncryptionAlgorithm: algo,
+			EncryptedContent:           ciphertext,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 私钥包
+	keyContents, err := asn1.Marshal(keyBags)
+	if err != nil {
+		return nil, err
+	}
+	keyOctets, err := asn1.Marshal(keyContents)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. AuthenticatedSafe
+	authSafe, err := asn1.Marshal([]contentInfo{
+		{ContentType: oidEncryptedData, Content: asn1.RawValue{FullBytes: explicit0(encData)}},
+		{ContentType: oidData, Content: asn1.RawValue{FullBytes: explicit0(keyOctets)}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	authSafeOctets, err := asn1.Marshal(authSafe)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. MAC
+	macSalt := make([]byte, 16)
+	if _, err := rand.Read(macSalt); err != nil {
+		return nil, err
+	}
+	mac := MAC(opts.MacHash, opts.Password, macSalt, opts.MacIterations, authSafe)
+
+	return asn1.Marshal(pfxPdu{
+		Version:  3,
+		AuthSafe: contentInfo{ContentType: oidData, Content: asn1.RawValue{FullBytes: explicit0(authSafeOctets)}},
+		MacData: macData{
+			Mac: digestInfo{
+				Algorithm: pkix.AlgorithmIdentifier{Algorithm: opts.MacOID, Parameters: asn1.NullRawValue},
+				Digest:    mac,
+			},
+			MacSalt:    macSalt,
+			Iterations: opts.MacIterations,
+		},
+	})
+}
+
+// bagAttributes 构建 localKeyId 与 friendlyName 属性
+func bagAttributes(localKeyID []byte, friendlyName string) ([]pkcs12Attribute, error) {
+	keyID, err := asn1.Marshal(localKeyID)
+	if err != nil {
+		return nil, err
+	}
+	attrs := []pkcs12Attribute{{ID: oidLocalKeyID, Value: asn1.RawValue{Tag: asn1.TagSet, Class: asn1.ClassUniversal, IsCompound: true, Bytes: keyID}}}
+
+	if friendlyName != "" {
+		units := utf16.Encode([]rune(friendlyName))
+		bmp := make([]byte, 0, 2*len(units))
+		for _, u := range units {
+			bmp = append(bmp, byte(u>>8), byte(u))
+		}
+		name, err := asn1.Marshal(asn1.RawValue{Tag: asn1.TagBMPString, Class: asn1.ClassUniversal, Bytes: bmp})
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, pkcs12Attribute{ID: oidFriendlyName, Value: asn1.RawValue{Tag: asn1.TagSet, Class: asn1.ClassUniversal, IsCompound: true, Bytes: name}})
+	}
+	return attrs, nil
+}
+
+// explicit0 包装为 [0] EXPLICIT
+func explicit0(der []byte) []byte {
+	b, _ := asn1.Marshal(asn1.RawValue{Tag: 0, Class: asn1.ClassContextSpecific, IsCompound: true, Bytes: der})
+	return b
+}
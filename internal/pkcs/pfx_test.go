@@ -0,0 +1,146 @@
+package pkcs
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/des"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/asn1"
+	"testing"
+)
+
+// decrypt 解密 PBES2(PBKDF2-HMAC-SHA256, AES-256-CBC) 或 pbeWithSHAAnd3-KeyTripleDES-CBC 数据
+func decrypt(t *testing.T, algo asn1.ObjectIdentifier, params []byte, password string, ciphertext []byte) []byte {
+	t.Helper()
+	switch {
+	case algo.Equal(OIDPBES2):
+		var p pbes2Params
+		if _, err := asn1.Unmarshal(params, &p); err != nil {
+			t.Fatal(err)
+		}
+		var kdf pbkdf2Params
+		if _, err := asn1.Unmarshal(p.KeyDerivationFunc.Parameters.FullBytes, &kdf); err != nil {
+			t.Fatal(err)
+		}
+		if !kdf.PRF.Algorithm.Equal(OIDHMACWithSHA256) || !p.EncryptionScheme.Algorithm.Equal(OIDAES256CBC) {
+			t.Fatalf("Unexpected PBES2 algorithms")
+		}
+		var iv []byte
+		if _, err := asn1.Unmarshal(p.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+			t.Fatal(err)
+		}
+		key, _ := pbkdf2.Key(sha256.New, password, kdf.Salt, kdf.Iterations, 32)
+		block, _ := aes.NewCipher(key)
+		plaintext, err := DecryptCBC(block, iv, ciphertext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return plaintext
+	case algo.Equal(OIDPBEWithSHAAnd3KeyTripleDESCBC):
+		var p pbeParams
+		if _, err := asn1.Unmarshal(params, &p); err != nil {
+			t.Fatal(err)
+		}
+		key := PKCS12KDF(sha1.New, BMPString(password), p.Salt, p.Iterations, 1, 24)
+		iv := PKCS12KDF(sha1.New, BMPString(password), p.Salt, p.Iterations, 2, 8)
+		block, _ := des.NewTripleDESCipher(key)
+		plaintext, err := DecryptCBC(block, iv, ciphertext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return plaintext
+	}
+	t.Fatalf("Unexpected algorithm %v", algo)
+	return nil
+}
+
+func TestEncodePFX(t *testing.T) {
+	key := []byte("pkcs8-private-key")
+	certs := [][]byte{[]byte("leaf-der"), []byte("intermediate-der")}
+
+	modern := &PBES2{PRF: sha256.New, PRFOID: OIDHMACWithSHA256, Cipher: aes.NewCipher, CipherOID: OIDAES256CBC, KeyLen: 32, Iterations: 10, SaltLen: 16}
+	legacy := &PKCS12PBE{Hash: sha1.New, OID: OIDPBEWithSHAAnd3KeyTripleDESCBC, Cipher: des.NewTripleDESCipher, KeyLen: 24, Iterations: 10, SaltLen: 8}
+
+	for name, enc := range map[string]Encryptor{"modern": modern, "legacy": legacy} {
+		t.Run(name, func(t *testing.T) {
+			der, err := EncodePFX(key, certs, "example", &PFXOptions{
+				Password:      "secret",
+				KeyEncryptor:  enc,
+				CertEncryptor: enc,
+				MacHash:       sha256.New,
+				MacOID:        OIDSHA256,
+				MacIterations: 10,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var pfx pfxPdu
+			if _, err := asn1.Unmarshal(der, &pfx); err != nil {
+				t.Fatal(err)
+			}
+			var authSafeOctets []byte
+			if _, err := asn1.Unmarshal(pfx.AuthSafe.Content.Bytes, &authSafeOctets); err != nil {
+				t.Fatal(err)
+			}
+
+			// 校验MAC
+			key := PKCS12KDF(sha256.New, BMPString("secret"), pfx.MacData.MacSalt, pfx.MacData.Iterations, 3, 32)
+			mac := hmac.New(sha256.New, key)
+			mac.Write(authSafeOctets)
+			if !hmac.Equal(mac.Sum(nil), pfx.MacData.Mac.Digest) {
+				t.Fatal("MAC verification failed")
+			}
+
+			var authSafe []contentInfo
+			if _, err := asn1.Unmarshal(authSafeOctets, &authSafe); err != nil {
+				t.Fatal(err)
+			}
+			if len(authSafe) != 2 {
+				t.Fatalf("Expected 2 content infos, got %d", len(authSafe))
+			}
+
+			// 证书包
+			var ed encryptedData
+			if _, err := asn1.Unmarshal(authSafe[0].Content.Bytes, &ed); err != nil {
+				t.Fatal(err)
+			}
+			algo := ed.EncryptedContentInfo.ContentEncryptionAlgorithm
+			plain := decrypt(t, algo.Algorithm, algo.Parameters.FullBytes, "secret", ed.EncryptedContentInfo.EncryptedContent)
+			var bags []safeBag
+			if _, err := asn1.Unmarshal(plain, &bags); err != nil {
+				t.Fatal(err)
+			}
+			if len(bags) != 2 {
+				t.Fatalf("Expected 2 cert bags, got %d", len(bags))
+			}
+			var cb certBag
+			if _, err := asn1.Unmarshal(bags[0].Value.Bytes, &cb); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(cb.Data, certs[0]) {
+				t.Error("Leaf certificate mismatch")
+			}
+
+			// 私钥包
+			var keyOctets []byte
+			if _, err := asn1.Unmarshal(authSafe[1].Content.Bytes, &keyOctets); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := asn1.Unmarshal(keyOctets, &bags); err != nil {
+				t.Fatal(err)
+			}
+			var epki EncryptedPrivateKeyInfo
+			if _, err := asn1.Unmarshal(bags[0].Value.Bytes, &epki); err != nil {
+				t.Fatal(err)
+			}
+			plain = decrypt(t, epki.Algorithm.Algorithm, epki.Algorithm.Parameters.FullBytes, "secret", epki.EncryptedData)
+			if !bytes.Equal(plain, []byte("pkcs8-private-key")) {
+				t.Error("Private key mismatch")
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+// Package testpki 为测试生成证书及根证书、中间证书组成的测试PKI
+package testpki
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// PKI 测试根证书与中间证书
+type PKI struct {
+	Root, Inter       *x509.Certificate
+	RootKey, InterKey *ecdsa.PrivateKey
+}
+
+// New 生成有效期分别为 48 小时和 24 小时的根证书与中间证书
+func New(t testing.TB) *PKI {
+	t.Helper()
+	now := time.Now()
+	root, rootKey := Issue(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Root"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(48 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}, nil, nil)
+	inter, interKey := Issue(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               pkix.Name{CommonName: "Test Intermediate"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}, root, rootKey)
+	return &PKI{Root: root, Inter: inter, RootKey: rootKey, InterKey: interKey}
+}
+
+// Roots 返回仅包含根证书的根证书列表
+func (p *PKI) Roots() []*x509.Certificate {
+	return []*x509.Certificate{p.Root}
+}
+
+// Issue 生成 P-256 密钥并由 parent 签发证书，parent 为空时自签名
+func Issue(t testing.TB, tmpl, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key := NewKey(t)
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	return NewCert(t, tmpl, parent, &key.PublicKey, parentKey), key
+}
+
+// NewKey 生成 P-256 密钥
+func NewKey(t testing.TB) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// NewCert 使用 priv 签发公钥为 pub 的证书
+func NewCert(t testing.TB, tmpl, parent *x509.Certificate, pub, priv any) *x509.Certificate {
+	t.Helper()
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, pub, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
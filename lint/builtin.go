@@ -0,0 +1,331 @@
+package lint
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/trustasia-com/certm-plugin-sdk/certutil"
+	"github.com/trustasia-com/certm-plugin-sdk/gm"
+)
+
+// minSerialBytes 序列号最少字节数，CA/B基线要求至少64位随机数
+const minSerialBytes = 8
+
+func init() {
+	for _, l := range builtinLints {
+		Register(l)
+	}
+}
+
+// builtinLints 内置检查项，前半部分由策略驱动，后半部分为固定的合规检查
+var builtinLints = []*Lint{
+	{
+		Name:        "key_size",
+		Description: "RSA/ECDSA密钥位数不低于策略要求",
+		Severity:    SeverityError,
+		Check:       checkKeySize,
+	},
+	{
+		Name:        "key_type",
+		Description: "密钥类型在策略允许范围内",
+		Severity:    SeverityError,
+		Check:       checkKeyType,
+	},
+	{
+		Name:        "signature_algorithm",
+		Description: "签名算法在策略允许范围内",
+		Severity:    SeverityError,
+		Check:       checkSignatureAlgorithm,
+	},
+	{
+		Name:        "max_validity",
+		Description: "有效期不超过策略要求",
+		Severity:    SeverityError,
+		Check:       checkMaxValidity,
+	},
+	{
+		Name:        "required_sans",
+		Description: "包含策略要求的SAN",
+		Severity:    SeverityError,
+		Check:       checkRequiredSANs,
+	},
+	{
+		Name:        "wildcard_zone",
+		Description: "禁止的域中不包含通配符SAN",
+		Severity:    SeverityError,
+		Check:       checkWildcardZones,
+	},
+	{
+		Name:        "required_eku",
+		Description: "包含策略要求的扩展密钥用法",
+		Severity:    SeverityError,
+		Check:       checkRequiredEKUs,
+	},
+	{
+		Name:        "sha1_signature",
+		Description: "不使用SHA-1签名",
+		Severity:    SeverityError,
+		Check:       checkSHA1Signature,
+	},
+	{
+		Name:        "validity_order",
+		Description: "生效时间早于过期时间",
+		Severity:    SeverityError,
+		Check:       checkValidityOrder,
+	},
+	{
+		Name:        "leaf_is_ca",
+		Description: "终端证书不得为CA证书",
+		Severity:    SeverityError,
+		Check:       checkLeafIsCA,
+	},
+	{
+		Name:        "san_missing",
+		Description: "证书包含SAN扩展",
+		Severity:    SeverityError,
+		Check:       checkSANMissing,
+	},
+	{
+		Name:        "dns_name_invalid",
+		Description: "DNS名称格式合法",
+		Severity:    SeverityError,
+		Check:       checkDNSNames,
+	},
+	{
+		Name:        "cn_not_in_san",
+		Description: "通用名称包含在SAN中",
+		Severity:    SeverityWarn,
+		Check:       checkCNInSAN,
+	},
+	{
+		Name:        "serial_number",
+		Description: "序列号为正数且至少64位",
+		Severity:    SeverityWarn,
+		Check:       checkSerialNumber,
+	},
+	{
+		Name:        "rsa_exponent",
+		Description: "RSA公钥指数为不小于65537的奇数",
+		Severity:    SeverityWarn,
+		Check:       checkRSAExponent,
+	},
+}
+
+// keyTypeName 返回公钥类型名称，对应 KeyTypes
+func keyTypeName(pub any) string {
+	switch pub.(type) {
+	case *rsa.PublicKey:
+		return "RSA"
+	case *ecdsa.PublicKey:
+		return "ECDSA"
+	case ed25519.PublicKey:
+		return "Ed25519"
+	case *gm.PublicKey:
+		return "SM2"
+	default:
+		return "Unknown"
+	}
+}
+
+// signatureAlgorithmName 返回签名算法名称，SM2证书为 SM2-SM3
+func signatureAlgorithmName(cert *x509.Certificate) string {
+	if cert.SignatureAlgorithm == x509.UnknownSignatureAlgorithm {
+		if _, ok := cert.PublicKey.(*gm.PublicKey); ok {
+			return "SM2-SM3"
+		}
+	}
+	return cert.SignatureAlgorithm.String()
+}
+
+func checkKeySize(cert *x509.Certificate, p *Policy) []string {
+	switch k := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if bits := k.N.BitLen(); bits < p.MinRSABits {
+			return []string{fmt.Sprintf("RSA key has %d bits, at least %d required", bits, p.MinRSABits)}
+		}
+	case *ecdsa.PublicKey:
+		if bits := k.Curve.Params().BitSize; bits < p.MinECDSABits {
+			return []string{fmt.Sprintf("ECDSA key has %d bits, at least %d required", bits, p.MinECDSABits)}
+		}
+	}
+	return nil
+}
+
+func checkKeyType(cert *x509.Certificate, p *Policy) []string {
+	if len(p.KeyTypes) == 0 {
+		return nil
+	}
+	if name := keyTypeName(cert.PublicKey); !slices.Contains(p.KeyTypes, name) {
+		return []string{fmt.Sprintf("key type %s is not allowed", name)}
+	}
+	return nil
+}
+
+func checkSignatureAlgorithm(cert *x509.Certificate, p *Policy) []string {
+	if len(p.SignatureAlgorithms) == 0 {
+		return nil
+	}
+	name := signatureAlgorithmName(cert)
+	for _, allowed := range p.SignatureAlgorithms {
+		if strings.EqualFold(allowed, name) {
+			return nil
+		}
+	}
+	return []string{fmt.Sprintf("signature algorithm %s is not allowed", name)}
+}
+
+func checkMaxValidity(cert *x509.Certificate, p *Policy) []string {
+	if p.MaxValidityDays <= 0 {
+		return nil
+	}
+	// 有效期包含 NotAfter 当秒，与CA/B基线的计算方式一致
+	validity := cert.NotAfter.Sub(cert.NotBefore) + time.Second
+	if validity > time.Duration(p.MaxValidityDays)*24*time.Hour {
+		days := int(validity.Hours() / 24)
+		return []string{fmt.Sprintf("validity of %d days exceeds %d days", days, p.MaxValidityDays)}
+	}
+	return nil
+}
+
+func checkRequiredSANs(cert *x509.Certificate, p *Policy) []string {
+	var msgs []string
+	for _, san := range p.RequiredSANs {
+		if err := cert.VerifyHostname(san); err != nil {
+			msgs = append(msgs, fmt.Sprintf("required SAN %s is not covered", san))
+		}
+	}
+	return msgs
+}
+
+func checkWildcardZones(cert *x509.Certificate, p *Policy) []string {
+	var msgs []string
+	for _, name := range cert.DNSNames {
+		if !strings.HasPrefix(name, "*.") {
+			continue
+		}
+		base := strings.ToLower(strings.TrimSuffix(name[2:], "."))
+		for _, zone := range p.NoWildcardZones {
+			zone = strings.ToLower(strings.TrimSuffix(zone, "."))
+			if base == zone || strings.HasSuffix(base, "."+zone) {
+				msgs = append(msgs, fmt.Sprintf("wildcard %s is not allowed in zone %s", name, zone))
+				break
+			}
+		}
+	}
+	return msgs
+}
+
+func checkRequiredEKUs(cert *x509.Certificate, p *Policy) []string {
+	var msgs []string
+	for _, name := range p.RequiredEKUs {
+		eku, ok := ekuNames[name]
+		if !ok {
+			msgs = append(msgs, fmt.Sprintf("unknown extended key usage %s", name))
+			continue
+		}
+		if !slices.Contains(cert.ExtKeyUsage, eku) && !slices.Contains(cert.ExtKeyUsage, x509.ExtKeyUsageAny) {
+			msgs = append(msgs, fmt.Sprintf("extended key usage %s is missing", name))
+		}
+	}
+	return msgs
+}
+
+func checkSHA1Signature(cert *x509.Certificate, _ *Policy) []string {
+	switch cert.SignatureAlgorithm {
+	case x509.SHA1WithRSA, x509.ECDSAWithSHA1, x509.DSAWithSHA1:
+		return []string{fmt.Sprintf("certificate is signed with %s", cert.SignatureAlgorithm)}
+	}
+	return nil
+}
+
+func checkValidityOrder(cert *x509.Certificate, _ *Policy) []string {
+	if !cert.NotAfter.After(cert.NotBefore) {
+		return []string{"NotAfter is not later than NotBefore"}
+	}
+	return nil
+}
+
+func checkLeafIsCA(cert *x509.Certificate, _ *Policy) []string {
+	if cert.IsCA {
+		return []string{"basic constraints mark the certificate as a CA"}
+	}
+	return nil
+}
+
+func checkSANMissing(cert *x509.Certificate, _ *Policy) []string {
+	if len(certutil.SANs(cert)) == 0 {
+		return []string{"certificate has no subject alternative names"}
+	}
+	return nil
+}
+
+func checkDNSNames(cert *x509.Certificate, _ *Policy) []string {
+	var msgs []string
+	for _, name := range cert.DNSNames {
+		if !validDNSName(name) {
+			msgs = append(msgs, fmt.Sprintf("DNS name %q is malformed", name))
+		}
+	}
+	return msgs
+}
+
+// validDNSName 判断DNS名称是否为合法主机名，允许最左侧为通配符
+func validDNSName(name string) bool {
+	name = strings.TrimPrefix(name, "*.")
+	if name == "" || len(name) > 253 || net.ParseIP(name) != nil {
+		return false
+	}
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func checkCNInSAN(cert *x509.Certificate, _ *Policy) []string {
+	cn := cert.Subject.CommonName
+	if cn == "" {
+		return nil
+	}
+	for _, san := range certutil.SANs(cert) {
+		if strings.EqualFold(san, cn) {
+			return nil
+		}
+	}
+	return []string{fmt.Sprintf("common name %s is not present in SANs", cn)}
+}
+
+func checkSerialNumber(cert *x509.Certificate, _ *Policy) []string {
+	serial := cert.SerialNumber
+	if serial == nil || serial.Sign() <= 0 {
+		return []string{"serial number is not positive"}
+	}
+	if n := len(serial.Bytes()); n < minSerialBytes {
+		return []string{fmt.Sprintf("serial number has %d bytes, at least %d recommended", n, minSerialBytes)}
+	}
+	return nil
+}
+
+func checkRSAExponent(cert *x509.Certificate, _ *Policy) []string {
+	k, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil
+	}
+	if k.E < 65537 || k.E%2 == 0 {
+		return []string{fmt.Sprintf("RSA public exponent %d is weak", k.E)}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+// Package lint 提供签发证书的策略检查，包含可配置的组织策略及内置合规检查项
+package lint
+
+import (
+	"crypto/x509"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	certm "github.com/trustasia-com/certm-plugin-sdk"
+	"github.com/trustasia-com/certm-plugin-sdk/certutil"
+	"github.com/trustasia-com/certm-plugin-sdk/gm"
+)
+
+// Severity 检查结果级别
+type Severity string
+
+const (
+	SeverityNotice Severity = "notice" // 提示
+	SeverityWarn   Severity = "warn"   // 警告
+	SeverityError  Severity = "error"  // 错误
+)
+
+// rank 返回级别高低，未知级别视为 0
+func (s Severity) rank() int {
+	switch s {
+	case SeverityNotice:
+		return 1
+	case SeverityWarn:
+		return 2
+	case SeverityError:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Finding 单条检查结果
+type Finding struct {
+	Lint     string   `json:"lint"`     // 检查项名称
+	Severity Severity `json:"severity"` // 级别
+	Message  string   `json:"message"`  // 说明
+}
+
+// Lint 检查项
+type Lint struct {
+	Name        string   // 名称，唯一
+	Description string   // 描述
+	Severity    Severity // 发现问题时的级别
+	// Check 返回发现的问题说明，无问题时返回空
+	Check func(cert *x509.Certificate, p *Policy) []string
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []*Lint
+)
+
+// Register 注册检查项，同名检查项将被替换
+func Register(l *Lint) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for i, existing := range registry {
+		if existing.Name == l.Name {
+			registry[i] = l
+			return
+		}
+	}
+	registry = append(registry, l)
+}
+
+// Lints 返回已注册的全部检查项
+func Lints() []*Lint {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return slices.Clone(registry)
+}
+
+// Result 检查结果
+type Result struct {
+	SHA1     string    `json:"sha1"`     // 叶子证书SHA1
+	Findings []Finding `json:"findings"` // 发现的问题
+	FailOn   Severity  `json:"fail_on"`  // 判定失败的最低级别
+}
+
+// Failed 判断是否存在不低于 FailOn 级别的问题
+func (r *Result) Failed() bool {
+	return len(r.failures()) > 0
+}
+
+// failures 返回不低于 FailOn 级别的问题
+func (r *Result) failures() []Finding {
+	failOn := r.FailOn
+	if failOn.rank() == 0 {
+		failOn = SeverityError
+	}
+	var failures []Finding
+	for _, f := range r.Findings {
+		if f.Severity.rank() >= failOn.rank() {
+			failures = append(failures, f)
+		}
+	}
+	return failures
+}
+
+// Err 检查失败时返回 *PolicyError，否则返回 nil
+func (r *Result) Err() error {
+	failures := r.failures()
+	if len(failures) == 0 {
+		return nil
+	}
+	return &PolicyError{Findings: failures}
+}
+
+// PolicyError 证书不符合策略
+type PolicyError struct {
+	Findings []Finding
+}
+
+func (e *PolicyError) Error() string {
+	msgs := make([]string, len(e.Findings))
+	for i, f := range e.Findings {
+		msgs[i] = fmt.Sprintf("%s: %s", f.Lint, f.Message)
+	}
+	return "lint: certificate violates policy: " + strings.Join(msgs, "; ")
+}
+
+// CheckCertificate 使用策略检查证书，p 为空时使用 DefaultPolicy
+func CheckCertificate(cert *x509.Certificate, p *Policy) *Result {
+	if p == nil {
+		p = DefaultPolicy()
+	}
+	result := &Result{FailOn: p.FailOn}
+	result.SHA1, _ = certutil.Fingerprint(cert, certutil.FingerprintSHA1)
+	for _, l := range Lints() {
+		if slices.Contains(p.Disabled, l.Name) {
+			continue
+		}
+		for _, msg := range l.Check(cert, p) {
+			result.Findings = append(result.Findings, Finding{Lint: l.Name, Severity: l.Severity, Message: msg})
+		}
+	}
+	return result
+}
+
+// Check 检查证书组件输出的叶子证书，支持SM2证书
+func Check(data *certm.CertOutputData, p *Policy) (*Result, error) {
+	certs, err := certutil.ParseCertificatesPEM(data.ChainPEM...)
+	if err != nil {
+		// 标准库无法解析SM2公钥，回退到国密解析
+		var gmErr error
+		if certs, gmErr = gm.ParseCertificatesPEM(data.ChainPEM...); gmErr != nil {
+			return nil, fmt.Errorf("lint: %w", err)
+		}
+	}
+	return CheckCertificate(certs[0], p), nil
+}
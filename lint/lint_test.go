@@ -0,0 +1,224 @@
+package lint
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	certm "github.com/trustasia-com/certm-plugin-sdk"
+	"github.com/trustasia-com/certm-plugin-sdk/certutil"
+	"github.com/trustasia-com/certm-plugin-sdk/helper"
+)
+
+var (
+	caKey, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caCert   = &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+)
+
+// newLeaf 签发叶子证书，modify 可调整模板
+func newLeaf(t *testing.T, pub any, modify func(*x509.Certificate)) *x509.Certificate {
+	t.Helper()
+	serial, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 120))
+	tmpl := &x509.Certificate{
+		SerialNumber: serial.Add(serial, new(big.Int).Lsh(big.NewInt(1), 120)),
+		Subject:      pkix.Name{CommonName: "www.example.com"},
+		DNSNames:     []string{"www.example.com", "example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(90 * 24 * time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if modify != nil {
+		modify(tmpl)
+	}
+	if pub == nil {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		pub = &key.PublicKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, caCert, pub, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func findingNames(r *Result) map[string]bool {
+	names := make(map[string]bool)
+	for _, f := range r.Findings {
+		names[f.Lint] = true
+	}
+	return names
+}
+
+func TestCheckCertificate_Clean(t *testing.T) {
+	r := CheckCertificate(newLeaf(t, nil, nil), nil)
+	if len(r.Findings) != 0 {
+		t.Fatalf("unexpected findings: %+v", r.Findings)
+	}
+	if r.Failed() || r.Err() != nil {
+		t.Error("clean certificate should pass")
+	}
+	if r.SHA1 == "" {
+		t.Error("SHA1 should be set")
+	}
+}
+
+func TestCheckCertificate_Policy(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := newLeaf(t, &rsaKey.PublicKey, func(c *x509.Certificate) {
+		c.DNSNames = []string{"*.internal.example.com", "www.example.com"}
+		c.NotAfter = c.NotBefore.Add(400 * 24 * time.Hour)
+		c.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	})
+	p := DefaultPolicy()
+	p.KeyTypes = []string{"ECDSA"}
+	p.SignatureAlgorithms = []string{"ECDSA-SHA384"}
+	p.RequiredSANs = []string{"api.example.com"}
+	p.NoWildcardZones = []string{"example.com"}
+
+	r := CheckCertificate(cert, p)
+	names := findingNames(r)
+	for _, want := range []string{"key_size", "key_type", "signature_algorithm", "max_validity", "required_sans", "wildcard_zone", "required_eku"} {
+		if !names[want] {
+			t.Errorf("missing finding %s", want)
+		}
+	}
+	if names["cn_not_in_san"] {
+		t.Error("cn_not_in_san should not be reported")
+	}
+
+	var perr *PolicyError
+	if !errors.As(r.Err(), &perr) || len(perr.Findings) == 0 {
+		t.Fatalf("Err() = %v, want *PolicyError", r.Err())
+	}
+}
+
+func TestCheckCertificate_Builtin(t *testing.T) {
+	cert := newLeaf(t, nil, func(c *x509.Certificate) {
+		c.SerialNumber = big.NewInt(42)
+		c.Subject.CommonName = "other.example.com"
+		c.DNSNames = []string{"www.example.com", "bad_name.example.com"}
+		c.IsCA = true
+		c.BasicConstraintsValid = true
+	})
+	r := CheckCertificate(cert, &Policy{})
+	names := findingNames(r)
+	for _, want := range []string{"serial_number", "cn_not_in_san", "dns_name_invalid", "leaf_is_ca"} {
+		if !names[want] {
+			t.Errorf("missing finding %s", want)
+		}
+	}
+
+	// 禁用后不再报告
+	r = CheckCertificate(cert, &Policy{Disabled: []string{"leaf_is_ca", "dns_name_invalid"}})
+	// serial_number 与 cn_not_in_san 为警告，默认不判定失败
+	if r.Failed() {
+		t.Errorf("only warnings expected, got %+v", r.Findings)
+	}
+	r.FailOn = SeverityWarn
+	if !r.Failed() {
+		t.Error("warnings should fail with FailOn=warn")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register(&Lint{
+		Name:     "test_org_unit",
+		Severity: SeverityNotice,
+		Check: func(cert *x509.Certificate, _ *Policy) []string {
+			if len(cert.Subject.OrganizationalUnit) == 0 {
+				return []string{"organizational unit is empty"}
+			}
+			return nil
+		},
+	})
+	defer func() {
+		registryMu.Lock()
+		registry = registry[:len(registry)-1]
+		registryMu.Unlock()
+	}()
+
+	r := CheckCertificate(newLeaf(t, nil, nil), nil)
+	if len(r.Findings) != 1 || r.Findings[0].Lint != "test_org_unit" || r.Findings[0].Severity != SeverityNotice {
+		t.Fatalf("findings = %+v", r.Findings)
+	}
+	if r.Failed() {
+		t.Error("notice should not fail by default")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	cert := newLeaf(t, nil, nil)
+	r, err := Check(&certm.CertOutputData{ChainPEM: []string{certutil.EncodeCertificatePEM(cert)}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Failed() {
+		t.Errorf("unexpected failure: %+v", r.Findings)
+	}
+
+	if _, err := Check(&certm.CertOutputData{}, nil); err == nil {
+		t.Error("expected error for empty chain")
+	}
+}
+
+func TestPolicyFromConfig(t *testing.T) {
+	var cfg helper.FieldConfig
+	raw := `{
+		"lint_min_rsa_bits": 3072,
+		"lint_min_ecdsa_bits": 384,
+		"lint_key_types": ["RSA", " ECDSA "],
+		"lint_max_validity_days": 90,
+		"lint_no_wildcard_zones": ["example.com"],
+		"lint_fail_on": "warn"
+	}`
+	if err := json.Unmarshal([]byte(raw), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	p, err := PolicyFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.MinRSABits != 3072 || p.MinECDSABits != 384 || p.MaxValidityDays != 90 || p.FailOn != SeverityWarn {
+		t.Errorf("policy = %+v", p)
+	}
+	if len(p.KeyTypes) != 2 || p.KeyTypes[1] != "ECDSA" {
+		t.Errorf("KeyTypes = %v", p.KeyTypes)
+	}
+	if len(p.RequiredEKUs) != 1 || p.RequiredEKUs[0] != "serverAuth" {
+		t.Errorf("RequiredEKUs should keep default, got %v", p.RequiredEKUs)
+	}
+
+	for name, cfg := range map[string]helper.FieldConfig{
+		"key type": {KeyKeyTypes: []any{"DSA"}},
+		"eku":      {KeyRequiredEKUs: []any{"anything"}},
+		"fail on":  {KeyFailOn: "fatal"},
+		"type":     {KeyMaxValidityDays: "90"},
+	} {
+		if _, err := PolicyFromConfig(cfg); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package lint
+
+import (
+	"crypto/x509"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/trustasia-com/certm-plugin-sdk/helper"
+)
+
+// 策略配置字段key
+const (
+	KeyMinRSABits          = "lint_min_rsa_bits"         // RSA最小位数
+	KeyMinECDSABits        = "lint_min_ecdsa_bits"       // ECDSA最小位数
+	KeyKeyTypes            = "lint_key_types"            // 允许的密钥类型
+	KeySignatureAlgorithms = "lint_signature_algorithms" // 允许的签名算法
+	KeyMaxValidityDays     = "lint_max_validity_days"    // 最长有效期
+	KeyRequiredSANs        = "lint_required_sans"        // 必须包含的SAN
+	KeyNoWildcardZones     = "lint_no_wildcard_zones"    // 禁止通配符的域
+	KeyRequiredEKUs        = "lint_required_ekus"        // 必须包含的扩展密钥用法
+	KeyDisabled            = "lint_disabled"             // 禁用的检查项
+	KeyFailOn              = "lint_fail_on"              // 判定失败的最低级别
+)
+
+// KeyTypes 支持的密钥类型名称
+var KeyTypes = []string{"RSA", "ECDSA", "Ed25519", "SM2"}
+
+// ekuNames 扩展密钥用法名称
+var ekuNames = map[string]x509.ExtKeyUsage{
+	"serverAuth":      x509.ExtKeyUsageServerAuth,
+	"clientAuth":      x509.ExtKeyUsageClientAuth,
+	"codeSigning":     x509.ExtKeyUsageCodeSigning,
+	"emailProtection": x509.ExtKeyUsageEmailProtection,
+	"timeStamping":    x509.ExtKeyUsageTimeStamping,
+	"OCSPSigning":     x509.ExtKeyUsageOCSPSigning,
+}
+
+// Policy 证书策略，零值字段表示不限制
+type Policy struct {
+	MinRSABits          int      // RSA最小位数
+	MinECDSABits        int      // ECDSA最小位数
+	KeyTypes            []string // 允许的密钥类型：RSA/ECDSA/Ed25519/SM2
+	SignatureAlgorithms []string // 允许的签名算法，如 SHA256-RSA、ECDSA-SHA256、SM2-SM3
+	MaxValidityDays     int      // 最长有效期(天)
+	RequiredSANs        []string // 必须包含的SAN
+	NoWildcardZones     []string // 禁止签发通配符证书的域，包含子域
+	RequiredEKUs        []string // 必须包含的扩展密钥用法：serverAuth/clientAuth 等
+	Disabled            []string // 禁用的检查项名称
+	FailOn              Severity // 判定失败的最低级别，默认 error
+}
+
+// DefaultPolicy 返回默认策略：RSA 2048位、ECDSA 256位、最长398天、需要 serverAuth
+func DefaultPolicy() *Policy {
+	return &Policy{
+		MinRSABits:      2048,
+		MinECDSABits:    256,
+		MaxValidityDays: 398,
+		RequiredEKUs:    []string{"serverAuth"},
+		FailOn:          SeverityError,
+	}
+}
+
+// Fields 返回策略配置字段，可加入组件的配置Schema
+func Fields() []helper.Field {
+	d := DefaultPolicy()
+	return []helper.Field{
+		{
+			Type:    helper.FieldTypeInt,
+			Format:  helper.FieldFormatNumber,
+			Key:     KeyMinRSABits,
+			Name:    "RSA最小位数",
+			Default: d.MinRSABits,
+		},
+		{
+			Type:    helper.FieldTypeInt,
+			Format:  helper.FieldFormatSelect,
+			Key:     KeyMinECDSABits,
+			Name:    "ECDSA最小位数",
+			Default: d.MinECDSABits,
+			Options: []helper.FieldOption{
+				{Value: 256, Name: "256"},
+				{Value: 384, Name: "384"},
+				{Value: 521, Name: "521"},
+			},
+		},
+		{
+			Type:        helper.FieldTypeStringArray,
+			Format:      helper.FieldFormatTextarea,
+			Key:         KeyKeyTypes,
+			Name:        "允许的密钥类型",
+			Description: "RSA、ECDSA、Ed25519、SM2，留空不限制",
+		},
+		{
+			Type:        helper.FieldTypeStringArray,
+			Format:      helper.FieldFormatTextarea,
+			Key:         KeySignatureAlgorithms,
+			Name:        "允许的签名算法",
+			Description: "如 SHA256-RSA、ECDSA-SHA256、SM2-SM3，留空不限制",
+		},
+		{
+			Type:    helper.FieldTypeInt,
+			Format:  helper.FieldFormatNumber,
+			Key:     KeyMaxValidityDays,
+			Name:    "最长有效期(天)",
+			Default: d.MaxValidityDays,
+		},
+		{
+			Type:        helper.FieldTypeStringArray,
+			Format:      helper.FieldFormatTextarea,
+			Key:         KeyRequiredSANs,
+			Name:        "必须包含的SAN",
+			Description: "域名或IP，每行一个",
+		},
+		{
+			Type:        helper.FieldTypeStringArray,
+			Format:      helper.FieldFormatTextarea,
+			Key:         KeyNoWildcardZones,
+			Name:        "禁止通配符的域",
+			Description: "如 example.com，包含子域",
+		},
+		{
+			Type:        helper.FieldTypeStringArray,
+			Format:      helper.FieldFormatTextarea,
+			Key:         KeyRequiredEKUs,
+			Name:        "必须包含的扩展密钥用法",
+			Default:     d.RequiredEKUs,
+			Description: "serverAuth、clientAuth、codeSigning、emailProtection、timeStamping、OCSPSigning",
+		},
+		{
+			Type:        helper.FieldTypeStringArray,
+			Format:      helper.FieldFormatTextarea,
+			Key:         KeyDisabled,
+			Name:        "禁用的检查项",
+			Description: "检查项名称，见 lint.Lints()",
+		},
+		{
+			Type:    helper.FieldTypeString,
+			Format:  helper.FieldFormatSelect,
+			Key:     KeyFailOn,
+			Name:    "失败级别",
+			Default: string(d.FailOn),
+			Options: []helper.FieldOption{
+				{Value: string(SeverityError), Name: "错误"},
+				{Value: string(SeverityWarn), Name: "警告"},
+				{Value: string(SeverityNotice), Name: "提示"},
+			},
+		},
+	}
+}
+
+// PolicyFromConfig 从组件配置构建策略，未配置的字段使用默认值
+func PolicyFromConfig(cfg helper.FieldConfig) (*Policy, error) {
+	if err := cfg.Validate(Fields()); err != nil {
+		return nil, err
+	}
+	p := DefaultPolicy()
+	if _, ok := cfg[KeyMinRSABits]; ok {
+		p.MinRSABits = cfg.Int(KeyMinRSABits)
+	}
+	if _, ok := cfg[KeyMinECDSABits]; ok {
+		p.MinECDSABits = cfg.Int(KeyMinECDSABits)
+	}
+	if _, ok := cfg[KeyMaxValidityDays]; ok {
+		p.MaxValidityDays = cfg.Int(KeyMaxValidityDays)
+	}
+	if _, ok := cfg[KeyFailOn]; ok {
+		p.FailOn = Severity(cfg.String(KeyFailOn))
+	}
+	for key, dst := range map[string]*[]string{
+		KeyKeyTypes:            &p.KeyTypes,
+		KeySignatureAlgorithms: &p.SignatureAlgorithms,
+		KeyRequiredSANs:        &p.RequiredSANs,
+		KeyNoWildcardZones:     &p.NoWildcardZones,
+		KeyRequiredEKUs:        &p.RequiredEKUs,
+		KeyDisabled:            &p.Disabled,
+	} {
+		if _, ok := cfg[key]; ok {
+			*dst = trimValues(cfg.StringSlice(key))
+		}
+	}
+
+	for _, t := range p.KeyTypes {
+		if !slices.Contains(KeyTypes, t) {
+			return nil, fmt.Errorf("lint: unknown key type %q", t)
+		}
+	}
+	for _, name := range p.RequiredEKUs {
+		if _, ok := ekuNames[name]; !ok {
+			return nil, fmt.Errorf("lint: unknown extended key usage %q", name)
+		}
+	}
+	return p, nil
+}
+
+// trimValues 去除空白及空值
+func trimValues(values []string) []string {
+	var trimmed []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			trimmed = append(trimmed, v)
+		}
+	}
+	return trimmed
+}
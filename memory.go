@@ -83,8 +83,12 @@ func call[T any](fnName string, args ...interface{}) (*T, error) {
 	// 4. 检查是否是错误
 	var errResp struct {
 		Error string `json:"error"`
+		Code  string `json:"code"`
 	}
 	if err := json.Unmarshal(resultJSON, &errResp); err == nil && errResp.Error != "" {
+		if errResp.Code == ErrCodePermissionDenied {
+			return nil, fmt.Errorf("%w: %s", ErrPermissionDenied, errResp.Error)
+		}
 		return nil, fmt.Errorf("%s", errResp.Error)
 	}
 
@@ -0,0 +1,95 @@
+package certm
+
+import "context"
+
+const (
+	tlsProberCtxKey   contextKey = "tlsProber"
+	dnsResolverCtxKey contextKey = "dnsResolver"
+)
+
+// TLSProbeRequest 主机TLS探测请求
+type TLSProbeRequest struct {
+	Address    string `json:"address"`               // 连接地址: host:port
+	ServerName string `json:"server_name,omitempty"` // SNI主机名
+	Protocol   string `json:"protocol,omitempty"`    // 协议: tls/smtp/imap/pop3/ftp/ldap/postgres/mysql，为空表示tls
+	Timeout    int    `json:"timeout,omitempty"`     // 超时时间(秒)，0使用主机默认值
+
+	// 以下字段用于安全扫描，为空时使用主机默认配置
+	MinVersion   uint16   `json:"min_version,omitempty"`   // 最低协议版本，如 0x0301 (TLS1.0)
+	MaxVersion   uint16   `json:"max_version,omitempty"`   // 最高协议版本，如 0x0303 (TLS1.2)
+	CipherSuites []uint16 `json:"cipher_suites,omitempty"` // 提供的TLS1.2及以下密码套件IANA编号，可包含DHE/NULL/EXPORT/匿名套件
+}
+
+// TLSProbeResponse 主机TLS探测结果
+type TLSProbeResponse struct {
+	RemoteAddr   string   `json:"remote_addr"`             // 实际连接地址
+	ChainPEM     []string `json:"chain_pem"`               // 对端证书链PEM，叶子证书在前
+	OCSPResponse []byte   `json:"ocsp_response,omitempty"` // OCSP装订响应
+	TLSVersion   string   `json:"tls_version"`             // 协商的协议版本
+	CipherSuite  string   `json:"cipher_suite"`            // 协商的密码套件
+	ResponseTime int      `json:"response_time"`           // 响应时间(ms)
+}
+
+// TLSProber 主机TLS探测能力，WASM中由主机函数 tls_probe 实现
+type TLSProber interface {
+	TLSProbe(req *TLSProbeRequest) (*TLSProbeResponse, error)
+}
+
+// GetTLSProber 获取主机TLS探测能力
+// nolint:errcheck
+func GetTLSProber(ctx context.Context) TLSProber {
+	return ctx.Value(tlsProberCtxKey).(TLSProber)
+}
+
+// SetTLSProber 设置主机TLS探测能力
+func SetTLSProber(ctx context.Context, prober TLSProber) context.Context {
+	return context.WithValue(ctx, tlsProberCtxKey, prober)
+}
+
+// DNSRecordType DNS记录类型
+type DNSRecordType string
+
+const (
+	DNSRecordA     DNSRecordType = "A"     // IPv4地址
+	DNSRecordAAAA  DNSRecordType = "AAAA"  // IPv6地址
+	DNSRecordCNAME DNSRecordType = "CNAME" // 别名
+	DNSRecordTXT   DNSRecordType = "TXT"   // 文本
+	DNSRecordCAA   DNSRecordType = "CAA"   // CA授权
+	DNSRecordTLSA  DNSRecordType = "TLSA"  // DANE证书关联
+)
+
+// DNSRequest 主机DNS查询请求
+type DNSRequest struct {
+	Name string        `json:"name"` // 查询名称
+	Type DNSRecordType `json:"type"` // 记录类型
+}
+
+// DNSRecord DNS记录，Value 为标准文本格式，如 CAA: 0 issue "letsencrypt.org"
+type DNSRecord struct {
+	Name  string        `json:"name"`  // 记录名称
+	Type  DNSRecordType `json:"type"`  // 记录类型
+	Value string        `json:"value"` // 记录值
+	TTL   int           `json:"ttl"`   // 生存时间(秒)
+}
+
+// DNSResponse 主机DNS查询结果，无记录时 Records 为空
+type DNSResponse struct {
+	Records       []DNSRecord `json:"records"`       // 记录列表
+	Authenticated bool        `json:"authenticated"` // 是否通过DNSSEC验证
+}
+
+// DNSResolver 主机DNS查询能力，WASM中由主机函数 dns_lookup 实现
+type DNSResolver interface {
+	DNSLookup(req *DNSRequest) (*DNSResponse, error)
+}
+
+// GetDNSResolver 获取主机DNS查询能力
+// nolint:errcheck
+func GetDNSResolver(ctx context.Context) DNSResolver {
+	return ctx.Value(dnsResolverCtxKey).(DNSResolver)
+}
+
+// SetDNSResolver 设置主机DNS查询能力
+func SetDNSResolver(ctx context.Context, resolver DNSResolver) context.Context {
+	return context.WithValue(ctx, dnsResolverCtxKey, resolver)
+}
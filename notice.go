@@ -0,0 +1,65 @@
+package certm
+
+import (
+	"fmt"
+	"time"
+)
+
+// Condition 返回规则中指定事件的触发条件，未配置时返回 nil
+func (r *NoticeRuleDetail) Condition(event NoticeEvent) *NoticeCondition {
+	for _, c := range r.Conditions {
+		if c.Event == event {
+			return c
+		}
+	}
+	return nil
+}
+
+// ShouldNotifyExpiring 判断剩余天数是否达到即将过期阈值
+func (r *NoticeRuleDetail) ShouldNotifyExpiring(daysRemaining int) bool {
+	c := r.Condition(NoticeEventCertExpiring)
+	return c != nil && daysRemaining >= 0 && daysRemaining <= c.ExpireDays
+}
+
+// Contains 判断时间是否处于免打扰时段，未配置时返回 false
+//
+// 时段或时区配置无效时返回错误；WASM环境可能缺少时区数据，此时时区无法加载也返回错误，
+// 由调用方决定是否发送通知，而不是按UTC误判。
+func (q *QuietHours) Contains(t time.Time) (bool, error) {
+	if q == nil {
+		return false, nil
+	}
+	start, err := parseClock(q.Start)
+	if err != nil {
+		return false, fmt.Errorf("certm: quiet hours start: %w", err)
+	}
+	end, err := parseClock(q.End)
+	if err != nil {
+		return false, fmt.Errorf("certm: quiet hours end: %w", err)
+	}
+	if start == end {
+		return false, nil
+	}
+
+	loc := time.UTC
+	if q.Timezone != "" {
+		if loc, err = time.LoadLocation(q.Timezone); err != nil {
+			return false, fmt.Errorf("certm: quiet hours timezone: %w", err)
+		}
+	}
+	t = t.In(loc)
+	now := t.Hour()*60 + t.Minute()
+	if start < end {
+		return now >= start && now < end, nil
+	}
+	return now >= start || now < end, nil
+}
+
+// parseClock 解析 HH:MM 格式时间，返回自零点起的分钟数
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid clock %q: %w", s, err)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,59 @@
+package certm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuietHoursContains(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 1, 1, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name string
+		q    *QuietHours
+		t    time.Time
+		want bool
+		err  bool
+	}{
+		{"nil", nil, at(23, 0), false, false},
+		{"same day inside", &QuietHours{Start: "12:00", End: "14:00"}, at(13, 30), true, false},
+		{"same day end", &QuietHours{Start: "12:00", End: "14:00"}, at(14, 0), false, false},
+		{"overnight late", &QuietHours{Start: "22:00", End: "08:00"}, at(23, 0), true, false},
+		{"overnight early", &QuietHours{Start: "22:00", End: "08:00"}, at(7, 59), true, false},
+		{"overnight outside", &QuietHours{Start: "22:00", End: "08:00"}, at(12, 0), false, false},
+		{"invalid", &QuietHours{Start: "25:00", End: "08:00"}, at(23, 0), false, true},
+		{"unknown timezone", &QuietHours{Start: "22:00", End: "08:00", Timezone: "Mars/Olympus"}, at(23, 0), false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.q.Contains(tt.t)
+			if got != tt.want || (err != nil) != tt.err {
+				t.Errorf("Contains = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+
+	// 时区：UTC 15:00 为上海 23:00
+	q := &QuietHours{Start: "22:00", End: "08:00", Timezone: "Asia/Shanghai"}
+	if _, err := time.LoadLocation(q.Timezone); err == nil {
+		if got, err := q.Contains(at(15, 0)); err != nil || !got {
+			t.Errorf("Contains = %v, %v, expected quiet hours in Asia/Shanghai", got, err)
+		}
+	}
+}
+
+func TestNoticeRuleDetail(t *testing.T) {
+	rule := &NoticeRuleDetail{Conditions: []*NoticeCondition{
+		{Event: NoticeEventCertExpiring, ExpireDays: 30},
+		{Event: NoticeEventTrustChanged},
+	}}
+	if rule.Condition(NoticeEventDeployFailed) != nil {
+		t.Error("unexpected condition")
+	}
+	for days, want := range map[int]bool{-1: false, 0: true, 30: true, 31: false} {
+		if got := rule.ShouldNotifyExpiring(days); got != want {
+			t.Errorf("ShouldNotifyExpiring(%d) = %v", days, got)
+		}
+	}
+}
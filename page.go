@@ -0,0 +1,169 @@
+package certm
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultPageSize = 100  // 默认每页数量
+	MaxPageSize     = 1000 // 最大每页数量
+)
+
+// ErrCursorNotAdvanced 主机返回的下一页游标与当前游标相同
+var ErrCursorNotAdvanced = errors.New("certm: pagination cursor did not advance")
+
+// SortOrder 排序方向
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"  // 升序
+	SortDesc SortOrder = "desc" // 降序
+)
+
+// 排序字段
+const (
+	SortByID         = "id"          // ID
+	SortByCommonName = "common_name" // 通用名称
+	SortByNotAfter   = "not_after"   // 过期时间，仅证书资产
+)
+
+// ListOptions 列表查询选项，零值字段表示不限制
+type ListOptions struct {
+	Page     int    `json:"page,omitempty"`      // 页码，从1开始
+	PageSize int    `json:"page_size,omitempty"` // 每页数量，默认100，最大1000
+	Cursor   string `json:"cursor,omitempty"`    // 游标，设置后忽略 Page
+
+	Status       string    `json:"status,omitempty"`       // 状态，仅证书容器
+	KeyAlgo      string    `json:"key_algo,omitempty"`     // 密钥算法，仅证书容器
+	ExpireBefore time.Time `json:"expire_before,omitzero"` // 过期时间早于，仅证书资产
+	ExpireAfter  time.Time `json:"expire_after,omitzero"`  // 过期时间晚于，仅证书资产
+	Search       string    `json:"search,omitempty"`       // 通用名称模糊搜索
+	SortBy       string    `json:"sort_by,omitempty"`      // 排序字段，默认 id
+	Order        SortOrder `json:"order,omitempty"`        // 排序方向，默认 asc
+}
+
+// normalize 返回填充默认值后的副本
+func (o *ListOptions) normalize() *ListOptions {
+	opts := ListOptions{}
+	if o != nil {
+		opts = *o
+	}
+	if opts.Page < 1 {
+		opts.Page = 1
+	}
+	if opts.PageSize <= 0 {
+		opts.PageSize = DefaultPageSize
+	}
+	opts.PageSize = min(opts.PageSize, MaxPageSize)
+	return &opts
+}
+
+// Page 分页结果
+type Page[T any] struct {
+	Items      []T    `json:"items"`                 // 当前页数据
+	Total      int    `json:"total"`                 // 满足条件的总数，游标分页时可能为 -1 表示未知
+	Page       int    `json:"page"`                  // 当前页码
+	PageSize   int    `json:"page_size"`             // 每页数量
+	NextCursor string `json:"next_cursor,omitempty"` // 下一页游标，为空时按页码翻页
+}
+
+// HasMore 判断是否还有下一页
+func (p *Page[T]) HasMore() bool {
+	if p.NextCursor != "" {
+		return true
+	}
+	if len(p.Items) == 0 || p.Total < 0 {
+		return false
+	}
+	return p.Page*p.PageSize < p.Total
+}
+
+// Iterator 分页迭代器，按需逐页请求
+//
+//	it := certm.IterCertAssetsOfContainer(dataAccess, projectID, containerID, nil)
+//	for it.Next() {
+//		asset := it.Value()
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type Iterator[T any] struct {
+	fetch func(opts *ListOptions) (*Page[T], error)
+	opts  *ListOptions
+	page  *Page[T]
+	index int
+	err   error
+	done  bool
+}
+
+// NewIterator 创建分页迭代器，fetch 按选项请求一页数据
+func NewIterator[T any](opts *ListOptions, fetch func(opts *ListOptions) (*Page[T], error)) *Iterator[T] {
+	return &Iterator[T]{fetch: fetch, opts: opts.normalize()}
+}
+
+// Next 前进到下一条数据，没有更多数据或出错时返回 false
+func (it *Iterator[T]) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	for it.page == nil || it.index >= len(it.page.Items) {
+		if it.done {
+			return false
+		}
+		if it.page != nil {
+			// 使用游标翻页后游标为空即为最后一页，不再回退到页码翻页，避免重复请求同一页
+			if !it.page.HasMore() || it.page.NextCursor == "" && it.opts.Cursor != "" {
+				it.done = true
+				return false
+			}
+			if it.page.NextCursor != "" {
+				// 主机返回的游标未变化时停止，避免无限循环
+				if it.page.NextCursor == it.opts.Cursor {
+					it.err = fmt.Errorf("%w: %q", ErrCursorNotAdvanced, it.page.NextCursor)
+					return false
+				}
+				it.opts.Cursor = it.page.NextCursor
+			} else {
+				it.opts.Page++
+			}
+		}
+		page, err := it.fetch(it.opts)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.page, it.index = page, 0
+		if len(page.Items) == 0 {
+			it.done = true
+			return false
+		}
+	}
+	it.index++
+	return true
+}
+
+// Value 返回当前数据
+func (it *Iterator[T]) Value() T {
+	return it.page.Items[it.index-1]
+}
+
+// Err 返回迭代过程中的错误
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// IterCertContainers 遍历项目下的证书容器
+func IterCertContainers(da DataAccess, projectID int, opts *ListOptions) *Iterator[*CertContainerInfo] {
+	return NewIterator(opts, func(opts *ListOptions) (*Page[*CertContainerInfo], error) {
+		return da.ListCertContainers(projectID, opts)
+	})
+}
+
+// IterCertAssetsOfContainer 遍历证书容器下的证书资产
+func IterCertAssetsOfContainer(da DataAccess, projectID, containerID int, opts *ListOptions) *Iterator[*CertAssetInfo] {
+	return NewIterator(opts, func(opts *ListOptions) (*Page[*CertAssetInfo], error) {
+		return da.ListCertAssetsOfContainer(projectID, containerID, opts)
+	})
+}
@@ -0,0 +1,113 @@
+package certm
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestListOptionsNormalize(t *testing.T) {
+	var nilOpts *ListOptions
+	opts := nilOpts.normalize()
+	if opts.Page != 1 || opts.PageSize != DefaultPageSize {
+		t.Errorf("normalize(nil) = %+v", opts)
+	}
+
+	orig := &ListOptions{PageSize: 5000, Search: "example"}
+	opts = orig.normalize()
+	if opts.PageSize != MaxPageSize || opts.Search != "example" || orig.PageSize != 5000 {
+		t.Errorf("normalize = %+v, orig = %+v", opts, orig)
+	}
+
+	data, _ := json.Marshal(&ListOptions{Page: 2})
+	if string(data) != `{"page":2}` {
+		t.Errorf("json = %s", data)
+	}
+}
+
+func TestIterator(t *testing.T) {
+	items := make([]int, 7)
+	for i := range items {
+		items[i] = i
+	}
+	pageFetch := func(calls *int) func(*ListOptions) (*Page[int], error) {
+		return func(opts *ListOptions) (*Page[int], error) {
+			*calls++
+			start := min((opts.Page-1)*opts.PageSize, len(items))
+			end := min(start+opts.PageSize, len(items))
+			return &Page[int]{Items: items[start:end], Total: len(items), Page: opts.Page, PageSize: opts.PageSize}, nil
+		}
+	}
+	cursorFetch := func(calls *int) func(*ListOptions) (*Page[int], error) {
+		return func(opts *ListOptions) (*Page[int], error) {
+			*calls++
+			start, _ := strconv.Atoi(opts.Cursor)
+			end := min(start+opts.PageSize, len(items))
+			page := &Page[int]{Items: items[start:end], Total: -1, PageSize: opts.PageSize}
+			if end < len(items) {
+				page.NextCursor = strconv.Itoa(end)
+			}
+			return page, nil
+		}
+	}
+	// 主机在游标分页时仍回显页码与总数
+	cursorTotalFetch := func(calls *int) func(*ListOptions) (*Page[int], error) {
+		fetch := cursorFetch(calls)
+		return func(opts *ListOptions) (*Page[int], error) {
+			page, err := fetch(opts)
+			page.Page, page.Total = opts.Page, len(items)
+			return page, err
+		}
+	}
+
+	tests := []struct {
+		name  string
+		fetch func(*int) func(*ListOptions) (*Page[int], error)
+		calls int
+	}{
+		{"page", pageFetch, 3},
+		{"cursor", cursorFetch, 3},
+		{"cursor with total", cursorTotalFetch, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			it := NewIterator(&ListOptions{PageSize: 3}, tt.fetch(&calls))
+			var got []int
+			for it.Next() {
+				got = append(got, it.Value())
+			}
+			if it.Err() != nil {
+				t.Fatal(it.Err())
+			}
+			if len(got) != len(items) || got[6] != 6 {
+				t.Errorf("got %v", got)
+			}
+			if calls != tt.calls {
+				t.Errorf("calls = %d, want %d", calls, tt.calls)
+			}
+		})
+	}
+
+	// 游标不变时返回错误而非无限循环
+	calls := 0
+	it := NewIterator(nil, func(*ListOptions) (*Page[int], error) {
+		calls++
+		return &Page[int]{Items: []int{1}, Total: -1, NextCursor: "same"}, nil
+	})
+	for it.Next() {
+		if calls > 10 {
+			t.Fatal("iterator did not stop")
+		}
+	}
+	if !errors.Is(it.Err(), ErrCursorNotAdvanced) || calls != 2 {
+		t.Errorf("err = %v, calls = %d", it.Err(), calls)
+	}
+
+	errFetch := errors.New("host unavailable")
+	it = NewIterator(nil, func(*ListOptions) (*Page[int], error) { return nil, errFetch })
+	if it.Next() || !errors.Is(it.Err(), errFetch) {
+		t.Errorf("err = %v", it.Err())
+	}
+}
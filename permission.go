@@ -0,0 +1,114 @@
+package certm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrPermissionDenied 插件未声明所需权限
+var ErrPermissionDenied = errors.New("permission denied")
+
+// ErrCodePermissionDenied 主机返回权限错误时使用的错误码，如 {"error": "...", "code": "permission_denied"}
+const ErrCodePermissionDenied = "permission_denied"
+
+// Permission 插件权限，在 plugin.yml 的 permissions 中声明
+type Permission string
+
+const (
+	PermissionContainerCreate Permission = "cert_container:create" // 创建证书容器
+	PermissionAssetImport     Permission = "cert_asset:import"     // 导入证书资产
+	PermissionAssetRevoke     Permission = "cert_asset:revoke"     // 标记证书资产已吊销
+	PermissionLabelWrite      Permission = "label:write"           // 添加标签
+	PermissionCrossProject    Permission = "project:cross"         // 访问调用上下文以外的项目
+)
+
+// Permissions 全部已知权限
+var Permissions = []Permission{
+	PermissionContainerCreate,
+	PermissionAssetImport,
+	PermissionAssetRevoke,
+	PermissionLabelWrite,
+	PermissionCrossProject,
+}
+
+// hostFuncPermissions 主机函数所需权限，未列出的函数无需声明权限
+var hostFuncPermissions = map[string]Permission{
+	"db_create_cert_container": PermissionContainerCreate,
+	"db_import_cert_asset":     PermissionAssetImport,
+	"db_revoke_cert_asset":     PermissionAssetRevoke,
+	"db_attach_labels":         PermissionLabelWrite,
+}
+
+// RequiredPermission 返回主机函数所需权限
+func RequiredPermission(fn string) (Permission, bool) {
+	perm, ok := hostFuncPermissions[fn]
+	return perm, ok
+}
+
+// PermissionError 权限错误
+type PermissionError struct {
+	PluginID   string     // 插件ID
+	Func       string     // 主机函数
+	Permission Permission // 缺少的权限
+}
+
+// Error 实现 error 接口
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("plugin %s: %s requires permission %q", e.PluginID, e.Func, e.Permission)
+}
+
+// Unwrap 支持 errors.Is(err, ErrPermissionDenied)
+func (e *PermissionError) Unwrap() error {
+	return ErrPermissionDenied
+}
+
+// HasPermission 判断插件是否声明了权限
+func (p *PluginYaml) HasPermission(perm Permission) bool {
+	return slices.Contains(p.Permissions, perm)
+}
+
+// ValidatePermissions 校验声明的权限均为已知权限
+func (p *PluginYaml) ValidatePermissions() error {
+	for _, perm := range p.Permissions {
+		if !slices.Contains(Permissions, perm) {
+			return fmt.Errorf("unknown permission: %s", perm)
+		}
+	}
+	return nil
+}
+
+// CheckHostCall 主机侧在执行主机函数前校验插件是否声明了所需权限
+func (p *PluginYaml) CheckHostCall(fn string) error {
+	perm, ok := RequiredPermission(fn)
+	if !ok || p.HasPermission(perm) {
+		return nil
+	}
+	return &PermissionError{PluginID: p.ID, Func: fn, Permission: perm}
+}
+
+// projectScopedFuncPrefix 首个参数为项目ID的主机函数前缀
+const projectScopedFuncPrefix = "db_"
+
+// CheckProjectScope 主机侧校验数据访问函数的项目ID与调用上下文一致
+//
+// args 为主机函数的JSON参数数组，首个参数为项目ID；声明 project:cross 权限时不校验。
+func (p *PluginYaml) CheckProjectScope(fn string, contextProjectID int, args []byte) error {
+	if !strings.HasPrefix(fn, projectScopedFuncPrefix) || p.HasPermission(PermissionCrossProject) {
+		return nil
+	}
+	var params []json.RawMessage
+	if err := json.Unmarshal(args, &params); err != nil {
+		return fmt.Errorf("%s: invalid args: %w", fn, err)
+	}
+	var projectID int
+	if len(params) == 0 || json.Unmarshal(params[0], &projectID) != nil {
+		return fmt.Errorf("%s: missing project id", fn)
+	}
+	if projectID != contextProjectID {
+		return &PermissionError{PluginID: p.ID, Func: fn, Permission: PermissionCrossProject}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package certm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPluginYamlCheckHostCall(t *testing.T) {
+	p := &PluginYaml{ID: "discovery", Permissions: []Permission{PermissionAssetImport}}
+	if err := p.ValidatePermissions(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		fn   string
+		want bool
+	}{
+		{"db_get_cert_container_list", true},
+		{"db_import_cert_asset", true},
+		{"db_create_cert_container", false},
+		{"db_attach_labels", false},
+	}
+	for _, tt := range tests {
+		err := p.CheckHostCall(tt.fn)
+		if (err == nil) != tt.want {
+			t.Errorf("CheckHostCall(%s) = %v", tt.fn, err)
+		}
+		if err != nil && !errors.Is(err, ErrPermissionDenied) {
+			t.Errorf("CheckHostCall(%s) should wrap ErrPermissionDenied", tt.fn)
+		}
+	}
+
+	p.Permissions = append(p.Permissions, "cert_asset:delete")
+	if err := p.ValidatePermissions(); err == nil {
+		t.Error("expected unknown permission error")
+	}
+}
+
+func TestPluginYamlCheckProjectScope(t *testing.T) {
+	p := &PluginYaml{ID: "checker"}
+	tests := []struct {
+		fn   string
+		args string
+		ok   bool
+	}{
+		{"db_get_cert_container_list", `[1]`, true},
+		{"db_get_cert_asset_detail", `[2,10]`, false},
+		{"db_get_cert_asset_detail", `[]`, false},
+		{"http_request", `[{"url":"https://example.com"}]`, true},
+	}
+	for _, tt := range tests {
+		err := p.CheckProjectScope(tt.fn, 1, []byte(tt.args))
+		if (err == nil) != tt.ok {
+			t.Errorf("CheckProjectScope(%s, %s) = %v", tt.fn, tt.args, err)
+		}
+	}
+	if err := p.CheckProjectScope("db_get_cert_asset_detail", 1, []byte(`[2,10]`)); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("cross project err = %v", err)
+	}
+
+	p.Permissions = []Permission{PermissionCrossProject}
+	if err := p.CheckProjectScope("db_get_cert_asset_detail", 1, []byte(`[2,10]`)); err != nil {
+		t.Errorf("granted cross project err = %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package probe
+
+import (
+	"crypto/tls"
+	"time"
+
+	certm "github.com/trustasia-com/certm-plugin-sdk"
+	"github.com/trustasia-com/certm-plugin-sdk/certutil"
+)
+
+// LocalProber 使用本地网络实现 certm.TLSProber，用于测试及非WASM环境
+type LocalProber struct {
+	Dialer Dialer // 为空时使用 net.Dialer
+}
+
+// TLSProbe 完成协议升级及TLS握手，返回对端证书链
+//
+// 请求中的密码套件仅提供 crypto/tls 实现的部分，不含任何已实现套件时握手失败。
+func (p *LocalProber) TLSProbe(req *certm.TLSProbeRequest) (*certm.TLSProbeResponse, error) {
+	result, err := Probe(req.Address, &Options{
+		Protocol:   Protocol(req.Protocol),
+		ServerName: req.ServerName,
+		Dialer:     p.Dialer,
+		Timeout:    time.Duration(req.Timeout) * time.Second,
+		TLSConfig: &tls.Config{
+			MinVersion:   req.MinVersion,
+			MaxVersion:   req.MaxVersion,
+			CipherSuites: req.CipherSuites,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.TLSProbeResponse(), nil
+}
+
+// TLSProbeResponse 转换为主机TLS探测结果格式
+func (r *Result) TLSProbeResponse() *certm.TLSProbeResponse {
+	resp := &certm.TLSProbeResponse{
+		RemoteAddr:   r.RemoteAddr,
+		OCSPResponse: r.State.OCSPResponse,
+		TLSVersion:   tls.VersionName(r.State.Version),
+		CipherSuite:  tls.CipherSuiteName(r.State.CipherSuite),
+		ResponseTime: r.ResponseTimeMillis(),
+	}
+	for _, cert := range r.State.PeerCertificates {
+		resp.ChainPEM = append(resp.ChainPEM, certutil.EncodeCertificatePEM(cert))
+	}
+	return resp
+}
@@ -0,0 +1,226 @@
+// Package probe 提供检测组件的TLS握手探测，支持直接TLS及STARTTLS类协议
+package probe
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Protocol 端点协议
+type Protocol string
+
+const (
+	ProtocolTLS        Protocol = "tls"      // 直接TLS，如HTTPS/SMTPS/IMAPS
+	ProtocolSMTP       Protocol = "smtp"     // SMTP STARTTLS
+	ProtocolIMAP       Protocol = "imap"     // IMAP STARTTLS
+	ProtocolPOP3       Protocol = "pop3"     // POP3 STLS
+	ProtocolFTP        Protocol = "ftp"      // FTP AUTH TLS
+	ProtocolLDAP       Protocol = "ldap"     // LDAP StartTLS扩展操作
+	ProtocolPostgreSQL Protocol = "postgres" // PostgreSQL SSLRequest
+	ProtocolMySQL      Protocol = "mysql"    // MySQL SSLRequest
+)
+
+// defaultPorts 各协议默认端口
+var defaultPorts = map[Protocol]int{
+	ProtocolTLS:        443,
+	ProtocolSMTP:       25,
+	ProtocolIMAP:       143,
+	ProtocolPOP3:       110,
+	ProtocolFTP:        21,
+	ProtocolLDAP:       389,
+	ProtocolPostgreSQL: 5432,
+	ProtocolMySQL:      3306,
+}
+
+// protocolAliases URL scheme 别名
+var protocolAliases = map[string]Protocol{
+	"https":      ProtocolTLS,
+	"postgresql": ProtocolPostgreSQL,
+}
+
+// DefaultPort 返回协议默认端口，未知协议返回 0
+func DefaultPort(p Protocol) int {
+	return defaultPorts[p]
+}
+
+// ParseProtocol 解析协议名称，空字符串视为 ProtocolTLS
+func ParseProtocol(s string) (Protocol, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return ProtocolTLS, nil
+	}
+	if p, ok := protocolAliases[s]; ok {
+		return p, nil
+	}
+	if _, ok := defaultPorts[Protocol(s)]; !ok {
+		return "", fmt.Errorf("probe: unsupported protocol %q", s)
+	}
+	return Protocol(s), nil
+}
+
+// Endpoint 解析后的检测端点
+type Endpoint struct {
+	Protocol Protocol // 协议
+	Host     string   // 主机名或IP
+	Port     int      // 端口
+}
+
+// Address 返回 host:port
+func (e *Endpoint) Address() string {
+	return net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
+}
+
+// String 返回 protocol://host:port，直接TLS省略协议
+func (e *Endpoint) String() string {
+	if e.Protocol == ProtocolTLS {
+		return e.Address()
+	}
+	return string(e.Protocol) + "://" + e.Address()
+}
+
+// ParseEndpoint 解析检测端点
+//
+// 支持 example.com、example.com:443 及 smtp://mail.example.com:587 形式，
+// protocol 非空时覆盖地址中的协议，未指定端口时使用协议默认端口。
+func ParseEndpoint(endpoint string, protocol Protocol) (*Endpoint, error) {
+	scheme := ""
+	if i := strings.Index(endpoint, "://"); i >= 0 {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("probe: invalid endpoint %q: %w", endpoint, err)
+		}
+		scheme, endpoint = u.Scheme, u.Host
+	}
+	if protocol == "" {
+		p, err := ParseProtocol(scheme)
+		if err != nil {
+			return nil, err
+		}
+		protocol = p
+	} else if _, ok := defaultPorts[protocol]; !ok {
+		return nil, fmt.Errorf("probe: unsupported protocol %q", protocol)
+	}
+
+	ep := &Endpoint{Protocol: protocol, Host: endpoint, Port: DefaultPort(protocol)}
+	if host, port, err := net.SplitHostPort(endpoint); err == nil {
+		n, err := strconv.Atoi(port)
+		if err != nil || n <= 0 || n > 65535 {
+			return nil, fmt.Errorf("probe: invalid port in %q", endpoint)
+		}
+		ep.Host, ep.Port = host, n
+	}
+	ep.Host = strings.Trim(ep.Host, "[]")
+	if ep.Host == "" {
+		return nil, fmt.Errorf("probe: missing host in %q", endpoint)
+	}
+	return ep, nil
+}
+
+// Dialer 建立TCP连接，WASM中需由主机提供实现
+type Dialer interface {
+	Dial(network, address string) (net.Conn, error)
+}
+
+// Options 探测选项
+type Options struct {
+	// Protocol 协议，为空时从端点地址解析
+	Protocol Protocol
+	// ServerName SNI主机名，默认使用端点主机名
+	ServerName string
+	// Dialer 为空时使用 net.Dialer
+	Dialer Dialer
+	// Timeout 整体超时时间，默认10秒
+	Timeout time.Duration
+	// TLSConfig 基础TLS配置，不校验证书，信任状态由 trust 包评估
+	TLSConfig *tls.Config
+}
+
+// Result 探测结果
+type Result struct {
+	Endpoint     *Endpoint           // 检测端点
+	RemoteAddr   string              // 实际连接地址
+	State        tls.ConnectionState // TLS连接状态，包含对端证书链及OCSP装订响应
+	ResponseTime time.Duration       // 建立连接至握手完成耗时
+}
+
+// ResponseTimeMillis 返回毫秒耗时，对应 CheckEndpointResult.ResponseTime
+func (r *Result) ResponseTimeMillis() int {
+	return int(r.ResponseTime / time.Millisecond)
+}
+
+const defaultTimeout = 10 * time.Second
+
+// Probe 连接端点，完成协议升级及TLS握手后返回连接状态
+func Probe(endpoint string, opts *Options) (*Result, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	ep, err := ParseEndpoint(endpoint, opts.Protocol)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	conn, err := handshake(ep, opts, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return &Result{
+		Endpoint:     ep,
+		RemoteAddr:   conn.RemoteAddr().String(),
+		State:        conn.ConnectionState(),
+		ResponseTime: time.Since(start),
+	}, nil
+}
+
+// handshake 建立连接并完成TLS握手，configure 可调整本次握手的TLS配置
+func handshake(ep *Endpoint, opts *Options, configure func(*tls.Config)) (*tls.Conn, error) {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	dialer := opts.Dialer
+	if dialer == nil {
+		dialer = &net.Dialer{Timeout: timeout}
+	}
+
+	conn, err := dialer.Dial("tcp", ep.Address())
+	if err != nil {
+		return nil, fmt.Errorf("probe: dial %s: %w", ep.Address(), err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	if err := StartTLS(conn, ep.Protocol); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	config := &tls.Config{}
+	if opts.TLSConfig != nil {
+		config = opts.TLSConfig.Clone()
+	}
+	config.InsecureSkipVerify = true
+	config.ServerName = serverName(ep, opts)
+	if configure != nil {
+		configure(config)
+	}
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("probe: tls handshake with %s: %w", ep.Address(), err)
+	}
+	return tlsConn, nil
+}
+
+// serverName 返回SNI主机名，IP端点不发送SNI
+func serverName(ep *Endpoint, opts *Options) string {
+	if opts.ServerName == "" && net.ParseIP(ep.Host) == nil {
+		return ep.Host
+	}
+	return opts.ServerName
+}
@@ -0,0 +1,232 @@
+package probe
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	certm "github.com/trustasia-com/certm-plugin-sdk"
+)
+
+func newTLSCert(t *testing.T) tls.Certificate {
+	t.Helper()
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "mail.example.com"},
+		DNSNames:     []string{"mail.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// fakeServer 在本地监听，完成 dialogue 后（返回 true 时）进行TLS握手
+func fakeServer(t *testing.T, dialogue func(conn net.Conn) bool) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	cert := newTLSCert(t)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		if !dialogue(conn) {
+			return
+		}
+		_ = tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{cert}}).Handshake()
+	}()
+	return ln.Addr().String()
+}
+
+func expectLine(conn net.Conn, want string) bool {
+	line, err := readLine(conn)
+	return err == nil && line == want
+}
+
+func write(conn net.Conn, s string) {
+	_, _ = io.WriteString(conn, s)
+}
+
+func TestProbe_StartTLS(t *testing.T) {
+	tests := []struct {
+		protocol Protocol
+		dialogue func(conn net.Conn) bool
+	}{
+		{ProtocolTLS, func(net.Conn) bool { return true }},
+		{ProtocolSMTP, func(conn net.Conn) bool {
+			write(conn, "220-mail.example.com ESMTP\r\n220 ready\r\n")
+			if !expectLine(conn, "EHLO certm.local") {
+				return false
+			}
+			write(conn, "250-mail.example.com\r\n250-STARTTLS\r\n250 SIZE 1024\r\n")
+			if !expectLine(conn, "STARTTLS") {
+				return false
+			}
+			write(conn, "220 go ahead\r\n")
+			return true
+		}},
+		{ProtocolIMAP, func(conn net.Conn) bool {
+			write(conn, "* OK IMAP ready\r\n")
+			if !expectLine(conn, "a001 STARTTLS") {
+				return false
+			}
+			write(conn, "a001 OK begin TLS\r\n")
+			return true
+		}},
+		{ProtocolPOP3, func(conn net.Conn) bool {
+			write(conn, "+OK POP3 ready\r\n")
+			if !expectLine(conn, "STLS") {
+				return false
+			}
+			write(conn, "+OK begin TLS\r\n")
+			return true
+		}},
+		{ProtocolFTP, func(conn net.Conn) bool {
+			write(conn, "220-welcome\r\n220 ready\r\n")
+			if !expectLine(conn, "AUTH TLS") {
+				return false
+			}
+			write(conn, "234 AUTH TLS ok\r\n")
+			return true
+		}},
+		{ProtocolLDAP, func(conn net.Conn) bool {
+			req, err := readDER(conn)
+			if err != nil || !strings.Contains(string(req), ldapStartTLSOID) {
+				return false
+			}
+			body, _ := asn1.Marshal(ldapExtendedResponse{})
+			var seq asn1.RawValue
+			_, _ = asn1.Unmarshal(body, &seq)
+			resp, _ := asn1.Marshal(struct {
+				MessageID int
+				Response  asn1.RawValue
+			}{1, asn1.RawValue{Class: asn1.ClassApplication, Tag: 24, IsCompound: true, Bytes: seq.Bytes}})
+			_, _ = conn.Write(resp)
+			return true
+		}},
+		{ProtocolPostgreSQL, func(conn net.Conn) bool {
+			req := make([]byte, 8)
+			if _, err := io.ReadFull(conn, req); err != nil {
+				return false
+			}
+			write(conn, "S")
+			return true
+		}},
+		{ProtocolMySQL, func(conn net.Conn) bool {
+			payload := []byte{10}
+			payload = append(payload, "8.0.36\x00"...)
+			payload = append(payload, 1, 0, 0, 0)                // 连接ID
+			payload = append(payload, "abcdefgh"...)             // 挑战数据
+			payload = append(payload, 0)                         // 填充
+			payload = append(payload, 0x00, 0x8a)                // 能力标志: SSL|PROTOCOL_41|SECURE_CONNECTION
+			payload = append(payload, mysqlCharsetUTF8MB4, 2, 0) // 字符集、状态
+			packet := append([]byte{byte(len(payload)), 0, 0, 0}, payload...)
+			_, _ = conn.Write(packet)
+			seq, req, err := readMySQLPacket(conn)
+			return err == nil && seq == 1 && len(req) == 32
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.protocol), func(t *testing.T) {
+			addr := fakeServer(t, tt.dialogue)
+			result, err := Probe(string(tt.protocol)+"://"+addr, &Options{ServerName: "mail.example.com", Timeout: 5 * time.Second})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Endpoint.Protocol != tt.protocol {
+				t.Errorf("Expected protocol %s, got %s", tt.protocol, result.Endpoint.Protocol)
+			}
+			certs := result.State.PeerCertificates
+			if len(certs) != 1 || certs[0].Subject.CommonName != "mail.example.com" {
+				t.Errorf("Unexpected peer certificates %d", len(certs))
+			}
+		})
+	}
+}
+
+func TestProbe_Unsupported(t *testing.T) {
+	addr := fakeServer(t, func(conn net.Conn) bool {
+		write(conn, "220 ready\r\n")
+		_, _ = readLine(conn)
+		write(conn, "250-mail.example.com\r\n250 SIZE 1024\r\n")
+		return false
+	})
+	if _, err := Probe(addr, &Options{Protocol: ProtocolSMTP}); !errors.Is(err, ErrStartTLSUnsupported) {
+		t.Errorf("Expected ErrStartTLSUnsupported, got %v", err)
+	}
+
+	addr = fakeServer(t, func(conn net.Conn) bool {
+		_, _ = io.ReadFull(conn, make([]byte, 8))
+		write(conn, "N")
+		return false
+	})
+	if _, err := Probe("postgresql://"+addr, nil); !errors.Is(err, ErrStartTLSUnsupported) {
+		t.Errorf("Expected ErrStartTLSUnsupported, got %v", err)
+	}
+}
+
+func TestParseEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		protocol Protocol
+		expected string
+	}{
+		{"example.com", "", "example.com:443"},
+		{"example.com:8443", "", "example.com:8443"},
+		{"smtp://mail.example.com", "", "smtp://mail.example.com:25"},
+		{"smtp://mail.example.com:587", "", "smtp://mail.example.com:587"},
+		{"mail.example.com", ProtocolIMAP, "imap://mail.example.com:143"},
+		{"https://[::1]", "", "[::1]:443"},
+		{"postgresql://db.example.com", "", "postgres://db.example.com:5432"},
+	}
+	for _, tt := range tests {
+		ep, err := ParseEndpoint(tt.endpoint, tt.protocol)
+		if err != nil {
+			t.Errorf("ParseEndpoint(%q): %v", tt.endpoint, err)
+			continue
+		}
+		if ep.String() != tt.expected {
+			t.Errorf("ParseEndpoint(%q) = %q, want %q", tt.endpoint, ep.String(), tt.expected)
+		}
+	}
+
+	for _, endpoint := range []string{"gopher://example.com", "example.com:99999", ":443"} {
+		if _, err := ParseEndpoint(endpoint, ""); err == nil {
+			t.Errorf("Expected error for %q", endpoint)
+		}
+	}
+}
+
+func TestLocalProber(t *testing.T) {
+	addr := fakeServer(t, func(net.Conn) bool { return true })
+	prober := &LocalProber{}
+	resp, err := prober.TLSProbe(&certm.TLSProbeRequest{Address: addr, ServerName: "mail.example.com", Timeout: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.ChainPEM) != 1 || resp.TLSVersion != "TLS 1.3" || resp.RemoteAddr != addr {
+		t.Errorf("Unexpected response %+v", resp)
+	}
+}
@@ -0,0 +1,345 @@
+package probe
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	certm "github.com/trustasia-com/certm-plugin-sdk"
+)
+
+// ScanMode 扫描模式，可按位组合
+type ScanMode int
+
+const (
+	ScanVersions     ScanMode = 1 << iota // 枚举支持的协议版本
+	ScanCipherSuites                      // 枚举支持的密码套件
+	ScanHeaders                           // 读取HTTP安全响应头，仅直接TLS
+
+	ScanAll = ScanVersions | ScanCipherSuites | ScanHeaders
+)
+
+// ScanOptions 安全扫描选项
+type ScanOptions struct {
+	Options
+	// Modes 扫描模式，默认 ScanAll
+	Modes ScanMode
+	// Path 读取响应头的请求路径，默认 /
+	Path string
+	// Prober 主机TLS探测能力，设置后全部握手经主机完成，无需本地网络（WASM中使用）；
+	// 此时不读取HTTP响应头，密码套件额外枚举DHE/NULL/EXPORT/匿名套件
+	Prober certm.TLSProber
+}
+
+// hstsMinMaxAge A+评级要求的HSTS最短有效期(180天)
+const hstsMinMaxAge = 180 * 24 * 3600
+
+var tlsVersions = []struct {
+	id   uint16
+	name string
+}{
+	{tls.VersionTLS10, "TLS1.0"},
+	{tls.VersionTLS11, "TLS1.1"},
+	{tls.VersionTLS12, "TLS1.2"},
+	{tls.VersionTLS13, "TLS1.3"},
+}
+
+// cipherSuite 待枚举的密码套件
+type cipherSuite struct {
+	id   uint16
+	name string
+	weak bool
+}
+
+// legacyCipherSuites crypto/tls 未实现的TLS1.2及以下密码套件，仅经主机探测时枚举
+var legacyCipherSuites = []cipherSuite{
+	{0x0033, "TLS_DHE_RSA_WITH_AES_128_CBC_SHA", false},
+	{0x0039, "TLS_DHE_RSA_WITH_AES_256_CBC_SHA", false},
+	{0x0067, "TLS_DHE_RSA_WITH_AES_128_CBC_SHA256", false},
+	{0x006B, "TLS_DHE_RSA_WITH_AES_256_CBC_SHA256", false},
+	{0x009E, "TLS_DHE_RSA_WITH_AES_128_GCM_SHA256", false},
+	{0x009F, "TLS_DHE_RSA_WITH_AES_256_GCM_SHA384", false},
+	{0xCCAA, "TLS_DHE_RSA_WITH_CHACHA20_POLY1305_SHA256", false},
+	{0x0016, "TLS_DHE_RSA_WITH_3DES_EDE_CBC_SHA", true},
+	{0x0015, "TLS_DHE_RSA_WITH_DES_CBC_SHA", true},
+	{0x0009, "TLS_RSA_WITH_DES_CBC_SHA", true},
+	{0x0001, "TLS_RSA_WITH_NULL_MD5", true},
+	{0x0002, "TLS_RSA_WITH_NULL_SHA", true},
+	{0x003B, "TLS_RSA_WITH_NULL_SHA256", true},
+	{0xC006, "TLS_ECDHE_ECDSA_WITH_NULL_SHA", true},
+	{0xC010, "TLS_ECDHE_RSA_WITH_NULL_SHA", true},
+	{0x0003, "TLS_RSA_EXPORT_WITH_RC4_40_MD5", true},
+	{0x0006, "TLS_RSA_EXPORT_WITH_RC2_CBC_40_MD5", true},
+	{0x0008, "TLS_RSA_EXPORT_WITH_DES40_CBC_SHA", true},
+	{0x0014, "TLS_DHE_RSA_EXPORT_WITH_DES40_CBC_SHA", true},
+	{0x0018, "TLS_DH_anon_WITH_RC4_128_MD5", true},
+	{0x001B, "TLS_DH_anon_WITH_3DES_EDE_CBC_SHA", true},
+	{0x0034, "TLS_DH_anon_WITH_AES_128_CBC_SHA", true},
+	{0x003A, "TLS_DH_anon_WITH_AES_256_CBC_SHA", true},
+	{0x00A6, "TLS_DH_anon_WITH_AES_128_GCM_SHA256", true},
+	{0xC018, "TLS_ECDH_anon_WITH_AES_128_CBC_SHA", true},
+	{0xC019, "TLS_ECDH_anon_WITH_AES_256_CBC_SHA", true},
+}
+
+// partialCipherCoverage 本地枚举时的覆盖范围说明
+const partialCipherCoverage = "cipher suite scan is partial: only suites implemented by crypto/tls were offered, " +
+	"DHE, NULL, EXPORT and anonymous suites were not tested"
+
+// securityHeaders 记录的HTTP安全响应头
+var securityHeaders = []string{
+	"Content-Security-Policy",
+	"X-Frame-Options",
+	"X-Content-Type-Options",
+	"Referrer-Policy",
+	"Permissions-Policy",
+}
+
+// Scan 扫描端点的TLS安全配置并评级
+//
+// 每个协议版本与TLS1.2及以下的密码套件各进行一次握手；TLS1.3密码套件不可由客户端限定，
+// 仅记录协商结果。未设置 Prober 时使用本地网络与 crypto/tls，只能枚举其实现的密码套件，
+// 并在 Issues 中注明覆盖不完整。
+func Scan(endpoint string, opts *ScanOptions) (*certm.EndpointSecurity, error) {
+	if opts == nil {
+		opts = &ScanOptions{}
+	}
+	ep, err := ParseEndpoint(endpoint, opts.Protocol)
+	if err != nil {
+		return nil, err
+	}
+	modes := opts.Modes
+	if modes == 0 {
+		modes = ScanAll
+	}
+	if ep.Protocol != ProtocolTLS || opts.Prober != nil {
+		modes &^= ScanHeaders
+	}
+
+	try := localHandshaker(ep, &opts.Options)
+	if opts.Prober != nil {
+		try = hostHandshaker(ep, opts)
+	}
+	// 先以默认配置确认端点可达
+	if _, err := try(0, 0, nil); err != nil {
+		return nil, err
+	}
+
+	suites := cipherSuites(opts.Prober != nil)
+	sec := &certm.EndpointSecurity{}
+	if modes&ScanVersions != 0 {
+		// 旧版本握手需提供全部密码套件，避免因默认套件不含CBC/3DES误判为不支持
+		var all []uint16
+		for _, suite := range suites {
+			all = append(all, suite.id)
+		}
+		for _, v := range tlsVersions {
+			if _, err := try(v.id, v.id, all); err == nil {
+				sec.TLSVersions = append(sec.TLSVersions, v.name)
+			}
+		}
+	}
+	if modes&ScanCipherSuites != 0 {
+		scanCipherSuites(try, suites, sec)
+		if opts.Prober == nil {
+			sec.Issues = append(sec.Issues, partialCipherCoverage)
+		}
+	}
+	if modes&ScanHeaders != 0 {
+		if err := scanHeaders(ep, opts, sec); err != nil {
+			sec.Issues = append(sec.Issues, "unable to read HTTP headers: "+err.Error())
+		}
+	}
+	GradeSecurity(sec, modes)
+	return sec, nil
+}
+
+// handshaker 以指定协议版本与密码套件握手，返回协商的密码套件，零值使用默认配置
+type handshaker func(minVersion, maxVersion uint16, suites []uint16) (string, error)
+
+// localHandshaker 使用本地网络握手
+func localHandshaker(ep *Endpoint, opts *Options) handshaker {
+	return func(minVersion, maxVersion uint16, suites []uint16) (string, error) {
+		conn, err := handshake(ep, opts, func(c *tls.Config) {
+			if minVersion != 0 {
+				c.MinVersion = minVersion
+			}
+			if maxVersion != 0 {
+				c.MaxVersion = maxVersion
+			}
+			if suites != nil {
+				c.CipherSuites = suites
+			}
+		})
+		if err != nil {
+			return "", err
+		}
+		defer conn.Close()
+		return tls.CipherSuiteName(conn.ConnectionState().CipherSuite), nil
+	}
+}
+
+// hostHandshaker 经主机TLS探测握手
+func hostHandshaker(ep *Endpoint, opts *ScanOptions) handshaker {
+	return func(minVersion, maxVersion uint16, suites []uint16) (string, error) {
+		resp, err := opts.Prober.TLSProbe(&certm.TLSProbeRequest{
+			Address:      ep.Address(),
+			ServerName:   serverName(ep, &opts.Options),
+			Protocol:     string(ep.Protocol),
+			Timeout:      int(opts.Timeout / time.Second),
+			MinVersion:   minVersion,
+			MaxVersion:   maxVersion,
+			CipherSuites: suites,
+		})
+		if err != nil {
+			return "", fmt.Errorf("probe: host tls probe %s: %w", ep.Address(), err)
+		}
+		return resp.CipherSuite, nil
+	}
+}
+
+// cipherSuites 返回待枚举的TLS1.2及以下密码套件，legacy 为 true 时包含 crypto/tls 未实现的套件
+func cipherSuites(legacy bool) []cipherSuite {
+	var suites []cipherSuite
+	for _, suite := range tls.CipherSuites() {
+		if supportsBelowTLS13(suite) {
+			suites = append(suites, cipherSuite{suite.ID, suite.Name, false})
+		}
+	}
+	for _, suite := range tls.InsecureCipherSuites() {
+		if supportsBelowTLS13(suite) {
+			suites = append(suites, cipherSuite{suite.ID, suite.Name, true})
+		}
+	}
+	if legacy {
+		suites = append(suites, legacyCipherSuites...)
+	}
+	return suites
+}
+
+// scanCipherSuites 枚举TLS1.2及以下密码套件，并记录TLS1.3协商的套件
+func scanCipherSuites(try handshaker, suites []cipherSuite, sec *certm.EndpointSecurity) {
+	for _, suite := range suites {
+		if _, err := try(tls.VersionTLS10, tls.VersionTLS12, []uint16{suite.id}); err == nil {
+			sec.CipherSuites = append(sec.CipherSuites, suite.name)
+			if suite.weak {
+				sec.WeakCipherSuites = append(sec.WeakCipherSuites, suite.name)
+			}
+		}
+	}
+
+	if name, err := try(tls.VersionTLS13, 0, nil); err == nil {
+		sec.CipherSuites = append(sec.CipherSuites, name)
+	}
+}
+
+func supportsBelowTLS13(suite *tls.CipherSuite) bool {
+	for _, v := range suite.SupportedVersions {
+		if v < tls.VersionTLS13 {
+			return true
+		}
+	}
+	return false
+}
+
+// scanHeaders 发送HTTP/1.1 HEAD请求读取安全响应头
+func scanHeaders(ep *Endpoint, opts *ScanOptions, sec *certm.EndpointSecurity) error {
+	conn, err := handshake(ep, &opts.Options, func(c *tls.Config) { c.NextProtos = []string{"http/1.1"} })
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	path := opts.Path
+	if path == "" {
+		path = "/"
+	}
+	host := opts.ServerName
+	if host == "" {
+		host = ep.Host
+	}
+	req := fmt.Sprintf("HEAD %s HTTP/1.1\r\nHost: %s\r\nUser-Agent: certm-probe\r\nConnection: close\r\n\r\n", path, host)
+	if _, err := io.WriteString(conn, req); err != nil {
+		return err
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), &http.Request{Method: http.MethodHead})
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	sec.HSTS = resp.Header.Get("Strict-Transport-Security")
+	for _, name := range securityHeaders {
+		if value := resp.Header.Get(name); value != "" {
+			if sec.Headers == nil {
+				sec.Headers = make(map[string]string)
+			}
+			sec.Headers[name] = value
+		}
+	}
+	return nil
+}
+
+// grades 评级由高到低
+var grades = []string{"A+", "A", "B", "C", "F"}
+
+// GradeSecurity 根据扫描结果计算评级并记录问题
+//
+// 不支持TLS1.2及以上为 F，支持不安全密码套件最高 C，支持TLS1.0/1.1最高 B，
+// 扫描响应头且HSTS有效期不少于180天时为 A+。未扫描的项目不影响评级。
+func GradeSecurity(sec *certm.EndpointSecurity, modes ScanMode) {
+	grade := 0
+	limit := func(g int, issue string) {
+		if g > grade {
+			grade = g
+		}
+		sec.Issues = append(sec.Issues, issue)
+	}
+
+	if modes&ScanVersions != 0 {
+		modern := false
+		for _, v := range sec.TLSVersions {
+			switch v {
+			case "TLS1.2", "TLS1.3":
+				modern = true
+			case "TLS1.0", "TLS1.1":
+				limit(2, v+" is supported")
+			}
+		}
+		if !modern {
+			limit(4, "TLS1.2 or later is not supported")
+		}
+	}
+	if len(sec.WeakCipherSuites) > 0 {
+		limit(3, "insecure cipher suites are supported: "+strings.Join(sec.WeakCipherSuites, ", "))
+	}
+	if modes&ScanHeaders != 0 {
+		switch maxAge := hstsMaxAge(sec.HSTS); {
+		case sec.HSTS == "":
+			limit(1, "HSTS is not enabled")
+		case maxAge < hstsMinMaxAge:
+			limit(1, fmt.Sprintf("HSTS max-age %d is shorter than %d", maxAge, hstsMinMaxAge))
+		}
+	} else if grade == 0 {
+		grade = 1
+	}
+	sec.Grade = grades[grade]
+}
+
+// hstsMaxAge 解析HSTS响应头中的 max-age
+func hstsMaxAge(hsts string) int {
+	for _, directive := range strings.Split(hsts, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(directive), "=")
+		if ok && strings.EqualFold(name, "max-age") {
+			n, err := strconv.Atoi(strings.Trim(value, `"`))
+			if err == nil {
+				return n
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,149 @@
+package probe
+
+import (
+	"crypto/tls"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	certm "github.com/trustasia-com/certm-plugin-sdk"
+)
+
+func newHTTPSServer(t *testing.T, config *tls.Config, hsts string) string {
+	t.Helper()
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hsts != "" {
+			w.Header().Set("Strict-Transport-Security", hsts)
+		}
+		w.Header().Set("X-Frame-Options", "DENY")
+	}))
+	ts.TLS = config
+	ts.Config.ErrorLog = log.New(io.Discard, "", 0)
+	ts.StartTLS()
+	t.Cleanup(ts.Close)
+	return ts.Listener.Addr().String()
+}
+
+func TestScan_Modern(t *testing.T) {
+	addr := newHTTPSServer(t, &tls.Config{MinVersion: tls.VersionTLS12}, "max-age=31536000; includeSubDomains")
+
+	sec, err := Scan(addr, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(sec.TLSVersions, []string{"TLS1.2", "TLS1.3"}) {
+		t.Errorf("Unexpected TLS versions %v", sec.TLSVersions)
+	}
+	if len(sec.CipherSuites) == 0 || len(sec.WeakCipherSuites) != 0 {
+		t.Errorf("Unexpected cipher suites %v (weak %v)", sec.CipherSuites, sec.WeakCipherSuites)
+	}
+	if sec.Headers["X-Frame-Options"] != "DENY" {
+		t.Errorf("Expected X-Frame-Options header, got %v", sec.Headers)
+	}
+	if sec.Grade != "A+" {
+		t.Errorf("Expected A+, got %s (%v)", sec.Grade, sec.Issues)
+	}
+}
+
+func TestScan_Legacy(t *testing.T) {
+	addr := newHTTPSServer(t, &tls.Config{
+		MinVersion:   tls.VersionTLS10,
+		MaxVersion:   tls.VersionTLS12,
+		CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_RSA_WITH_3DES_EDE_CBC_SHA},
+	}, "")
+
+	sec, err := Scan(addr, &ScanOptions{Modes: ScanVersions | ScanCipherSuites})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(sec.TLSVersions, "TLS1.0") || slices.Contains(sec.TLSVersions, "TLS1.3") {
+		t.Errorf("Unexpected TLS versions %v", sec.TLSVersions)
+	}
+	if !slices.Equal(sec.WeakCipherSuites, []string{"TLS_RSA_WITH_3DES_EDE_CBC_SHA"}) {
+		t.Errorf("Unexpected weak cipher suites %v", sec.WeakCipherSuites)
+	}
+	if sec.Grade != "C" || sec.HSTS != "" {
+		t.Errorf("Expected C without headers, got %s (%v)", sec.Grade, sec.Issues)
+	}
+	if !slices.Contains(sec.Issues, partialCipherCoverage) {
+		t.Errorf("Expected partial coverage issue, got %v", sec.Issues)
+	}
+
+	// 经主机探测（LocalProber）与本地扫描结果一致
+	hosted, err := Scan(addr, &ScanOptions{Modes: ScanVersions | ScanCipherSuites, Prober: &LocalProber{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(hosted.TLSVersions, sec.TLSVersions) || !slices.Equal(hosted.WeakCipherSuites, sec.WeakCipherSuites) {
+		t.Errorf("Unexpected host scan %+v", hosted)
+	}
+}
+
+// fakeScanProber 仅接受TLS1.2及指定密码套件
+type fakeScanProber struct {
+	suites   []uint16
+	requests []*certm.TLSProbeRequest
+}
+
+func (f *fakeScanProber) TLSProbe(req *certm.TLSProbeRequest) (*certm.TLSProbeResponse, error) {
+	f.requests = append(f.requests, req)
+	if req.MinVersion > tls.VersionTLS12 || (req.MaxVersion != 0 && req.MaxVersion < tls.VersionTLS12) {
+		return nil, errors.New("protocol version not supported")
+	}
+	if req.CipherSuites == nil {
+		return &certm.TLSProbeResponse{CipherSuite: "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}, nil
+	}
+	for _, id := range req.CipherSuites {
+		if slices.Contains(f.suites, id) {
+			return &certm.TLSProbeResponse{CipherSuite: tls.CipherSuiteName(id)}, nil
+		}
+	}
+	return nil, errors.New("handshake failure")
+}
+
+func TestScan_Prober(t *testing.T) {
+	prober := &fakeScanProber{suites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, 0x0033, 0x0001}}
+	sec, err := Scan("smtp://mail.example.com", &ScanOptions{Prober: prober})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(sec.TLSVersions, []string{"TLS1.2"}) {
+		t.Errorf("Unexpected TLS versions %v", sec.TLSVersions)
+	}
+	if !slices.Contains(sec.CipherSuites, "TLS_DHE_RSA_WITH_AES_128_CBC_SHA") ||
+		!slices.Equal(sec.WeakCipherSuites, []string{"TLS_RSA_WITH_NULL_MD5"}) {
+		t.Errorf("Unexpected cipher suites %v (weak %v)", sec.CipherSuites, sec.WeakCipherSuites)
+	}
+	if slices.Contains(sec.Issues, partialCipherCoverage) || sec.Grade != "C" {
+		t.Errorf("Unexpected grade %s (%v)", sec.Grade, sec.Issues)
+	}
+	if req := prober.requests[0]; req.Address != "mail.example.com:25" || req.ServerName != "mail.example.com" || req.Protocol != "smtp" {
+		t.Errorf("Unexpected request %+v", req)
+	}
+}
+
+func TestGradeSecurity(t *testing.T) {
+	tests := []struct {
+		name     string
+		sec      certm.EndpointSecurity
+		modes    ScanMode
+		expected string
+	}{
+		{"short hsts", certm.EndpointSecurity{TLSVersions: []string{"TLS1.3"}, HSTS: "max-age=300"}, ScanAll, "A"},
+		{"no headers scanned", certm.EndpointSecurity{TLSVersions: []string{"TLS1.2"}}, ScanVersions, "A"},
+		{"tls1.1", certm.EndpointSecurity{TLSVersions: []string{"TLS1.1", "TLS1.2"}}, ScanVersions, "B"},
+		{"legacy only", certm.EndpointSecurity{TLSVersions: []string{"TLS1.0"}}, ScanVersions, "F"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			GradeSecurity(&tt.sec, tt.modes)
+			if tt.sec.Grade != tt.expected {
+				t.Errorf("Expected %s, got %s (%v)", tt.expected, tt.sec.Grade, tt.sec.Issues)
+			}
+		})
+	}
+}
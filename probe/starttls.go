@@ -0,0 +1,363 @@
+package probe
+
+import (
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+)
+
+// ErrStartTLSUnsupported 服务端不支持TLS升级
+var ErrStartTLSUnsupported = errors.New("probe: server does not support STARTTLS")
+
+// StartTLSError 协议升级对话中服务端返回了非预期响应
+type StartTLSError struct {
+	Protocol Protocol
+	Response string
+}
+
+func (e *StartTLSError) Error() string {
+	return fmt.Sprintf("probe: %s starttls failed: unexpected response %q", e.Protocol, e.Response)
+}
+
+// maxLineLength 单行响应最大长度
+const maxLineLength = 4096
+
+// StartTLS 在已建立的连接上完成协议升级对话，返回后即可开始TLS握手
+//
+// 逐字节读取响应，不会读取服务端握手数据；ProtocolTLS 不做任何操作。
+func StartTLS(conn net.Conn, protocol Protocol) error {
+	switch protocol {
+	case ProtocolTLS, "":
+		return nil
+	case ProtocolSMTP:
+		return startSMTP(conn)
+	case ProtocolIMAP:
+		return startIMAP(conn)
+	case ProtocolPOP3:
+		return startPOP3(conn)
+	case ProtocolFTP:
+		return startFTP(conn)
+	case ProtocolLDAP:
+		return startLDAP(conn)
+	case ProtocolPostgreSQL:
+		return startPostgreSQL(conn)
+	case ProtocolMySQL:
+		return startMySQL(conn)
+	}
+	return fmt.Errorf("probe: unsupported protocol %q", protocol)
+}
+
+// readLine 读取一行，去除行尾 CRLF
+func readLine(r io.Reader) (string, error) {
+	var line []byte
+	b := make([]byte, 1)
+	for len(line) < maxLineLength {
+		if _, err := io.ReadFull(r, b); err != nil {
+			return "", err
+		}
+		if b[0] == '\n' {
+			return strings.TrimRight(string(line), "\r"), nil
+		}
+		line = append(line, b[0])
+	}
+	return "", errors.New("probe: response line too long")
+}
+
+// readReply 读取 SMTP/FTP 形式的多行应答，返回状态码及全部行
+func readReply(r io.Reader) (string, []string, error) {
+	var lines []string
+	for {
+		line, err := readLine(r)
+		if err != nil {
+			return "", lines, err
+		}
+		lines = append(lines, line)
+		// "250-..." 为续行，"250 ..." 或仅有状态码为末行
+		if len(line) < 4 || line[3] != '-' {
+			if len(line) < 3 {
+				return line, lines, nil
+			}
+			return line[:3], lines, nil
+		}
+	}
+}
+
+func writeLine(w io.Writer, line string) error {
+	_, err := io.WriteString(w, line+"\r\n")
+	return err
+}
+
+func startSMTP(conn net.Conn) error {
+	if code, lines, err := readReply(conn); err != nil {
+		return err
+	} else if code != "220" {
+		return &StartTLSError{Protocol: ProtocolSMTP, Response: strings.Join(lines, "\n")}
+	}
+	if err := writeLine(conn, "EHLO certm.local"); err != nil {
+		return err
+	}
+	code, lines, err := readReply(conn)
+	if err != nil {
+		return err
+	}
+	if code != "250" {
+		return &StartTLSError{Protocol: ProtocolSMTP, Response: strings.Join(lines, "\n")}
+	}
+	supported := false
+	for _, line := range lines {
+		if len(line) > 4 && strings.EqualFold(strings.TrimSpace(line[4:]), "STARTTLS") {
+			supported = true
+		}
+	}
+	if !supported {
+		return ErrStartTLSUnsupported
+	}
+	if err := writeLine(conn, "STARTTLS"); err != nil {
+		return err
+	}
+	if code, lines, err := readReply(conn); err != nil {
+		return err
+	} else if code != "220" {
+		return &StartTLSError{Protocol: ProtocolSMTP, Response: strings.Join(lines, "\n")}
+	}
+	return nil
+}
+
+func startIMAP(conn net.Conn) error {
+	greeting, err := readLine(conn)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(greeting, "* OK") {
+		return &StartTLSError{Protocol: ProtocolIMAP, Response: greeting}
+	}
+	if err := writeLine(conn, "a001 STARTTLS"); err != nil {
+		return err
+	}
+	for {
+		line, err := readLine(conn)
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(line, "* ") {
+			continue
+		}
+		if strings.HasPrefix(line, "a001 OK") {
+			return nil
+		}
+		if strings.HasPrefix(line, "a001 BAD") || strings.HasPrefix(line, "a001 NO") {
+			return ErrStartTLSUnsupported
+		}
+		return &StartTLSError{Protocol: ProtocolIMAP, Response: line}
+	}
+}
+
+func startPOP3(conn net.Conn) error {
+	greeting, err := readLine(conn)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(greeting, "+OK") {
+		return &StartTLSError{Protocol: ProtocolPOP3, Response: greeting}
+	}
+	if err := writeLine(conn, "STLS"); err != nil {
+		return err
+	}
+	line, err := readLine(conn)
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(line, "-ERR") {
+		return ErrStartTLSUnsupported
+	}
+	if !strings.HasPrefix(line, "+OK") {
+		return &StartTLSError{Protocol: ProtocolPOP3, Response: line}
+	}
+	return nil
+}
+
+func startFTP(conn net.Conn) error {
+	if code, lines, err := readReply(conn); err != nil {
+		return err
+	} else if code != "220" {
+		return &StartTLSError{Protocol: ProtocolFTP, Response: strings.Join(lines, "\n")}
+	}
+	if err := writeLine(conn, "AUTH TLS"); err != nil {
+		return err
+	}
+	code, lines, err := readReply(conn)
+	if err != nil {
+		return err
+	}
+	switch {
+	case code == "234":
+		return nil
+	case strings.HasPrefix(code, "5"):
+		return ErrStartTLSUnsupported
+	}
+	return &StartTLSError{Protocol: ProtocolFTP, Response: strings.Join(lines, "\n")}
+}
+
+// ldapStartTLSOID LDAP StartTLS 扩展操作 (RFC 4511)
+const ldapStartTLSOID = "1.3.6.1.4.1.1466.20037"
+
+type ldapExtendedResponse struct {
+	ResultCode        asn1.Enumerated
+	MatchedDN         []byte
+	DiagnosticMessage []byte
+}
+
+func startLDAP(conn net.Conn) error {
+	// LDAPMessage { messageID 1, extendedReq [APPLICATION 23] { requestName [0] OID } }
+	name := asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, Bytes: []byte(ldapStartTLSOID)}
+	nameDER, _ := asn1.Marshal(name)
+	req, _ := asn1.Marshal(struct {
+		MessageID int
+		Request   asn1.RawValue
+	}{
+		MessageID: 1,
+		Request:   asn1.RawValue{Class: asn1.ClassApplication, Tag: 23, IsCompound: true, Bytes: nameDER},
+	})
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+
+	der, err := readDER(conn)
+	if err != nil {
+		return err
+	}
+	var msg struct {
+		MessageID int
+		Response  asn1.RawValue
+	}
+	if _, err := asn1.Unmarshal(der, &msg); err != nil {
+		return fmt.Errorf("probe: parse ldap response: %w", err)
+	}
+	if msg.Response.Class != asn1.ClassApplication || msg.Response.Tag != 24 {
+		return &StartTLSError{Protocol: ProtocolLDAP, Response: fmt.Sprintf("protocolOp %d", msg.Response.Tag)}
+	}
+	// ExtendedResponse 为 [APPLICATION 24] IMPLICIT SEQUENCE，补回 SEQUENCE 标签解析
+	var resp ldapExtendedResponse
+	if _, err := asn1.UnmarshalWithParams(msg.Response.FullBytes, &resp, "application,tag:24"); err != nil {
+		return fmt.Errorf("probe: parse ldap extended response: %w", err)
+	}
+	switch resp.ResultCode {
+	case 0:
+		return nil
+	case 2, 53: // protocolError, unwillingToPerform
+		return ErrStartTLSUnsupported
+	}
+	return &StartTLSError{Protocol: ProtocolLDAP, Response: fmt.Sprintf("resultCode %d: %s", resp.ResultCode, resp.DiagnosticMessage)}
+}
+
+// readDER 读取一个完整的BER/DER元素（仅支持确定长度）
+func readDER(r io.Reader) ([]byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	length := int(header[1])
+	if length&0x80 != 0 {
+		n := length & 0x7f
+		if n == 0 || n > 3 {
+			return nil, errors.New("probe: unsupported ber length")
+		}
+		lenBytes := make([]byte, n)
+		if _, err := io.ReadFull(r, lenBytes); err != nil {
+			return nil, err
+		}
+		header = append(header, lenBytes...)
+		length = 0
+		for _, b := range lenBytes {
+			length = length<<8 | int(b)
+		}
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return append(header, body...), nil
+}
+
+// postgresSSLRequestCode PostgreSQL SSLRequest 协议码
+const postgresSSLRequestCode = 80877103
+
+func startPostgreSQL(conn net.Conn) error {
+	req := make([]byte, 8)
+	binary.BigEndian.PutUint32(req[0:4], 8)
+	binary.BigEndian.PutUint32(req[4:8], postgresSSLRequestCode)
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+	resp := make([]byte, 1)
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		return err
+	}
+	switch resp[0] {
+	case 'S':
+		return nil
+	case 'N':
+		return ErrStartTLSUnsupported
+	}
+	return &StartTLSError{Protocol: ProtocolPostgreSQL, Response: string(resp)}
+}
+
+// MySQL 能力标志
+const (
+	mysqlClientProtocol41       = 0x00000200
+	mysqlClientSSL              = 0x00000800
+	mysqlClientSecureConnection = 0x00008000
+	mysqlMaxPacketSize          = 1<<24 - 1
+	mysqlCharsetUTF8MB4         = 45
+)
+
+// readMySQLPacket 读取一个MySQL协议包，返回序号与负载
+func readMySQLPacket(r io.Reader) (byte, []byte, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+	length := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	return header[3], payload, nil
+}
+
+func startMySQL(conn net.Conn) error {
+	seq, payload, err := readMySQLPacket(conn)
+	if err != nil {
+		return err
+	}
+	if len(payload) == 0 || payload[0] == 0xff {
+		return &StartTLSError{Protocol: ProtocolMySQL, Response: string(payload)}
+	}
+	if payload[0] != 10 {
+		return &StartTLSError{Protocol: ProtocolMySQL, Response: fmt.Sprintf("protocol version %d", payload[0])}
+	}
+	// 协议版本(1) 服务端版本(NUL结尾) 连接ID(4) 挑战数据(8) 填充(1) 能力标志低16位(2)
+	end := strings.IndexByte(string(payload[1:]), 0)
+	if end < 0 || len(payload) < 1+end+1+4+8+1+2 {
+		return &StartTLSError{Protocol: ProtocolMySQL, Response: "truncated handshake"}
+	}
+	offset := 1 + end + 1 + 4 + 8 + 1
+	capabilities := uint32(binary.LittleEndian.Uint16(payload[offset:]))
+	if capabilities&mysqlClientSSL == 0 {
+		return ErrStartTLSUnsupported
+	}
+
+	// SSLRequest: 能力标志(4) 最大包长(4) 字符集(1) 保留(23)
+	req := make([]byte, 4+32)
+	req[0] = 32
+	req[3] = seq + 1
+	binary.LittleEndian.PutUint32(req[4:], mysqlClientSSL|mysqlClientProtocol41|mysqlClientSecureConnection)
+	binary.LittleEndian.PutUint32(req[8:], mysqlMaxPacketSize)
+	req[12] = mysqlCharsetUTF8MB4
+	_, err = conn.Write(req)
+	return err
+}
@@ -0,0 +1,151 @@
+package renewal
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	certm "github.com/trustasia-com/certm-plugin-sdk"
+)
+
+// DefaultARIRetryAfter 响应未提供 Retry-After 时的默认查询间隔
+const DefaultARIRetryAfter = 6 * time.Hour
+
+var (
+	ErrNoAuthorityKeyID = errors.New("renewal: certificate has no authority key identifier") // 证书缺少颁发者密钥标识
+	ErrNoRenewalInfo    = errors.New("renewal: ACME directory has no renewalInfo endpoint")  // CA不支持ARI
+	ErrInvalidWindow    = errors.New("renewal: invalid ARI suggested window")                // 建议窗口无效
+	ErrNoHTTPClient     = errors.New("renewal: http client is required")                     // 缺少HTTP请求能力
+)
+
+// CertID 计算证书的ARI标识：base64url(AKI).base64url(序列号)
+func CertID(cert *x509.Certificate) (string, error) {
+	if len(cert.AuthorityKeyId) == 0 {
+		return "", ErrNoAuthorityKeyID
+	}
+	// 序列号使用DER INTEGER内容字节，最高位为1时补0
+	serial := cert.SerialNumber.Bytes()
+	if len(serial) == 0 || serial[0]&0x80 != 0 {
+		serial = append([]byte{0}, serial...)
+	}
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(cert.AuthorityKeyId) + "." + enc.EncodeToString(serial), nil
+}
+
+// RenewalInfo ARI续期信息
+type RenewalInfo struct {
+	SuggestedWindow Window    `json:"suggestedWindow"`          // 建议的续期窗口
+	ExplanationURL  string    `json:"explanationURL,omitempty"` // CA的说明链接
+	RetryAfter      time.Time `json:"-"`                        // 下次查询时间
+}
+
+// Apply 将建议窗口写入续期计算输入
+func (ri *RenewalInfo) Apply(in *Input) {
+	window := ri.SuggestedWindow
+	in.ARI = &window
+	in.ARIRetryAfter = ri.RetryAfter
+}
+
+// ARIOptions ARI查询选项
+type ARIOptions struct {
+	// HTTPClient 主机HTTP请求能力，通常为 certm.GetHTTPClient(ctx)
+	HTTPClient certm.HTTPClient
+	// DirectoryURL ACME目录地址，用于获取 renewalInfo 端点
+	DirectoryURL string
+	// RenewalInfoURL renewalInfo 端点，已知时不再查询目录
+	RenewalInfoURL string
+	// Now 当前时间，用于计算 RetryAfter，默认 time.Now()
+	Now time.Time
+}
+
+// FetchRenewalInfo 查询证书组件输出的叶子证书的ARI续期信息
+func FetchRenewalInfo(data *certm.CertOutputData, opts *ARIOptions) (*RenewalInfo, error) {
+	leaf, err := data.Leaf()
+	if err != nil {
+		return nil, fmt.Errorf("renewal: %w", err)
+	}
+	id, err := CertID(leaf)
+	if err != nil {
+		return nil, err
+	}
+	return FetchRenewalInfoByID(id, opts)
+}
+
+// FetchRenewalInfoByID 按ARI标识查询续期信息，未提供 HTTPClient 时返回 ErrNoHTTPClient
+func FetchRenewalInfoByID(certID string, opts *ARIOptions) (*RenewalInfo, error) {
+	if opts == nil || opts.HTTPClient == nil {
+		return nil, ErrNoHTTPClient
+	}
+	endpoint := opts.RenewalInfoURL
+	if endpoint == "" {
+		var err error
+		if endpoint, err = renewalInfoURL(opts.HTTPClient, opts.DirectoryURL); err != nil {
+			return nil, err
+		}
+	}
+
+	url := strings.TrimSuffix(endpoint, "/") + "/" + certID
+	resp, err := opts.HTTPClient.HTTPDo(&certm.HTTPRequest{Method: "GET", URL: url})
+	if err != nil {
+		return nil, fmt.Errorf("renewal: fetch renewal info: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("renewal: fetch renewal info %s: unexpected status %d", url, resp.StatusCode)
+	}
+
+	var info RenewalInfo
+	if err := json.Unmarshal(resp.Body, &info); err != nil {
+		return nil, fmt.Errorf("renewal: parse renewal info: %w", err)
+	}
+	if info.SuggestedWindow.Start.IsZero() || !info.SuggestedWindow.End.After(info.SuggestedWindow.Start) {
+		return nil, ErrInvalidWindow
+	}
+
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	info.RetryAfter = retryAfter(resp.Header, now)
+	return &info, nil
+}
+
+// renewalInfoURL 从ACME目录获取 renewalInfo 端点
+func renewalInfoURL(client certm.HTTPClient, directoryURL string) (string, error) {
+	body, err := certm.HTTPGet(client, directoryURL)
+	if err != nil {
+		return "", fmt.Errorf("renewal: fetch ACME directory: %w", err)
+	}
+	var dir struct {
+		RenewalInfo string `json:"renewalInfo"`
+	}
+	if err := json.Unmarshal(body, &dir); err != nil {
+		return "", fmt.Errorf("renewal: parse ACME directory: %w", err)
+	}
+	if dir.RenewalInfo == "" {
+		return "", ErrNoRenewalInfo
+	}
+	return dir.RenewalInfo, nil
+}
+
+// retryAfter 解析 Retry-After 响应头，支持秒数与HTTP日期
+func retryAfter(header map[string]string, now time.Time) time.Time {
+	for name, value := range header {
+		if !strings.EqualFold(name, "Retry-After") {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if secs, err := strconv.Atoi(value); err == nil && secs > 0 {
+			return now.Add(time.Duration(secs) * time.Second)
+		}
+		if t, err := http.ParseTime(value); err == nil && t.After(now) {
+			return t
+		}
+	}
+	return now.Add(DefaultARIRetryAfter)
+}
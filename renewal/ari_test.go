@@ -0,0 +1,155 @@
+package renewal
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	certm "github.com/trustasia-com/certm-plugin-sdk"
+	"github.com/trustasia-com/certm-plugin-sdk/certutil"
+)
+
+type fakeHTTP struct {
+	responses map[string]*certm.HTTPResponse
+}
+
+func (f *fakeHTTP) HTTPDo(req *certm.HTTPRequest) (*certm.HTTPResponse, error) {
+	resp, ok := f.responses[req.URL]
+	if !ok {
+		return &certm.HTTPResponse{StatusCode: 404}, nil
+	}
+	return resp, nil
+}
+
+func TestCertID(t *testing.T) {
+	// draft-ietf-acme-ari 示例
+	aki, _ := hex.DecodeString("69885b6b87464041e1b37b847ba0ae2cde01c8d4")
+	serial, _ := new(big.Int).SetString("87654321", 16)
+	id, err := CertID(&x509.Certificate{AuthorityKeyId: aki, SerialNumber: serial})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "aYhba4dGQEHhs3uEe6CuLN4ByNQ.AIdlQyE"; id != want {
+		t.Errorf("CertID = %s, want %s", id, want)
+	}
+
+	if _, err := CertID(&x509.Certificate{SerialNumber: serial}); !errors.Is(err, ErrNoAuthorityKeyID) {
+		t.Errorf("err = %v, want ErrNoAuthorityKeyID", err)
+	}
+}
+
+func newCertOutput(t *testing.T) (*certm.CertOutputData, string) {
+	t.Helper()
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ca := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		SubjectKeyId:          []byte{1, 2, 3, 4},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(0x1234),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(12 * time.Hour),
+	}, ca, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, _ := x509.ParseCertificate(der)
+	id, err := CertID(leaf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &certm.CertOutputData{ChainPEM: []string{certutil.EncodeCertificatePEM(leaf)}}, id
+}
+
+func TestFetchRenewalInfo(t *testing.T) {
+	data, id := newCertOutput(t)
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	client := &fakeHTTP{responses: map[string]*certm.HTTPResponse{
+		"https://acme.example.com/directory": {
+			StatusCode: 200,
+			Body:       []byte(`{"newOrder":"https://acme.example.com/new-order","renewalInfo":"https://acme.example.com/renewal-info/"}`),
+		},
+		"https://acme.example.com/renewal-info/" + id: {
+			StatusCode: 200,
+			Header:     map[string]string{"retry-after": "3600"},
+			Body: []byte(`{"suggestedWindow":{"start":"2025-03-02T00:00:00Z","end":"2025-03-03T00:00:00Z"},
+				"explanationURL":"https://acme.example.com/incident"}`),
+		},
+	}}
+
+	info, err := FetchRenewalInfo(data, &ARIOptions{
+		HTTPClient:   client,
+		DirectoryURL: "https://acme.example.com/directory",
+		Now:          now,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC); !info.SuggestedWindow.Start.Equal(want) {
+		t.Errorf("Start = %v", info.SuggestedWindow.Start)
+	}
+	if info.ExplanationURL != "https://acme.example.com/incident" {
+		t.Errorf("ExplanationURL = %s", info.ExplanationURL)
+	}
+	if !info.RetryAfter.Equal(now.Add(time.Hour)) {
+		t.Errorf("RetryAfter = %v", info.RetryAfter)
+	}
+
+	in := &Input{NotBefore: now.Add(-60 * day), NotAfter: now.Add(30 * day)}
+	info.Apply(in)
+	plan := Compute(in, nil, now)
+	if plan.Source != SourceARI || plan.Urgency != UrgencyNone {
+		t.Errorf("plan = %+v", plan)
+	}
+	if !plan.NextCheck.Equal(info.RetryAfter) {
+		t.Errorf("NextCheck = %v, want %v", plan.NextCheck, info.RetryAfter)
+	}
+}
+
+func TestFetchRenewalInfoByID_Errors(t *testing.T) {
+	client := &fakeHTTP{responses: map[string]*certm.HTTPResponse{
+		"https://acme.example.com/directory": {StatusCode: 200, Body: []byte(`{"newOrder":"x"}`)},
+		"https://acme.example.com/ri/bad":    {StatusCode: 200, Body: []byte(`{"suggestedWindow":{"start":"2025-03-02T00:00:00Z","end":"2025-03-01T00:00:00Z"}}`)},
+	}}
+
+	_, err := FetchRenewalInfoByID("id", &ARIOptions{HTTPClient: client, DirectoryURL: "https://acme.example.com/directory"})
+	if !errors.Is(err, ErrNoRenewalInfo) {
+		t.Errorf("err = %v, want ErrNoRenewalInfo", err)
+	}
+	_, err = FetchRenewalInfoByID("bad", &ARIOptions{HTTPClient: client, RenewalInfoURL: "https://acme.example.com/ri"})
+	if !errors.Is(err, ErrInvalidWindow) {
+		t.Errorf("err = %v, want ErrInvalidWindow", err)
+	}
+	if _, err = FetchRenewalInfoByID("missing", &ARIOptions{HTTPClient: client, RenewalInfoURL: "https://acme.example.com/ri"}); err == nil {
+		t.Error("expected error for 404")
+	}
+	if _, err = FetchRenewalInfoByID("id", nil); !errors.Is(err, ErrNoHTTPClient) {
+		t.Errorf("err = %v, want ErrNoHTTPClient", err)
+	}
+	if _, err = FetchRenewalInfoByID("id", &ARIOptions{RenewalInfoURL: "https://acme.example.com/ri"}); !errors.Is(err, ErrNoHTTPClient) {
+		t.Errorf("err = %v, want ErrNoHTTPClient", err)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	if got := retryAfter(nil, now); !got.Equal(now.Add(DefaultARIRetryAfter)) {
+		t.Errorf("default = %v", got)
+	}
+	if got := retryAfter(map[string]string{"Retry-After": "Sun, 02 Mar 2025 00:00:00 GMT"}, now); !got.Equal(now.Add(day)) {
+		t.Errorf("http date = %v", got)
+	}
+}
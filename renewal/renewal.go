@@ -0,0 +1,216 @@
+// Package renewal 提供证书续期窗口与过期计划计算，供证书、检测及通知组件统一使用
+package renewal
+
+import (
+	"hash/fnv"
+	"time"
+
+	certm "github.com/trustasia-com/certm-plugin-sdk"
+)
+
+const (
+	day = 24 * time.Hour
+
+	DefaultDays          = 30             // 无法获取生效时间时的默认提前续期天数
+	DefaultPercent       = 100.0 / 3      // 默认在剩余1/3有效期时续期
+	DefaultCriticalDays  = 7              // 默认紧急天数
+	DefaultCheckInterval = 24 * time.Hour // 未到续期时间时的最长检查间隔
+	DefaultRetryInterval = 1 * time.Hour  // 需续期时的重试间隔
+)
+
+// Urgency 续期紧急程度
+type Urgency string
+
+const (
+	UrgencyNone     Urgency = "none"     // 未到续期时间
+	UrgencyDue      Urgency = "due"      // 已到续期时间
+	UrgencyCritical Urgency = "critical" // 即将过期或已超出续期窗口
+	UrgencyExpired  Urgency = "expired"  // 已过期
+)
+
+// Source 续期窗口来源
+type Source string
+
+const (
+	SourceDays    Source = "days"    // 过期前固定天数
+	SourcePercent Source = "percent" // 有效期比例
+	SourceARI     Source = "ari"     // CA通过ARI建议的窗口
+)
+
+// Window 续期窗口，应在 [Start, End) 内完成续期
+type Window struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Policy 续期策略，零值使用默认值
+type Policy struct {
+	// Days 过期前固定天数开始续期
+	Days int
+	// Percent 剩余有效期比例(0-100)开始续期，与 Days 同时设置时取较早者；
+	// 两者均未设置时使用 DefaultPercent，无法获取生效时间时使用 DefaultDays
+	Percent float64
+	// CriticalDays 剩余天数不超过该值时为紧急，默认7天，且不超过续期窗口的一半
+	CriticalDays int
+	// Jitter 续期时间的最大随机延后，避免同批证书同时续期；ARI窗口始终在整个窗口内分散
+	Jitter time.Duration
+	// CheckInterval 未到续期时间时的最长检查间隔，默认24小时
+	CheckInterval time.Duration
+	// RetryInterval 需续期时的重试间隔，默认1小时
+	RetryInterval time.Duration
+}
+
+// Input 续期计算输入
+type Input struct {
+	NotBefore time.Time // 生效时间，为零时无法按比例计算
+	NotAfter  time.Time // 过期时间
+	// Key 证书标识（如SHA1），用于计算稳定的随机延后，使重复计算结果一致
+	Key string
+	// ARI CA建议的续期窗口，设置后优先于本地策略
+	ARI *Window
+	// ARIRetryAfter 下次查询ARI的时间
+	ARIRetryAfter time.Time
+}
+
+// FromCertOutput 从证书组件输出构建输入，可解析证书时使用叶子证书的生效时间
+func FromCertOutput(data *certm.CertOutputData) *Input {
+	in := &Input{NotAfter: data.NotAfter, Key: data.SHA1}
+	if notBefore, err := data.NotBefore(); err == nil {
+		in.NotBefore = notBefore
+	}
+	return in
+}
+
+// FromAsset 从证书资产构建输入
+func FromAsset(asset *certm.CertAssetInfo) *Input {
+	return &Input{NotAfter: asset.NotAfter, Key: asset.SHA1}
+}
+
+// FromDeployOutput 从部署结果构建输入
+func FromDeployOutput(data *certm.DeployOutputData) *Input {
+	return &Input{NotAfter: data.NotAfter, Key: data.SHA1}
+}
+
+// Plan 续期计划
+type Plan struct {
+	Window    Window        `json:"window"`     // 续期窗口
+	Source    Source        `json:"source"`     // 窗口来源
+	RenewAt   time.Time     `json:"renew_at"`   // 含随机延后的续期时间
+	Urgency   Urgency       `json:"urgency"`    // 紧急程度
+	Remaining time.Duration `json:"remaining"`  // 剩余有效期，过期后为负
+	NextCheck time.Time     `json:"next_check"` // 建议的下次检查时间
+}
+
+// ShouldRenew 判断是否应立即续期
+func (p *Plan) ShouldRenew() bool {
+	return p.Urgency != UrgencyNone
+}
+
+// DaysRemaining 返回剩余整天数，过期后为负
+func (p *Plan) DaysRemaining() int {
+	return int(p.Remaining / day)
+}
+
+// Compute 计算续期计划，p 为空时使用默认策略
+func Compute(in *Input, p *Policy, now time.Time) *Plan {
+	if p == nil {
+		p = &Policy{}
+	}
+	plan := &Plan{Remaining: in.NotAfter.Sub(now)}
+
+	lead, source := p.lead(in)
+	start := in.NotAfter.Add(-lead)
+	if !in.NotBefore.IsZero() && start.Before(in.NotBefore) {
+		start = in.NotBefore
+	}
+	critical := time.Duration(p.CriticalDays) * day
+	if critical <= 0 {
+		critical = DefaultCriticalDays * day
+	}
+	critical = min(critical, in.NotAfter.Sub(start)/2)
+
+	if in.ARI != nil && in.ARI.End.After(in.ARI.Start) {
+		plan.Window, plan.Source = *in.ARI, SourceARI
+		plan.RenewAt = spread(in.Key, plan.Window.Start, plan.Window.End.Sub(plan.Window.Start))
+	} else {
+		plan.Window = Window{Start: start, End: in.NotAfter.Add(-critical)}
+		plan.Source = source
+		plan.RenewAt = spread(in.Key, start, min(p.Jitter, plan.Window.End.Sub(start)))
+	}
+
+	switch {
+	case !now.Before(in.NotAfter):
+		plan.Urgency = UrgencyExpired
+	case plan.Remaining <= critical || !now.Before(plan.Window.End):
+		plan.Urgency = UrgencyCritical
+	case !now.Before(plan.RenewAt):
+		plan.Urgency = UrgencyDue
+	default:
+		plan.Urgency = UrgencyNone
+	}
+	plan.NextCheck = p.nextCheck(plan, in, now)
+	return plan
+}
+
+// lead 计算过期前开始续期的提前量
+func (p *Policy) lead(in *Input) (time.Duration, Source) {
+	lifetime := in.NotAfter.Sub(in.NotBefore)
+	canPercent := !in.NotBefore.IsZero() && lifetime > 0
+
+	days, percent := p.Days, p.Percent
+	if days <= 0 && percent <= 0 {
+		if !canPercent {
+			return DefaultDays * day, SourceDays
+		}
+		percent = DefaultPercent
+	}
+
+	var lead time.Duration
+	var source Source
+	if days > 0 {
+		lead, source = time.Duration(days)*day, SourceDays
+	}
+	if percent > 0 && canPercent {
+		if l := time.Duration(float64(lifetime) * min(percent, 100) / 100); l > lead {
+			lead, source = l, SourcePercent
+		}
+	}
+	if lead <= 0 {
+		lead, source = DefaultDays*day, SourceDays
+	}
+	return lead, source
+}
+
+// nextCheck 计算下次检查时间
+func (p *Policy) nextCheck(plan *Plan, in *Input, now time.Time) time.Time {
+	retry := p.RetryInterval
+	if retry <= 0 {
+		retry = DefaultRetryInterval
+	}
+	interval := p.CheckInterval
+	if interval <= 0 {
+		interval = DefaultCheckInterval
+	}
+
+	next := now.Add(retry)
+	if plan.Urgency == UrgencyNone {
+		next = now.Add(interval)
+		if plan.RenewAt.Before(next) {
+			next = plan.RenewAt
+		}
+	}
+	if in.ARI != nil && in.ARIRetryAfter.After(now) && in.ARIRetryAfter.Before(next) {
+		next = in.ARIRetryAfter
+	}
+	return next
+}
+
+// spread 根据 key 在 [start, start+span] 内取稳定的时间点
+func spread(key string, start time.Time, span time.Duration) time.Time {
+	if span <= 0 {
+		return start
+	}
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return start.Add(time.Duration(h.Sum64() % uint64(span)))
+}
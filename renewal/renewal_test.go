@@ -0,0 +1,146 @@
+package renewal
+
+import (
+	"testing"
+	"time"
+
+	certm "github.com/trustasia-com/certm-plugin-sdk"
+)
+
+var issued = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func ninetyDays() *Input {
+	return &Input{NotBefore: issued, NotAfter: issued.Add(90 * day), Key: "abc"}
+}
+
+func TestCompute_DefaultPercent(t *testing.T) {
+	in := ninetyDays()
+	plan := Compute(in, nil, issued.Add(10*day))
+	if plan.Source != SourcePercent {
+		t.Errorf("Source = %s, want percent", plan.Source)
+	}
+	if want := issued.Add(60 * day); !plan.Window.Start.Equal(want) {
+		t.Errorf("Window.Start = %v, want %v", plan.Window.Start, want)
+	}
+	if want := in.NotAfter.Add(-DefaultCriticalDays * day); !plan.Window.End.Equal(want) {
+		t.Errorf("Window.End = %v, want %v", plan.Window.End, want)
+	}
+	if plan.Urgency != UrgencyNone || plan.ShouldRenew() {
+		t.Errorf("Urgency = %s, want none", plan.Urgency)
+	}
+	if plan.DaysRemaining() != 80 {
+		t.Errorf("DaysRemaining = %d, want 80", plan.DaysRemaining())
+	}
+	if want := issued.Add(11 * day); !plan.NextCheck.Equal(want) {
+		t.Errorf("NextCheck = %v, want %v", plan.NextCheck, want)
+	}
+}
+
+func TestCompute_Urgency(t *testing.T) {
+	in := ninetyDays()
+	p := &Policy{Days: 30}
+	tests := []struct {
+		at   time.Duration
+		want Urgency
+	}{
+		{59 * day, UrgencyNone},
+		{60 * day, UrgencyDue},
+		{84 * day, UrgencyCritical},
+		{90 * day, UrgencyExpired},
+	}
+	for _, tt := range tests {
+		now := issued.Add(tt.at)
+		plan := Compute(in, p, now)
+		if plan.Urgency != tt.want {
+			t.Errorf("at day %d: Urgency = %s, want %s", tt.at/day, plan.Urgency, tt.want)
+		}
+		if plan.ShouldRenew() && !plan.NextCheck.Equal(now.Add(DefaultRetryInterval)) {
+			t.Errorf("at day %d: NextCheck = %v", tt.at/day, plan.NextCheck)
+		}
+	}
+
+	// 仅剩一天时 NextCheck 不晚于续期时间
+	plan := Compute(in, p, issued.Add(59*day+12*time.Hour))
+	if !plan.NextCheck.Equal(plan.RenewAt) {
+		t.Errorf("NextCheck = %v, want RenewAt %v", plan.NextCheck, plan.RenewAt)
+	}
+}
+
+func TestCompute_DaysAndPercent(t *testing.T) {
+	in := ninetyDays()
+
+	// 取较早的窗口
+	plan := Compute(in, &Policy{Days: 45, Percent: 10}, issued)
+	if plan.Source != SourceDays || !plan.Window.Start.Equal(issued.Add(45*day)) {
+		t.Errorf("got %s %v", plan.Source, plan.Window.Start)
+	}
+	plan = Compute(in, &Policy{Days: 10, Percent: 50}, issued)
+	if plan.Source != SourcePercent || !plan.Window.Start.Equal(issued.Add(45*day)) {
+		t.Errorf("got %s %v", plan.Source, plan.Window.Start)
+	}
+
+	// 无生效时间时回退到固定天数
+	plan = Compute(&Input{NotAfter: in.NotAfter}, &Policy{Percent: 50}, issued)
+	if plan.Source != SourceDays || !plan.Window.Start.Equal(issued.Add(60*day)) {
+		t.Errorf("got %s %v", plan.Source, plan.Window.Start)
+	}
+
+	// 短期证书：窗口不早于生效时间，紧急天数不超过窗口的一半
+	short := &Input{NotBefore: issued, NotAfter: issued.Add(6 * day)}
+	plan = Compute(short, &Policy{Days: 30}, issued)
+	if !plan.Window.Start.Equal(issued) || !plan.Window.End.Equal(issued.Add(3*day)) {
+		t.Errorf("window = %+v", plan.Window)
+	}
+	if plan.Urgency != UrgencyDue {
+		t.Errorf("Urgency = %s, want due", plan.Urgency)
+	}
+}
+
+func TestCompute_Jitter(t *testing.T) {
+	p := &Policy{Days: 30, Jitter: 48 * time.Hour}
+	a := Compute(&Input{NotBefore: issued, NotAfter: issued.Add(90 * day), Key: "a"}, p, issued)
+	b := Compute(&Input{NotBefore: issued, NotAfter: issued.Add(90 * day), Key: "a"}, p, issued)
+	if !a.RenewAt.Equal(b.RenewAt) {
+		t.Error("RenewAt should be stable for the same key")
+	}
+	if a.RenewAt.Before(a.Window.Start) || a.RenewAt.After(a.Window.Start.Add(p.Jitter)) {
+		t.Errorf("RenewAt %v out of jitter range", a.RenewAt)
+	}
+}
+
+func TestCompute_ARI(t *testing.T) {
+	in := ninetyDays()
+	in.ARI = &Window{Start: issued.Add(20 * day), End: issued.Add(22 * day)}
+	in.ARIRetryAfter = issued.Add(6 * time.Hour)
+
+	plan := Compute(in, &Policy{Days: 30}, issued)
+	if plan.Source != SourceARI || plan.Window != *in.ARI {
+		t.Fatalf("window = %+v (%s)", plan.Window, plan.Source)
+	}
+	if plan.RenewAt.Before(in.ARI.Start) || !plan.RenewAt.Before(in.ARI.End) {
+		t.Errorf("RenewAt %v out of ARI window", plan.RenewAt)
+	}
+	if !plan.NextCheck.Equal(in.ARIRetryAfter) {
+		t.Errorf("NextCheck = %v, want ARI retry after", plan.NextCheck)
+	}
+
+	if plan := Compute(in, nil, issued.Add(23*day)); plan.Urgency != UrgencyCritical {
+		t.Errorf("after ARI window: Urgency = %s, want critical", plan.Urgency)
+	}
+}
+
+func TestFrom(t *testing.T) {
+	notAfter := issued.Add(30 * day)
+	in := FromAsset(&certm.CertAssetInfo{SHA1: "s", NotAfter: notAfter})
+	if in.Key != "s" || !in.NotAfter.Equal(notAfter) {
+		t.Errorf("FromAsset = %+v", in)
+	}
+	in = FromCertOutput(&certm.CertOutputData{SHA1: "s", NotAfter: notAfter})
+	if !in.NotBefore.IsZero() || !in.NotAfter.Equal(notAfter) {
+		t.Errorf("FromCertOutput = %+v", in)
+	}
+	in = FromDeployOutput(&certm.DeployOutputData{SHA1: "s", NotAfter: notAfter})
+	if in.Key != "s" {
+		t.Errorf("FromDeployOutput = %+v", in)
+	}
+}
@@ -0,0 +1,46 @@
+package revocation
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+)
+
+// CRLEntry 证书在CRL中的状态
+type CRLEntry struct {
+	Status     Status    // 吊销状态，仅 StatusGood 或 StatusRevoked
+	ThisUpdate time.Time // CRL生成时间
+	NextUpdate time.Time // CRL下次更新时间
+	RevokedAt  time.Time // 吊销时间
+	Reason     int       // 吊销原因，RFC 5280 CRLReason
+}
+
+// ParseCRL 解析DER或PEM格式的CRL并校验颁发者签名
+func ParseCRL(data []byte, issuer *x509.Certificate) (*x509.RevocationList, error) {
+	if block, _ := pem.Decode(data); block != nil && block.Type == "X509 CRL" {
+		data = block.Bytes
+	}
+	crl, err := x509.ParseRevocationList(data)
+	if err != nil {
+		return nil, fmt.Errorf("crl: parse: %w", err)
+	}
+	if err := crl.CheckSignatureFrom(issuer); err != nil {
+		return nil, fmt.Errorf("crl: bad signature: %w", err)
+	}
+	return crl, nil
+}
+
+// LookupCRL 在CRL中查找证书
+func LookupCRL(crl *x509.RevocationList, cert *x509.Certificate) *CRLEntry {
+	entry := &CRLEntry{Status: StatusGood, ThisUpdate: crl.ThisUpdate, NextUpdate: crl.NextUpdate}
+	for _, revoked := range crl.RevokedCertificateEntries {
+		if revoked.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+			entry.Status = StatusRevoked
+			entry.RevokedAt = revoked.RevocationTime
+			entry.Reason = revoked.ReasonCode
+			break
+		}
+	}
+	return entry
+}
@@ -0,0 +1,285 @@
+package revocation
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+)
+
+var (
+	oidSHA1          = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidSHA256        = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidOCSPBasic     = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 1}
+	signatureAlgoOID = map[string]x509.SignatureAlgorithm{
+		"1.2.840.113549.1.1.5":  x509.SHA1WithRSA,
+		"1.2.840.113549.1.1.11": x509.SHA256WithRSA,
+		"1.2.840.113549.1.1.12": x509.SHA384WithRSA,
+		"1.2.840.113549.1.1.13": x509.SHA512WithRSA,
+		"1.2.840.10045.4.1":     x509.ECDSAWithSHA1,
+		"1.2.840.10045.4.3.2":   x509.ECDSAWithSHA256,
+		"1.2.840.10045.4.3.3":   x509.ECDSAWithSHA384,
+		"1.2.840.10045.4.3.4":   x509.ECDSAWithSHA512,
+		"1.3.101.112":           x509.PureEd25519,
+	}
+)
+
+// OCSP响应状态错误
+var (
+	ErrOCSPMalformed    = errors.New("ocsp: malformed request")                         // 请求格式错误
+	ErrOCSPInternal     = errors.New("ocsp: internal error")                            // 服务端内部错误
+	ErrOCSPTryLater     = errors.New("ocsp: try later")                                 // 稍后重试
+	ErrOCSPSigRequired  = errors.New("ocsp: signature required")                        // 需要签名请求
+	ErrOCSPUnauthorized = errors.New("ocsp: unauthorized")                              // 未授权
+	ErrOCSPNoResponse   = errors.New("ocsp: response does not contain the certificate") // 响应中无对应证书
+)
+
+var ocspStatusErrors = map[asn1.Enumerated]error{
+	1: ErrOCSPMalformed,
+	2: ErrOCSPInternal,
+	3: ErrOCSPTryLater,
+	5: ErrOCSPSigRequired,
+	6: ErrOCSPUnauthorized,
+}
+
+type certID struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	NameHash      []byte
+	IssuerKeyHash []byte
+	SerialNumber  *big.Int
+}
+
+type ocspRequest struct {
+	TBSRequest tbsRequest
+}
+
+type tbsRequest struct {
+	Version     int `asn1:"explicit,tag:0,default:0,optional"`
+	RequestList []singleRequest
+}
+
+type singleRequest struct {
+	Cert certID
+}
+
+type ocspResponse struct {
+	Status   asn1.Enumerated
+	Response responseBytes `asn1:"explicit,tag:0,optional"`
+}
+
+type responseBytes struct {
+	ResponseType asn1.ObjectIdentifier
+	Response     []byte
+}
+
+type basicResponse struct {
+	TBSResponseData    responseData
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          asn1.BitString
+	Certificates       []asn1.RawValue `asn1:"explicit,tag:0,optional"`
+}
+
+type responseData struct {
+	Raw                asn1.RawContent
+	Version            int `asn1:"optional,default:0,explicit,tag:0"`
+	RawResponderID     asn1.RawValue
+	ProducedAt         time.Time `asn1:"generalized"`
+	Responses          []singleResponse
+	ResponseExtensions []pkix.Extension `asn1:"explicit,tag:1,optional"`
+}
+
+type singleResponse struct {
+	CertID           certID
+	Good             asn1.Flag        `asn1:"tag:0,optional"`
+	Revoked          revokedInfo      `asn1:"tag:1,optional"`
+	Unknown          asn1.Flag        `asn1:"tag:2,optional"`
+	ThisUpdate       time.Time        `asn1:"generalized"`
+	NextUpdate       time.Time        `asn1:"generalized,explicit,tag:0,optional"`
+	SingleExtensions []pkix.Extension `asn1:"explicit,tag:1,optional"`
+}
+
+type revokedInfo struct {
+	RevocationTime time.Time       `asn1:"generalized"`
+	Reason         asn1.Enumerated `asn1:"explicit,tag:0,optional"`
+}
+
+// OCSPResponse 单个证书的OCSP响应
+type OCSPResponse struct {
+	Status       Status            // 吊销状态
+	SerialNumber *big.Int          // 证书序列号
+	ProducedAt   time.Time         // 响应生成时间
+	ThisUpdate   time.Time         // 本次更新时间
+	NextUpdate   time.Time         // 下次更新时间，可能为空
+	RevokedAt    time.Time         // 吊销时间
+	Reason       int               // 吊销原因，RFC 5280 CRLReason
+	Responder    *x509.Certificate // 签名证书
+}
+
+// CreateOCSPRequest 创建证书的OCSP请求，使用SHA-1计算CertID
+func CreateOCSPRequest(cert, issuer *x509.Certificate) ([]byte, error) {
+	id, err := newCertID(cert.SerialNumber, issuer, crypto.SHA1)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(ocspRequest{TBSRequest: tbsRequest{RequestList: []singleRequest{{Cert: id}}}})
+}
+
+// ParseOCSPResponse 解析并校验OCSP响应，返回证书对应的状态
+//
+// 响应需由颁发者或颁发者授权的OCSP签名证书签名。响应时效（ThisUpdate、NextUpdate）
+// 及签名证书在当前时间的有效期由调用方检查，Check 会自动检查。
+func ParseOCSPResponse(der []byte, cert, issuer *x509.Certificate) (*OCSPResponse, error) {
+	var resp ocspResponse
+	if rest, err := asn1.Unmarshal(der, &resp); err != nil {
+		return nil, fmt.Errorf("ocsp: parse response: %w", err)
+	} else if len(rest) > 0 {
+		return nil, errors.New("ocsp: trailing data in response")
+	}
+	if resp.Status != 0 {
+		if err, ok := ocspStatusErrors[resp.Status]; ok {
+			return nil, err
+		}
+		return nil, fmt.Errorf("ocsp: unknown response status %d", resp.Status)
+	}
+	if !resp.Response.ResponseType.Equal(oidOCSPBasic) {
+		return nil, errors.New("ocsp: response is not a basic OCSP response")
+	}
+
+	var basic basicResponse
+	if _, err := asn1.Unmarshal(resp.Response.Response, &basic); err != nil {
+		return nil, fmt.Errorf("ocsp: parse basic response: %w", err)
+	}
+	responder, err := ocspResponder(&basic, issuer, basic.TBSResponseData.ProducedAt)
+	if err != nil {
+		return nil, err
+	}
+	algo, ok := signatureAlgoOID[basic.SignatureAlgorithm.Algorithm.String()]
+	if !ok {
+		return nil, fmt.Errorf("ocsp: unsupported signature algorithm %s", basic.SignatureAlgorithm.Algorithm)
+	}
+	if err := responder.CheckSignature(algo, basic.TBSResponseData.Raw, basic.Signature.RightAlign()); err != nil {
+		return nil, fmt.Errorf("ocsp: bad signature: %w", err)
+	}
+
+	for _, single := range basic.TBSResponseData.Responses {
+		if !matchCertID(single.CertID, cert, issuer) {
+			continue
+		}
+		result := &OCSPResponse{
+			SerialNumber: single.CertID.SerialNumber,
+			ProducedAt:   basic.TBSResponseData.ProducedAt,
+			ThisUpdate:   single.ThisUpdate,
+			NextUpdate:   single.NextUpdate,
+			Responder:    responder,
+		}
+		switch {
+		case bool(single.Good):
+			result.Status = StatusGood
+		case bool(single.Unknown):
+			result.Status = StatusUnknown
+		default:
+			result.Status = StatusRevoked
+			result.RevokedAt = single.Revoked.RevocationTime
+			result.Reason = int(single.Revoked.Reason)
+		}
+		return result, nil
+	}
+	return nil, ErrOCSPNoResponse
+}
+
+// ocspResponder 确定响应签名证书：颁发者本身或颁发者签发的OCSP签名证书
+//
+// 授权的OCSP签名证书需在响应生成时处于有效期内。
+func ocspResponder(basic *basicResponse, issuer *x509.Certificate, producedAt time.Time) (*x509.Certificate, error) {
+	if len(basic.Certificates) == 0 {
+		return issuer, nil
+	}
+	responder, err := x509.ParseCertificate(basic.Certificates[0].FullBytes)
+	if err != nil {
+		return nil, fmt.Errorf("ocsp: parse responder certificate: %w", err)
+	}
+	if bytes.Equal(responder.Raw, issuer.Raw) {
+		return issuer, nil
+	}
+	if err := responder.CheckSignatureFrom(issuer); err != nil {
+		return nil, fmt.Errorf("ocsp: responder certificate is not issued by the issuer: %w", err)
+	}
+	if producedAt.Before(responder.NotBefore) || producedAt.After(responder.NotAfter) {
+		return nil, errors.New("ocsp: responder certificate is not valid when the response was produced")
+	}
+	for _, usage := range responder.ExtKeyUsage {
+		if usage == x509.ExtKeyUsageOCSPSigning {
+			return responder, nil
+		}
+	}
+	return nil, errors.New("ocsp: responder certificate lacks OCSP signing usage")
+}
+
+// newCertID 计算CertID
+func newCertID(serial *big.Int, issuer *x509.Certificate, hash crypto.Hash) (certID, error) {
+	var oid asn1.ObjectIdentifier
+	switch hash {
+	case crypto.SHA1:
+		oid = oidSHA1
+	case crypto.SHA256:
+		oid = oidSHA256
+	default:
+		return certID{}, fmt.Errorf("ocsp: unsupported hash %v", hash)
+	}
+	keyBytes, err := issuerKeyBytes(issuer)
+	if err != nil {
+		return certID{}, err
+	}
+	h := hash.New()
+	h.Write(issuer.RawSubject)
+	nameHash := h.Sum(nil)
+	h.Reset()
+	h.Write(keyBytes)
+	return certID{
+		HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oid, Parameters: asn1.NullRawValue},
+		NameHash:      nameHash,
+		IssuerKeyHash: h.Sum(nil),
+		SerialNumber:  serial,
+	}, nil
+}
+
+// matchCertID 判断响应CertID是否对应证书
+func matchCertID(id certID, cert, issuer *x509.Certificate) bool {
+	if id.SerialNumber == nil || id.SerialNumber.Cmp(cert.SerialNumber) != 0 {
+		return false
+	}
+	var hash crypto.Hash
+	switch {
+	case id.HashAlgorithm.Algorithm.Equal(oidSHA1):
+		hash = crypto.SHA1
+	case id.HashAlgorithm.Algorithm.Equal(oidSHA256):
+		hash = crypto.SHA256
+	default:
+		return false
+	}
+	expected, err := newCertID(cert.SerialNumber, issuer, hash)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(id.NameHash, expected.NameHash) && bytes.Equal(id.IssuerKeyHash, expected.IssuerKeyHash)
+}
+
+// issuerKeyBytes 返回颁发者公钥的 subjectPublicKey 位串内容
+func issuerKeyBytes(issuer *x509.Certificate) ([]byte, error) {
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(issuer.RawSubjectPublicKeyInfo, &spki); err != nil {
+		return nil, fmt.Errorf("ocsp: parse issuer public key: %w", err)
+	}
+	return spki.PublicKey.RightAlign(), nil
+}
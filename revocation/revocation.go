@@ -0,0 +1,350 @@
+// Package revocation 提供基于OCSP与CRL的证书吊销检查
+package revocation
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	certm "github.com/trustasia-com/certm-plugin-sdk"
+	"github.com/trustasia-com/certm-plugin-sdk/certutil"
+)
+
+// Status 吊销状态
+type Status string
+
+const (
+	StatusGood    Status = "good"    // 未吊销
+	StatusRevoked Status = "revoked" // 已吊销
+	StatusUnknown Status = "unknown" // 无法确定
+)
+
+// Source 吊销状态来源
+type Source string
+
+const (
+	SourceStapled Source = "stapled" // TLS握手中的OCSP装订响应
+	SourceOCSP    Source = "ocsp"    // OCSP服务
+	SourceCRL     Source = "crl"     // CRL分发点
+)
+
+// ErrNoIssuer 证书链中缺少颁发者证书
+var ErrNoIssuer = errors.New("revocation: issuer certificate is not available")
+
+// Verdict 单个证书的吊销检查结果
+type Verdict struct {
+	Cert       *x509.Certificate `json:"-"`                     // 被检查的证书
+	Depth      int               `json:"depth"`                 // 在证书链中的位置，0 为叶子证书
+	Subject    string            `json:"subject"`               // 证书主题
+	Status     Status            `json:"status"`                // 吊销状态
+	Source     Source            `json:"source,omitempty"`      // 状态来源
+	RevokedAt  time.Time         `json:"revoked_at,omitempty"`  // 吊销时间
+	Reason     int               `json:"reason,omitempty"`      // 吊销原因，RFC 5280 CRLReason
+	ThisUpdate time.Time         `json:"this_update,omitempty"` // 状态更新时间
+	NextUpdate time.Time         `json:"next_update,omitempty"` // 状态下次更新时间
+	Error      string            `json:"error,omitempty"`       // 状态未知时的原因
+}
+
+// Options 吊销检查选项
+type Options struct {
+	// HTTPClient 用于请求OCSP服务及下载CRL，为空时仅使用装订响应与缓存
+	HTTPClient certm.HTTPClient
+	// Cache 检查结果缓存，为空时不缓存
+	Cache *Cache
+	// Issuers 额外的颁发者证书（中间证书、根证书），用于查找链末端证书的颁发者
+	Issuers []*x509.Certificate
+	// Stapled 叶子证书的OCSP装订响应（tls.ConnectionState.OCSPResponse）
+	Stapled []byte
+	// DisableCRL 不使用CRL，仅使用OCSP
+	DisableCRL bool
+	// Now 检查时间，默认当前时间
+	Now time.Time
+}
+
+// Check 检查叶子优先的证书链中每个证书的吊销状态
+//
+// 每个证书以链中下一个证书为颁发者，链末端证书的颁发者依次从 Options.Issuers
+// 及AIA颁发者地址查找，找不到时跳过该证书；自签名的根证书不检查。
+// 依次使用缓存、装订响应（仅叶子证书）、OCSP、CRL，首个确定的结果生效。
+func Check(chain []*x509.Certificate, opts *Options) []*Verdict {
+	if opts == nil {
+		opts = &Options{}
+	}
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	var verdicts []*Verdict
+	for i, cert := range chain {
+		if i > 0 && bytes.Equal(cert.RawIssuer, cert.RawSubject) {
+			break
+		}
+		var issuer *x509.Certificate
+		if i+1 < len(chain) {
+			issuer = chain[i+1]
+		} else if issuer = findIssuer(cert, opts); issuer == nil {
+			continue
+		}
+		v := &Verdict{Cert: cert, Subject: cert.Subject.String(), Status: StatusUnknown}
+		checkOne(v, cert, issuer, i == 0, opts, now)
+		v.Depth = i
+		verdicts = append(verdicts, v)
+	}
+	return verdicts
+}
+
+// findIssuer 从额外颁发者证书及AIA颁发者地址查找颁发者，找不到时返回 nil
+func findIssuer(cert *x509.Certificate, opts *Options) *x509.Certificate {
+	if bytes.Equal(cert.RawIssuer, cert.RawSubject) {
+		return nil
+	}
+	for _, c := range opts.Issuers {
+		if isIssuer(c, cert) {
+			return c
+		}
+	}
+	if opts.HTTPClient == nil {
+		return nil
+	}
+	for _, url := range cert.IssuingCertificateURL {
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			continue
+		}
+		body, err := certm.HTTPGet(opts.HTTPClient, url)
+		if err != nil {
+			continue
+		}
+		if block, _ := pem.Decode(body); block != nil {
+			body = block.Bytes
+		}
+		if c, err := x509.ParseCertificate(body); err == nil && isIssuer(c, cert) {
+			return c
+		}
+	}
+	return nil
+}
+
+// isIssuer 判断 parent 是否为 child 的颁发者
+func isIssuer(parent, child *x509.Certificate) bool {
+	return bytes.Equal(parent.RawSubject, child.RawIssuer) && child.CheckSignatureFrom(parent) == nil
+}
+
+// checkOne 检查单个证书
+func checkOne(v *Verdict, cert, issuer *x509.Certificate, isLeaf bool, opts *Options, now time.Time) {
+	if opts.Cache != nil && opts.Cache.load(cert, v, now) {
+		return
+	}
+
+	var errs []string
+	resolved := func() bool {
+		if v.Status == StatusUnknown {
+			errs = append(errs, string(v.Source)+": responder does not know the certificate")
+			return false
+		}
+		if opts.Cache != nil {
+			opts.Cache.save(v, now)
+		}
+		return true
+	}
+
+	if isLeaf && len(opts.Stapled) > 0 {
+		if err := applyOCSP(v, opts.Stapled, cert, issuer, SourceStapled, now); err != nil {
+			errs = append(errs, "stapled: "+err.Error())
+		} else if resolved() {
+			return
+		}
+	}
+
+	if opts.HTTPClient != nil {
+		for _, url := range cert.OCSPServer {
+			if err := queryOCSP(v, opts.HTTPClient, url, cert, issuer, now); err != nil {
+				errs = append(errs, "ocsp "+url+": "+err.Error())
+				continue
+			}
+			if resolved() {
+				return
+			}
+		}
+
+		if !opts.DisableCRL {
+			for _, url := range cert.CRLDistributionPoints {
+				if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+					continue
+				}
+				if err := fetchCRL(v, opts.HTTPClient, url, cert, issuer, now); err != nil {
+					errs = append(errs, "crl "+url+": "+err.Error())
+					continue
+				}
+				if resolved() {
+					return
+				}
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		errs = append(errs, "no revocation information available")
+	}
+	v.Error = strings.Join(errs, "; ")
+}
+
+// applyOCSP 解析OCSP响应并写入结果
+func applyOCSP(v *Verdict, der []byte, cert, issuer *x509.Certificate, source Source, now time.Time) error {
+	resp, err := ParseOCSPResponse(der, cert, issuer)
+	if err != nil {
+		return err
+	}
+	if resp.ThisUpdate.After(now) {
+		return fmt.Errorf("ocsp: response is not yet valid until %s", resp.ThisUpdate.UTC().Format(time.RFC3339))
+	}
+	if !resp.NextUpdate.IsZero() && now.After(resp.NextUpdate) {
+		return fmt.Errorf("ocsp: response expired at %s", resp.NextUpdate.UTC().Format(time.RFC3339))
+	}
+	if r := resp.Responder; r != issuer && (now.Before(r.NotBefore) || now.After(r.NotAfter)) {
+		return errors.New("ocsp: responder certificate is expired or not yet valid")
+	}
+	v.Status = resp.Status
+	v.Source = source
+	v.ThisUpdate = resp.ThisUpdate
+	v.NextUpdate = resp.NextUpdate
+	v.RevokedAt = resp.RevokedAt
+	v.Reason = resp.Reason
+	return nil
+}
+
+// queryOCSP 请求OCSP服务
+func queryOCSP(v *Verdict, client certm.HTTPClient, url string, cert, issuer *x509.Certificate, now time.Time) error {
+	req, err := CreateOCSPRequest(cert, issuer)
+	if err != nil {
+		return err
+	}
+	resp, err := client.HTTPDo(&certm.HTTPRequest{
+		Method: "POST",
+		URL:    url,
+		Header: map[string]string{"Content-Type": "application/ocsp-request", "Accept": "application/ocsp-response"},
+		Body:   req,
+	})
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return applyOCSP(v, resp.Body, cert, issuer, SourceOCSP, now)
+}
+
+// fetchCRL 下载CRL并查找证书
+func fetchCRL(v *Verdict, client certm.HTTPClient, url string, cert, issuer *x509.Certificate, now time.Time) error {
+	data, err := certm.HTTPGet(client, url)
+	if err != nil {
+		return err
+	}
+	crl, err := ParseCRL(data, issuer)
+	if err != nil {
+		return err
+	}
+	if !crl.NextUpdate.IsZero() && now.After(crl.NextUpdate) {
+		return fmt.Errorf("crl: expired at %s", crl.NextUpdate.UTC().Format(time.RFC3339))
+	}
+	entry := LookupCRL(crl, cert)
+	v.Status = entry.Status
+	v.Source = SourceCRL
+	v.ThisUpdate = entry.ThisUpdate
+	v.NextUpdate = entry.NextUpdate
+	v.RevokedAt = entry.RevokedAt
+	v.Reason = entry.Reason
+	return nil
+}
+
+// TrustReasons 将检查结果转换为端点信任问题
+//
+// 叶子证书吊销为 TrustStatusCertRevoked，CA证书吊销为 TrustStatusCARevoked，
+// 无法确定状态为 TrustStatusOCSPUnknown。
+func TrustReasons(verdicts []*Verdict) []certm.TrustReason {
+	var reasons []certm.TrustReason
+	for _, v := range verdicts {
+		switch v.Status {
+		case StatusRevoked:
+			code := certm.TrustStatusCARevoked
+			if v.Depth == 0 {
+				code = certm.TrustStatusCertRevoked
+			}
+			reasons = append(reasons, certm.NewTrustReason(code,
+				fmt.Sprintf("certificate %q was revoked at %s (%s)", v.Subject, v.RevokedAt.UTC().Format(time.RFC3339), v.Source)))
+		case StatusUnknown:
+			reasons = append(reasons, certm.NewTrustReason(certm.TrustStatusOCSPUnknown,
+				fmt.Sprintf("revocation status of %q is unknown: %s", v.Subject, v.Error)))
+		}
+	}
+	return reasons
+}
+
+const (
+	cacheKeyPrefix  = "revocation:"
+	defaultCacheTTL = time.Hour
+	maxCacheTTL     = 7 * 24 * time.Hour
+)
+
+// Cache 基于插件状态存储的检查结果缓存，有效期至 NextUpdate
+type Cache struct {
+	store certm.StateStore
+	// TTL 结果无 NextUpdate 时的缓存时间，默认1小时
+	TTL time.Duration
+}
+
+// NewCache 创建检查结果缓存
+func NewCache(store certm.StateStore) *Cache {
+	return &Cache{store: store, TTL: defaultCacheTTL}
+}
+
+// cacheKey 以证书SHA256指纹作为缓存键
+func cacheKey(cert *x509.Certificate) string {
+	fp, _ := certutil.Fingerprint(cert, certutil.FingerprintSHA256)
+	return cacheKeyPrefix + fp
+}
+
+// load 读取缓存结果，过期或读取失败时返回 false
+func (c *Cache) load(cert *x509.Certificate, v *Verdict, now time.Time) bool {
+	data, err := c.store.GetState(cacheKey(cert))
+	if err != nil || len(data) == 0 {
+		return false
+	}
+	var cached Verdict
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return false
+	}
+	if !cached.NextUpdate.IsZero() && now.After(cached.NextUpdate) {
+		return false
+	}
+	cached.Cert = cert
+	*v = cached
+	return true
+}
+
+// save 写入确定的结果，写入失败时忽略
+func (c *Cache) save(v *Verdict, now time.Time) {
+	ttl := c.TTL
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+	if !v.NextUpdate.IsZero() {
+		ttl = v.NextUpdate.Sub(now)
+	}
+	if ttl > maxCacheTTL {
+		ttl = maxCacheTTL
+	}
+	if ttl < time.Second {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	_ = c.store.SetState(cacheKey(v.Cert), data, int(ttl/time.Second))
+}
@@ -0,0 +1,247 @@
+package revocation
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	certm "github.com/trustasia-com/certm-plugin-sdk"
+	"github.com/trustasia-com/certm-plugin-sdk/internal/testpki"
+)
+
+type testPKI struct {
+	*testpki.PKI
+	leaf *x509.Certificate
+}
+
+// newTestPKI 生成由根证书直接签发的叶子证书
+func newTestPKI(t *testing.T, ocsp, crl bool) *testPKI {
+	t.Helper()
+	pki := testpki.New(t)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	if ocsp {
+		tmpl.OCSPServer = []string{"http://ocsp.example.com"}
+	}
+	if crl {
+		tmpl.CRLDistributionPoints = []string{"http://crl.example.com/root.crl"}
+	}
+	leaf, _ := testpki.Issue(t, tmpl, pki.Root, pki.RootKey)
+	return &testPKI{PKI: pki, leaf: leaf}
+}
+
+// ocspResponse 构造由 signer 签名的OCSP响应
+func (p *testPKI) ocspResponse(t *testing.T, status Status, signer crypto.Signer) []byte {
+	t.Helper()
+	now := time.Now().UTC().Truncate(time.Second)
+	id, err := newCertID(p.leaf.SerialNumber, p.Root, crypto.SHA1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	single := singleResponse{
+		CertID:     id,
+		ThisUpdate: now.Add(-time.Minute),
+		NextUpdate: now.Add(time.Hour),
+	}
+	switch status {
+	case StatusGood:
+		single.Good = true
+	case StatusRevoked:
+		single.Revoked = revokedInfo{RevocationTime: now.Add(-30 * time.Minute), Reason: 1}
+	default:
+		single.Unknown = true
+	}
+
+	keyHash, _ := asn1.Marshal(id.IssuerKeyHash)
+	tbs, err := asn1.Marshal(responseData{
+		RawResponderID: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 2, IsCompound: true, Bytes: keyHash},
+		ProducedAt:     now,
+		Responses:      []singleResponse{single},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256(tbs)
+	sig, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	basic, err := asn1.Marshal(struct {
+		TBSResponseData    asn1.RawValue
+		SignatureAlgorithm pkix.AlgorithmIdentifier
+		Signature          asn1.BitString
+	}{
+		TBSResponseData:    asn1.RawValue{FullBytes: tbs},
+		SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}},
+		Signature:          asn1.BitString{Bytes: sig, BitLength: len(sig) * 8},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := asn1.Marshal(ocspResponse{Response: responseBytes{ResponseType: oidOCSPBasic, Response: basic}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+type fakeHTTP struct {
+	bodies   map[string][]byte
+	requests int
+}
+
+func (f *fakeHTTP) HTTPDo(req *certm.HTTPRequest) (*certm.HTTPResponse, error) {
+	f.requests++
+	body, ok := f.bodies[req.URL]
+	if !ok {
+		return &certm.HTTPResponse{StatusCode: 404}, nil
+	}
+	return &certm.HTTPResponse{StatusCode: 200, Body: body}, nil
+}
+
+func TestCheck_OCSP(t *testing.T) {
+	pki := newTestPKI(t, true, false)
+	client := &fakeHTTP{bodies: map[string][]byte{
+		"http://ocsp.example.com": pki.ocspResponse(t, StatusGood, pki.RootKey),
+	}}
+	cache := NewCache(certm.NewMemoryStateStore())
+	chain := []*x509.Certificate{pki.leaf, pki.Root}
+
+	verdicts := Check(chain, &Options{HTTPClient: client, Cache: cache})
+	if len(verdicts) != 1 {
+		t.Fatalf("Expected root to be skipped, got %d verdicts", len(verdicts))
+	}
+	if v := verdicts[0]; v.Status != StatusGood || v.Source != SourceOCSP {
+		t.Fatalf("Expected good from ocsp, got %+v", v)
+	}
+	if reasons := TrustReasons(verdicts); len(reasons) != 0 {
+		t.Errorf("Expected no reasons, got %+v", reasons)
+	}
+
+	// 第二次检查命中缓存
+	verdicts = Check(chain, &Options{HTTPClient: client, Cache: cache})
+	if verdicts[0].Status != StatusGood || client.requests != 1 {
+		t.Errorf("Expected cached result, got %+v after %d requests", verdicts[0], client.requests)
+	}
+}
+
+func TestCheck_Stapled(t *testing.T) {
+	pki := newTestPKI(t, false, false)
+	chain := []*x509.Certificate{pki.leaf, pki.Root}
+
+	verdicts := Check(chain, &Options{Stapled: pki.ocspResponse(t, StatusRevoked, pki.RootKey)})
+	v := verdicts[0]
+	if v.Status != StatusRevoked || v.Source != SourceStapled || v.Reason != 1 {
+		t.Fatalf("Expected stapled revocation, got %+v", v)
+	}
+	reasons := TrustReasons(verdicts)
+	if len(reasons) != 1 || reasons[0].Code != certm.TrustStatusCertRevoked {
+		t.Errorf("Unexpected reasons %+v", reasons)
+	}
+
+	// 非颁发者签名的响应被拒绝
+	otherKey := testpki.NewKey(t)
+	verdicts = Check(chain, &Options{Stapled: pki.ocspResponse(t, StatusGood, otherKey)})
+	if verdicts[0].Status != StatusUnknown || verdicts[0].Error == "" {
+		t.Errorf("Expected unknown status for forged response, got %+v", verdicts[0])
+	}
+	reasons = TrustReasons(verdicts)
+	if len(reasons) != 1 || reasons[0].Code != certm.TrustStatusOCSPUnknown {
+		t.Errorf("Unexpected reasons %+v", reasons)
+	}
+}
+
+func TestCheck_Issuers(t *testing.T) {
+	pki := newTestPKI(t, false, false)
+	stapled := pki.ocspResponse(t, StatusGood, pki.RootKey)
+
+	// 找不到颁发者时跳过，而不是报告状态未知
+	if verdicts := Check([]*x509.Certificate{pki.leaf}, &Options{Stapled: stapled}); len(verdicts) != 0 {
+		t.Errorf("Expected leaf without issuer to be skipped, got %+v", verdicts[0])
+	}
+
+	verdicts := Check([]*x509.Certificate{pki.leaf}, &Options{Stapled: stapled, Issuers: []*x509.Certificate{pki.Root}})
+	if len(verdicts) != 1 || verdicts[0].Status != StatusGood || verdicts[0].Depth != 0 {
+		t.Fatalf("Expected good status with issuer from options, got %+v", verdicts)
+	}
+
+	// 响应的 ThisUpdate 晚于检查时间时拒绝
+	verdicts = Check([]*x509.Certificate{pki.leaf, pki.Root}, &Options{Stapled: stapled, Now: time.Now().Add(-time.Hour)})
+	if verdicts[0].Status != StatusUnknown || !strings.Contains(verdicts[0].Error, "not yet valid") {
+		t.Errorf("Expected future response to be rejected, got %+v", verdicts[0])
+	}
+}
+
+func TestOCSPResponder(t *testing.T) {
+	pki := newTestPKI(t, false, false)
+	now := time.Now()
+	responder, _ := testpki.Issue(t, &x509.Certificate{
+		SerialNumber: big.NewInt(7),
+		Subject:      pkix.Name{CommonName: "Test OCSP Responder"},
+		NotBefore:    now.Add(-2 * time.Hour),
+		NotAfter:     now.Add(-time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageOCSPSigning},
+	}, pki.Root, pki.RootKey)
+	basic := &basicResponse{Certificates: []asn1.RawValue{{FullBytes: responder.Raw}}}
+
+	if _, err := ocspResponder(basic, pki.Root, now.Add(-90*time.Minute)); err != nil {
+		t.Errorf("Expected responder valid at produced time, got %v", err)
+	}
+	if _, err := ocspResponder(basic, pki.Root, now); err == nil {
+		t.Error("Expected expired responder to be rejected")
+	}
+}
+
+func TestCheck_CRL(t *testing.T) {
+	pki := newTestPKI(t, false, true)
+	now := time.Now()
+	crl, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: now.Add(-time.Hour),
+		NextUpdate: now.Add(time.Hour),
+		RevokedCertificateEntries: []x509.RevocationListEntry{
+			{SerialNumber: big.NewInt(42), RevocationTime: now.Add(-time.Minute), ReasonCode: 4},
+		},
+	}, pki.Root, pki.RootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &fakeHTTP{bodies: map[string][]byte{"http://crl.example.com/root.crl": crl}}
+
+	verdicts := Check([]*x509.Certificate{pki.leaf, pki.Root}, &Options{HTTPClient: client})
+	v := verdicts[0]
+	if v.Status != StatusRevoked || v.Source != SourceCRL || v.Reason != 4 {
+		t.Fatalf("Expected crl revocation, got %+v", v)
+	}
+
+	verdicts = Check([]*x509.Certificate{pki.leaf, pki.Root}, &Options{HTTPClient: client, DisableCRL: true})
+	if verdicts[0].Status != StatusUnknown {
+		t.Errorf("Expected unknown status with CRL disabled, got %+v", verdicts[0])
+	}
+}
+
+func TestCreateOCSPRequest(t *testing.T) {
+	pki := newTestPKI(t, true, false)
+	der, err := CreateOCSPRequest(pki.leaf, pki.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var req ocspRequest
+	if _, err := asn1.Unmarshal(der, &req); err != nil {
+		t.Fatal(err)
+	}
+	if len(req.TBSRequest.RequestList) != 1 || !matchCertID(req.TBSRequest.RequestList[0].Cert, pki.leaf, pki.Root) {
+		t.Error("Expected request to contain the leaf CertID")
+	}
+}
@@ -0,0 +1,136 @@
+package certm
+
+import "context"
+
+// ScopedData 绑定当前项目的数据访问，方法均使用上下文中的项目ID
+//
+// 跨项目访问需在 plugin.yml 中声明 project:cross 权限并直接使用 DataAccess。
+type ScopedData struct {
+	da        DataAccess
+	projectID int
+}
+
+// Data 获取绑定当前项目的数据访问
+func Data(ctx context.Context) *ScopedData {
+	return &ScopedData{da: GetDataAccess(ctx), projectID: GetProjectID(ctx)}
+}
+
+// ProjectID 返回绑定的项目ID
+func (d *ScopedData) ProjectID() int {
+	return d.projectID
+}
+
+// GetCertContainerList 获取证书容器列表
+func (d *ScopedData) GetCertContainerList() ([]*CertContainerInfo, error) {
+	return d.da.GetCertContainerList(d.projectID)
+}
+
+// GetCertAssetListOfContainer 获取证书资产列表
+func (d *ScopedData) GetCertAssetListOfContainer(containerID int) ([]*CertAssetInfo, error) {
+	return d.da.GetCertAssetListOfContainer(d.projectID, containerID)
+}
+
+// ListCertContainers 分页获取证书容器列表
+func (d *ScopedData) ListCertContainers(opts *ListOptions) (*Page[*CertContainerInfo], error) {
+	return d.da.ListCertContainers(d.projectID, opts)
+}
+
+// ListCertAssetsOfContainer 分页获取证书资产列表
+func (d *ScopedData) ListCertAssetsOfContainer(containerID int, opts *ListOptions) (*Page[*CertAssetInfo], error) {
+	return d.da.ListCertAssetsOfContainer(d.projectID, containerID, opts)
+}
+
+// IterCertContainers 遍历证书容器
+func (d *ScopedData) IterCertContainers(opts *ListOptions) *Iterator[*CertContainerInfo] {
+	return IterCertContainers(d.da, d.projectID, opts)
+}
+
+// IterCertAssetsOfContainer 遍历证书容器下的证书资产
+func (d *ScopedData) IterCertAssetsOfContainer(containerID int, opts *ListOptions) *Iterator[*CertAssetInfo] {
+	return IterCertAssetsOfContainer(d.da, d.projectID, containerID, opts)
+}
+
+// FindCertAssets 按域名、指纹或序列号查找证书资产
+func (d *ScopedData) FindCertAssets(query *CertAssetQuery) ([]*CertAssetInfo, error) {
+	return d.da.FindCertAssets(d.projectID, query)
+}
+
+// GetCertAssetDetail 获取证书资产详情
+func (d *ScopedData) GetCertAssetDetail(assetID int) (*CertAssetDetail, error) {
+	return d.da.GetCertAssetDetail(d.projectID, assetID)
+}
+
+// GetCertAssetHistory 获取证书容器的历史证书资产，按签发时间倒序
+func (d *ScopedData) GetCertAssetHistory(containerID int) ([]*CertAssetHistory, error) {
+	return d.da.GetCertAssetHistory(d.projectID, containerID)
+}
+
+// GetCertAssetsBySHA1 按SHA1列表获取证书资产
+func (d *ScopedData) GetCertAssetsBySHA1(sha1s []string) ([]*CertAssetHistory, error) {
+	return d.da.GetCertAssetsBySHA1(d.projectID, sha1s)
+}
+
+// GetCertOutputHistory 获取证书输出中 HistorySHA1 对应的历史证书资产
+func (d *ScopedData) GetCertOutputHistory(data *CertOutputData) ([]*CertAssetHistory, error) {
+	return d.da.GetCertAssetsBySHA1(d.projectID, data.HistorySHA1)
+}
+
+// CreateCertContainer 创建证书容器
+func (d *ScopedData) CreateCertContainer(req *CreateCertContainerRequest) (*CertContainerInfo, error) {
+	return d.da.CreateCertContainer(d.projectID, req)
+}
+
+// ImportCertAsset 导入证书资产到证书容器
+func (d *ScopedData) ImportCertAsset(containerID int, data *CertOutputData) (*CertAssetInfo, error) {
+	return d.da.ImportCertAsset(d.projectID, containerID, data)
+}
+
+// RevokeCertAsset 标记证书资产已吊销
+func (d *ScopedData) RevokeCertAsset(assetID int, reason string) error {
+	return d.da.RevokeCertAsset(d.projectID, assetID, reason)
+}
+
+// AttachLabels 为证书容器或证书资产添加标签
+func (d *ScopedData) AttachLabels(resource ResourceType, id int, labels map[string]string) error {
+	return d.da.AttachLabels(d.projectID, resource, id, labels)
+}
+
+// GetDeployerList 获取部署器列表
+func (d *ScopedData) GetDeployerList(targetID string) ([]*DeployerInfo, error) {
+	return d.da.GetDeployerList(d.projectID, targetID)
+}
+
+// GetDeployerDetail 获取部署器详情
+func (d *ScopedData) GetDeployerDetail(deployerID int) (*DeployerDetail, error) {
+	return d.da.GetDeployerDetail(d.projectID, deployerID)
+}
+
+// GetCheckTargetList 获取检测目标列表
+func (d *ScopedData) GetCheckTargetList() ([]*CheckTargetInfo, error) {
+	return d.da.GetCheckTargetList(d.projectID)
+}
+
+// GetCheckTargetDetail 获取检测目标详情
+func (d *ScopedData) GetCheckTargetDetail(targetID int) (*CheckTargetDetail, error) {
+	return d.da.GetCheckTargetDetail(d.projectID, targetID)
+}
+
+// GetLatestCheckResults 获取检测目标最近一次的检测结果
+func (d *ScopedData) GetLatestCheckResults(targetID int) ([]*CheckEndpointResult, error) {
+	return d.da.GetLatestCheckResults(d.projectID, targetID)
+}
+
+// GetWorkflowSteps 获取工作流步骤列表
+func (d *ScopedData) GetWorkflowSteps(workflowID int) ([]*WorkflowStepInfo, error) {
+	return d.da.GetWorkflowSteps(d.projectID, workflowID)
+}
+
+// GetNoticeRuleList 获取告警规则列表
+func (d *ScopedData) GetNoticeRuleList() ([]*NoticeRuleInfo, error) {
+	return d.da.GetNoticeRuleList(d.projectID)
+}
+
+// GetNoticeRuleDetail 获取告警规则详情
+func (d *ScopedData) GetNoticeRuleDetail(ruleID int) (*NoticeRuleDetail, error) {
+	return d.da.GetNoticeRuleDetail(d.projectID, ruleID)
+}
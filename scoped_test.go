@@ -0,0 +1,36 @@
+package certm
+
+import (
+	"context"
+	"testing"
+)
+
+type recordingAccess struct {
+	DataAccess
+	projectIDs []int
+}
+
+func (r *recordingAccess) GetCertAssetDetail(projectID, assetID int) (*CertAssetDetail, error) {
+	r.projectIDs = append(r.projectIDs, projectID)
+	return &CertAssetDetail{CertAssetInfo: CertAssetInfo{ID: assetID}}, nil
+}
+
+func (r *recordingAccess) GetNoticeRuleList(projectID int) ([]*NoticeRuleInfo, error) {
+	r.projectIDs = append(r.projectIDs, projectID)
+	return nil, nil
+}
+
+func TestScopedData(t *testing.T) {
+	access := &recordingAccess{}
+	data := Data(SetContextKey(context.Background(), access, "zh-CN", 42))
+	if data.ProjectID() != 42 {
+		t.Errorf("ProjectID = %d", data.ProjectID())
+	}
+	if asset, err := data.GetCertAssetDetail(5); err != nil || asset.ID != 5 {
+		t.Fatalf("asset = %+v, err = %v", asset, err)
+	}
+	_, _ = data.GetNoticeRuleList()
+	if len(access.projectIDs) != 2 || access.projectIDs[0] != 42 || access.projectIDs[1] != 42 {
+		t.Errorf("projectIDs = %v", access.projectIDs)
+	}
+}
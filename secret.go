@@ -0,0 +1,114 @@
+package certm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/trustasia-com/certm-plugin-sdk/helper"
+)
+
+const secretResolverCtxKey contextKey = "secretResolver"
+
+// redacted 密钥在日志及序列化中的占位符
+const redacted = "******"
+
+// ErrSecretNotRef 密钥字段为明文而非引用
+var ErrSecretNotRef = errors.New("secret is not a reference")
+
+// ErrNoSecretResolver 密钥为引用但未提供密钥解析能力
+var ErrNoSecretResolver = errors.New("secret resolver is not available")
+
+// SecretResolver 密钥解析能力，WASM中由主机函数 secret_resolve 实现
+type SecretResolver interface {
+	ResolveSecret(ref string) (string, error)
+}
+
+// GetSecretResolver 获取密钥解析能力
+// nolint:errcheck
+func GetSecretResolver(ctx context.Context) SecretResolver {
+	return ctx.Value(secretResolverCtxKey).(SecretResolver)
+}
+
+// SetSecretResolver 设置密钥解析能力
+func SetSecretResolver(ctx context.Context, resolver SecretResolver) context.Context {
+	return context.WithValue(ctx, secretResolverCtxKey, resolver)
+}
+
+// Secret 密钥字段，对应 helper.FieldTypeSecret
+//
+// 主机保存配置时将明文替换为引用 {"secret_ref": "..."}，插件按需通过 Reveal 获取明文。
+// 打印及序列化时不会输出明文。
+type Secret struct {
+	ref   string
+	plain string
+}
+
+// NewSecretRef 创建密钥引用
+func NewSecretRef(ref string) Secret {
+	return Secret{ref: ref}
+}
+
+// Ref 返回密钥引用，明文密钥返回空
+func (s Secret) Ref() string {
+	return s.ref
+}
+
+// IsRef 判断是否为密钥引用
+func (s Secret) IsRef() bool {
+	return s.ref != ""
+}
+
+// IsZero 判断是否未设置
+func (s Secret) IsZero() bool {
+	return s.ref == "" && s.plain == ""
+}
+
+// Reveal 获取密钥明文，密钥为引用且 resolver 为 nil 时返回 ErrNoSecretResolver
+func (s Secret) Reveal(resolver SecretResolver) (string, error) {
+	if s.ref == "" {
+		return s.plain, nil
+	}
+	if resolver == nil {
+		return "", ErrNoSecretResolver
+	}
+	return resolver.ResolveSecret(s.ref)
+}
+
+// String 实现 Stringer 接口，始终返回占位符
+func (s Secret) String() string {
+	return redacted
+}
+
+// GoString 实现 GoStringer 接口，避免 %#v 输出明文
+func (s Secret) GoString() string {
+	return redacted
+}
+
+// MarshalJSON 仅输出引用，明文密钥输出占位符
+func (s Secret) MarshalJSON() ([]byte, error) {
+	if s.ref != "" {
+		return json.Marshal(map[string]string{helper.SecretRefKey: s.ref})
+	}
+	if s.plain != "" {
+		return json.Marshal(redacted)
+	}
+	return []byte("null"), nil
+}
+
+// UnmarshalJSON 支持引用对象及明文字符串
+func (s *Secret) UnmarshalJSON(data []byte) error {
+	*s = Secret{}
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &s.plain)
+	}
+	var ref map[string]string
+	if err := json.Unmarshal(data, &ref); err != nil {
+		return err
+	}
+	s.ref = ref[helper.SecretRefKey]
+	return nil
+}
@@ -0,0 +1,74 @@
+package certm
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const stateStoreCtxKey contextKey = "stateStore"
+
+// StateStore 插件状态存储，WASM中由主机函数 state_get/state_set 实现，按插件与项目隔离
+type StateStore interface {
+	// GetState 读取状态，不存在或已过期时返回 nil
+	GetState(key string) ([]byte, error)
+	// SetState 写入状态，ttl 为过期时间(秒)，0 表示不过期
+	SetState(key string, value []byte, ttl int) error
+}
+
+// GetStateStore 获取插件状态存储
+// nolint:errcheck
+func GetStateStore(ctx context.Context) StateStore {
+	return ctx.Value(stateStoreCtxKey).(StateStore)
+}
+
+// SetStateStore 设置插件状态存储
+func SetStateStore(ctx context.Context, store StateStore) context.Context {
+	return context.WithValue(ctx, stateStoreCtxKey, store)
+}
+
+// MemoryStateStore 内存状态存储，用于测试及本地运行
+type MemoryStateStore struct {
+	mu    sync.Mutex
+	items map[string]memoryState
+	now   func() time.Time
+}
+
+type memoryState struct {
+	value    []byte
+	expireAt time.Time
+}
+
+// NewMemoryStateStore 创建内存状态存储
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{items: make(map[string]memoryState), now: time.Now}
+}
+
+// GetState 读取状态
+func (m *MemoryStateStore) GetState(key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.items[key]
+	if !ok {
+		return nil, nil
+	}
+	if !item.expireAt.IsZero() && !m.now().Before(item.expireAt) {
+		delete(m.items, key)
+		return nil, nil
+	}
+	return item.value, nil
+}
+
+// SetState 写入状态
+func (m *MemoryStateStore) SetState(key string, value []byte, ttl int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item := memoryState{value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		item.expireAt = m.now().Add(time.Duration(ttl) * time.Second)
+	}
+	m.items[key] = item
+	return nil
+}
@@ -0,0 +1,7 @@
+//go:build !tinygo && !wasm
+// +build !tinygo,!wasm
+
+package trust
+
+// systemRoots 是否可使用系统根证书
+const systemRoots = true
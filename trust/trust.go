@@ -0,0 +1,293 @@
+// Package trust 提供检测组件统一的证书信任状态评估
+package trust
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	certm "github.com/trustasia-com/certm-plugin-sdk"
+	"github.com/trustasia-com/certm-plugin-sdk/certutil"
+	"github.com/trustasia-com/certm-plugin-sdk/hostmatch"
+)
+
+// ErrNoRoots WASM环境中未提供根证书
+var ErrNoRoots = errors.New("trust: roots are required, system roots are not available in WASM")
+
+// Input 评估输入
+type Input struct {
+	// PeerChain 对端返回的证书链，叶子证书在前
+	PeerChain []*x509.Certificate
+	// Hostname 期望的主机名，可带端口
+	Hostname string
+	// Roots 受信任的根证书，为空时使用系统根证书；WASM环境没有系统根证书，必须提供
+	Roots []*x509.Certificate
+	// Expected 期望部署的证书，为空时不判断证书是否匹配
+	Expected *certm.CertOutputData
+	// Now 评估时间，默认当前时间
+	Now time.Time
+}
+
+// Result 评估结果
+type Result struct {
+	TrustStatus certm.TrustStatus   // 信任状态，由 Reasons 推导
+	CertMatch   bool                // 叶子证书是否与期望证书一致
+	Reasons     []certm.TrustReason // 全部判定原因
+	Leaf        *x509.Certificate   // 对端叶子证书
+	SHA1        string              // 对端叶子证书SHA1指纹
+}
+
+// Apply 将评估结果写入检测端点结果
+func (r *Result) Apply(ep *certm.CheckEndpointResult) {
+	ep.TrustStatus = r.TrustStatus
+	ep.Reasons = r.Reasons
+	ep.CertMatch = r.CertMatch
+	if r.Leaf == nil {
+		return
+	}
+	ep.SHA1 = r.SHA1
+	ep.CommonName = r.Leaf.Subject.CommonName
+	ep.NotAfter = r.Leaf.NotAfter
+}
+
+// Evaluate 评估对端证书链的信任状态
+//
+// TrustStatus 由 certm.DeriveTrustStatus 推导，弱密钥与SHA-1签名仅作为警告记录。
+// 吊销及CA禁用/移除状态需结合吊销检查与平台CA策略，不在此判定。
+// WASM环境中未提供 Roots 时返回 ErrNoRoots。
+func Evaluate(in *Input) (*Result, error) {
+	if len(in.Roots) == 0 && !systemRoots {
+		return nil, ErrNoRoots
+	}
+	result := &Result{TrustStatus: certm.TrustStatusUnspecified}
+	if len(in.PeerChain) == 0 || in.PeerChain[0] == nil {
+		result.Reasons = append(result.Reasons, certm.NewTrustReason(
+			certm.TrustStatusUnspecified, "peer did not present a certificate"))
+		return result, nil
+	}
+
+	now := in.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	leaf := in.PeerChain[0]
+	result.Leaf = leaf
+	result.SHA1, _ = certutil.Fingerprint(leaf, certutil.FingerprintSHA1)
+	result.CertMatch = certMatch(result.SHA1, in.Expected)
+
+	var reasons []certm.TrustReason
+	add := func(code certm.TrustStatus, format string, args ...any) {
+		reasons = append(reasons, certm.NewTrustReason(code, fmt.Sprintf(format, args...)))
+	}
+
+	if now.After(leaf.NotAfter) {
+		add(certm.TrustStatusCertExpired, "certificate expired at %s", leaf.NotAfter.UTC().Format(time.RFC3339))
+	} else if now.Before(leaf.NotBefore) {
+		add(certm.TrustStatusCertNotYetValid, "certificate is not valid until %s", leaf.NotBefore.UTC().Format(time.RFC3339))
+	}
+
+	selfSigned := isSelfSigned(leaf)
+	if selfSigned {
+		add(certm.TrustStatusSelfSigned, "certificate is self-signed")
+	}
+
+	for _, cert := range in.PeerChain[1:] {
+		if now.After(cert.NotAfter) || now.Before(cert.NotBefore) {
+			add(certm.TrustStatusIntermediateExpired, "CA certificate %q is not valid at %s", cert.Subject.String(), now.UTC().Format(time.RFC3339))
+		}
+	}
+
+	if !selfSigned {
+		if msg := orderError(in.PeerChain); msg != "" {
+			add(certm.TrustStatusChainErr, "%s", msg)
+		}
+		if err := verify(in, leaf, now); err != nil {
+			var unknown x509.UnknownAuthorityError
+			var invalid x509.CertificateInvalidError
+			switch {
+			case errors.As(err, &unknown) && sha1Chained(in, leaf, now):
+				// 仅因SHA-1签名无法验证，在签名算法检查中记录
+			case errors.As(err, &unknown):
+				if len(in.PeerChain) == 1 && len(leaf.IssuingCertificateURL) > 0 {
+					add(certm.TrustStatusChainIncomplete, "intermediate certificates were not sent: issuer %q", leaf.Issuer.String())
+				} else {
+					add(certm.TrustStatusCANotFind, "unable to build a chain to a trusted root: %v", err)
+				}
+			case errors.As(err, &invalid) && invalid.Reason == x509.Expired:
+				// 已在有效期检查中记录
+			default:
+				add(certm.TrustStatusChainErr, "certificate chain verification failed: %v", err)
+			}
+		}
+	}
+
+	if host := hostname(in.Hostname); host != "" {
+		if !hostmatch.Covers(leaf, host) {
+			add(certm.TrustStatusDomainNotMatch, "certificate is not valid for %q", host)
+		}
+	}
+
+	if bits, weak := weakKey(leaf); weak {
+		add(certm.TrustStatusWeakKey, "certificate uses a weak %s key (%d bits)", leaf.PublicKeyAlgorithm, bits)
+	}
+	// 根证书自签名算法不影响信任
+	for i, cert := range in.PeerChain {
+		if isSHA1Signature(cert.SignatureAlgorithm) && !(i > 0 && isSelfSigned(cert)) {
+			add(certm.TrustStatusSHA1Signature, "certificate %q is signed with %s", cert.Subject.String(), cert.SignatureAlgorithm)
+		}
+	}
+
+	result.Reasons = reasons
+	result.TrustStatus = certm.DeriveTrustStatus(reasons)
+	return result, nil
+}
+
+// weakKey 判断公钥是否低于 RSA 2048 位或 ECDSA 256 位
+func weakKey(cert *x509.Certificate) (int, bool) {
+	switch pub := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return pub.N.BitLen(), pub.N.BitLen() < 2048
+	case *ecdsa.PublicKey:
+		return pub.Curve.Params().BitSize, pub.Curve.Params().BitSize < 256
+	}
+	return 0, false
+}
+
+// isSHA1Signature 判断签名算法是否使用SHA-1
+func isSHA1Signature(algo x509.SignatureAlgorithm) bool {
+	return algo == x509.SHA1WithRSA || algo == x509.ECDSAWithSHA1 || algo == x509.DSAWithSHA1
+}
+
+// verify 以对端中间证书构建证书链，有效期问题由调用方单独判定
+func verify(in *Input, cert *x509.Certificate, now time.Time) error {
+	var roots *x509.CertPool
+	if len(in.Roots) > 0 {
+		roots = x509.NewCertPool()
+		for _, root := range in.Roots {
+			roots.AddCert(root)
+		}
+	}
+	intermediates := x509.NewCertPool()
+	for _, c := range in.PeerChain[1:] {
+		intermediates.AddCert(c)
+	}
+	_, err := cert.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err
+}
+
+// sha1Chained 判断证书链是否仅因SHA-1签名无法验证
+//
+// crypto/x509 拒绝SHA-1签名并返回 UnknownAuthorityError。自叶子证书起逐级手动校验SHA-1签名，
+// 颁发者为 Input.Roots 中的根证书，或首个非SHA-1签名的证书可验证至受信任根证书时返回 true。
+// 使用系统根证书时无法取得根证书，由根证书直接以SHA-1签发的证书不能识别。
+func sha1Chained(in *Input, leaf *x509.Certificate, now time.Time) bool {
+	cert := leaf
+	for range in.PeerChain {
+		if !isSHA1Signature(cert.SignatureAlgorithm) {
+			err := verify(in, cert, now)
+			var invalid x509.CertificateInvalidError
+			return err == nil || errors.As(err, &invalid) && invalid.Reason == x509.Expired
+		}
+		for _, root := range in.Roots {
+			if checkSHA1Signature(root, cert) == nil {
+				return true
+			}
+		}
+		var parent *x509.Certificate
+		for _, c := range in.PeerChain[1:] {
+			if c != cert && checkSHA1Signature(c, cert) == nil {
+				parent = c
+				break
+			}
+		}
+		if parent == nil {
+			return false
+		}
+		cert = parent
+	}
+	return false
+}
+
+// checkSHA1Signature 校验 child 是否由 parent 以SHA-1签名，语义同 CheckSignatureFrom
+func checkSHA1Signature(parent, child *x509.Certificate) error {
+	if !bytes.Equal(parent.RawSubject, child.RawIssuer) {
+		return errors.New("trust: issuer name does not match")
+	}
+	if (parent.BasicConstraintsValid && !parent.IsCA) || (parent.KeyUsage != 0 && parent.KeyUsage&x509.KeyUsageCertSign == 0) {
+		return x509.ConstraintViolationError{}
+	}
+	digest := sha1.Sum(child.RawTBSCertificate)
+	switch pub := parent.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if child.SignatureAlgorithm != x509.SHA1WithRSA {
+			break
+		}
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA1, digest[:], child.Signature)
+	case *ecdsa.PublicKey:
+		if child.SignatureAlgorithm != x509.ECDSAWithSHA1 {
+			break
+		}
+		if !ecdsa.VerifyASN1(pub, digest[:], child.Signature) {
+			return errors.New("trust: ECDSA verification failure")
+		}
+		return nil
+	}
+	return x509.ErrUnsupportedAlgorithm
+}
+
+// orderError 检查对端证书链是否按签发顺序排列，SHA-1签名在签名算法检查中单独记录
+func orderError(peer []*x509.Certificate) string {
+	for i := 0; i+1 < len(peer); i++ {
+		child, parent := peer[i], peer[i+1]
+		if !bytes.Equal(child.RawIssuer, parent.RawSubject) {
+			return fmt.Sprintf("certificate %d (%q) is not issued by the next certificate (%q)", i, child.Subject.String(), parent.Subject.String())
+		}
+		if err := child.CheckSignatureFrom(parent); err != nil && !(isSHA1Signature(child.SignatureAlgorithm) && checkSHA1Signature(parent, child) == nil) {
+			return fmt.Sprintf("certificate %d (%q) signature is not verified by %q: %v", i, child.Subject.String(), parent.Subject.String(), err)
+		}
+	}
+	return ""
+}
+
+// isSelfSigned 判断证书是否由自身签发
+func isSelfSigned(cert *x509.Certificate) bool {
+	if !bytes.Equal(cert.RawIssuer, cert.RawSubject) {
+		return false
+	}
+	err := cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature)
+	var insecure x509.InsecureAlgorithmError
+	return err == nil || errors.As(err, &insecure)
+}
+
+// certMatch 判断叶子证书指纹是否与期望证书一致
+func certMatch(sha1 string, expected *certm.CertOutputData) bool {
+	if expected == nil || sha1 == "" {
+		return false
+	}
+	if expected.SHA1 != "" {
+		return certutil.NormalizeFingerprint(expected.SHA1) == sha1
+	}
+	fp, err := expected.Fingerprint(certutil.FingerprintSHA1)
+	return err == nil && fp == sha1
+}
+
+// hostname 去除端口及末尾的点
+func hostname(h string) string {
+	if host, _, err := net.SplitHostPort(h); err == nil {
+		h = host
+	}
+	return strings.TrimSuffix(strings.Trim(h, "[]"), ".")
+}
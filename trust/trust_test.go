@@ -0,0 +1,230 @@
+package trust
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	certm "github.com/trustasia-com/certm-plugin-sdk"
+	"github.com/trustasia-com/certm-plugin-sdk/certutil"
+	"github.com/trustasia-com/certm-plugin-sdk/internal/testpki"
+)
+
+type testPKI struct {
+	*testpki.PKI
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	return &testPKI{testpki.New(t)}
+}
+
+func (p *testPKI) leaf(t *testing.T, notAfter time.Time, aia bool) *x509.Certificate {
+	t.Helper()
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		DNSNames:     []string{"example.com", "*.example.com"},
+		NotBefore:    time.Now().Add(-2 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	if aia {
+		tmpl.IssuingCertificateURL = []string{"http://ca.example.com/inter.cer"}
+	}
+	cert, _ := testpki.Issue(t, tmpl, p.Inter, p.InterKey)
+	return cert
+}
+
+func mustEvaluate(t *testing.T, in *Input) *Result {
+	t.Helper()
+	result, err := Evaluate(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestEvaluate_Roots(t *testing.T) {
+	pki := newTestPKI(t)
+	leaf := pki.leaf(t, time.Now().Add(time.Hour), false)
+	_, err := Evaluate(&Input{PeerChain: []*x509.Certificate{leaf, pki.Inter}})
+	if systemRoots && err != nil {
+		t.Errorf("Expected system roots to be used, got %v", err)
+	}
+	if !systemRoots && !errors.Is(err, ErrNoRoots) {
+		t.Errorf("Expected ErrNoRoots, got %v", err)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	pki := newTestPKI(t)
+	now := time.Now()
+	valid := pki.leaf(t, now.Add(time.Hour), true)
+	expired := pki.leaf(t, now.Add(-time.Hour), false)
+
+	selfSigned, _ := testpki.Issue(t, &x509.Certificate{
+		SerialNumber: big.NewInt(9),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		DNSNames:     []string{"example.com"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(time.Hour),
+	}, nil, nil)
+	otherRoots := []*x509.Certificate{selfSigned}
+
+	tests := []struct {
+		name     string
+		peer     []*x509.Certificate
+		host     string
+		roots    []*x509.Certificate
+		expected certm.TrustStatus
+	}{
+		{"trusted", []*x509.Certificate{valid, pki.Inter}, "www.example.com:443", pki.Roots(), certm.TrustStatusTrusted},
+		{"expired", []*x509.Certificate{expired, pki.Inter}, "example.com", pki.Roots(), certm.TrustStatusCertExpired},
+		{"self signed", []*x509.Certificate{selfSigned}, "example.com", pki.Roots(), certm.TrustStatusSelfSigned},
+		{"missing intermediate", []*x509.Certificate{valid}, "example.com", pki.Roots(), certm.TrustStatusChainErr},
+		{"wrong order", []*x509.Certificate{valid, pki.Root, pki.Inter}, "example.com", pki.Roots(), certm.TrustStatusChainErr},
+		{"unknown root", []*x509.Certificate{valid, pki.Inter}, "example.com", otherRoots, certm.TrustStatusCANotFind},
+		{"domain mismatch", []*x509.Certificate{valid, pki.Inter}, "example.org", pki.Roots(), certm.TrustStatusDomainNotMatch},
+		{"wildcard depth", []*x509.Certificate{valid, pki.Inter}, "a.b.example.com", pki.Roots(), certm.TrustStatusDomainNotMatch},
+		{"case insensitive", []*x509.Certificate{valid, pki.Inter}, "WWW.Example.com.", pki.Roots(), certm.TrustStatusTrusted},
+		{"no certificate", nil, "example.com", pki.Roots(), certm.TrustStatusUnspecified},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := mustEvaluate(t, &Input{PeerChain: tt.peer, Hostname: tt.host, Roots: tt.roots, Now: now})
+			if result.TrustStatus != tt.expected {
+				t.Errorf("Expected %d, got %d (%+v)", tt.expected, result.TrustStatus, result.Reasons)
+			}
+			if tt.expected != certm.TrustStatusTrusted && len(result.Reasons) == 0 {
+				t.Error("Expected reasons")
+			}
+		})
+	}
+}
+
+func TestEvaluate_Reasons(t *testing.T) {
+	pki := newTestPKI(t)
+	leaf := pki.leaf(t, time.Now().Add(time.Hour), false)
+
+	// 尚未生效映射为证书过期
+	result := mustEvaluate(t, &Input{
+		PeerChain: []*x509.Certificate{leaf, pki.Inter},
+		Roots:     pki.Roots(),
+		Now:       time.Now().Add(-3 * time.Hour),
+	})
+	if result.TrustStatus != certm.TrustStatusCertExpired {
+		t.Errorf("Expected legacy status CertExpired, got %d", result.TrustStatus)
+	}
+	codes := map[certm.TrustStatus]bool{}
+	for _, r := range result.Reasons {
+		codes[r.Code] = true
+	}
+	if !codes[certm.TrustStatusCertNotYetValid] || !codes[certm.TrustStatusIntermediateExpired] {
+		t.Errorf("Unexpected reasons %+v", result.Reasons)
+	}
+
+	// 弱密钥仅为警告
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	weakLeaf := testpki.NewCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(4),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		DNSNames:     []string{"example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}, pki.Inter, &weak.PublicKey, pki.InterKey)
+	result = mustEvaluate(t, &Input{PeerChain: []*x509.Certificate{weakLeaf, pki.Inter}, Roots: pki.Roots()})
+	if result.TrustStatus != certm.TrustStatusTrusted {
+		t.Errorf("Expected Trusted, got %d", result.TrustStatus)
+	}
+	if len(result.Reasons) != 1 || result.Reasons[0].Code != certm.TrustStatusWeakKey ||
+		result.Reasons[0].Severity != certm.TrustSeverityWarning {
+		t.Errorf("Unexpected reasons %+v", result.Reasons)
+	}
+}
+
+func TestEvaluate_SHA1(t *testing.T) {
+	pki := newTestPKI(t)
+	now := time.Now()
+	key := testpki.NewKey(t)
+	sha1Leaf := func(parent *x509.Certificate, parentKey *ecdsa.PrivateKey) *x509.Certificate {
+		return testpki.NewCert(t, &x509.Certificate{
+			SerialNumber:       big.NewInt(5),
+			Subject:            pkix.Name{CommonName: "example.com"},
+			DNSNames:           []string{"example.com"},
+			NotBefore:          now.Add(-time.Hour),
+			NotAfter:           now.Add(time.Hour),
+			SignatureAlgorithm: x509.ECDSAWithSHA1,
+		}, parent, &key.PublicKey, parentKey)
+	}
+	underRoot := sha1Leaf(pki.Root, pki.RootKey)
+	underInter := sha1Leaf(pki.Inter, pki.InterKey)
+
+	tests := []struct {
+		name     string
+		peer     []*x509.Certificate
+		roots    []*x509.Certificate
+		expected certm.TrustStatus
+	}{
+		{"under root", []*x509.Certificate{underRoot}, pki.Roots(), certm.TrustStatusSHA1Signature},
+		{"under intermediate", []*x509.Certificate{underInter, pki.Inter}, pki.Roots(), certm.TrustStatusSHA1Signature},
+		{"unknown root", []*x509.Certificate{underInter, pki.Inter}, newTestPKI(t).Roots(), certm.TrustStatusCANotFind},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := mustEvaluate(t, &Input{PeerChain: tt.peer, Hostname: "example.com", Roots: tt.roots, Now: now})
+			codes := map[certm.TrustStatus]bool{}
+			for _, r := range result.Reasons {
+				codes[r.Code] = true
+			}
+			if !codes[tt.expected] || !codes[certm.TrustStatusSHA1Signature] {
+				t.Errorf("Expected %d, got %+v", tt.expected, result.Reasons)
+			}
+			if tt.expected == certm.TrustStatusSHA1Signature && (len(result.Reasons) != 1 || result.TrustStatus != certm.TrustStatusTrusted) {
+				t.Errorf("Expected only a SHA-1 warning, got %d %+v", result.TrustStatus, result.Reasons)
+			}
+		})
+	}
+}
+
+func TestEvaluate_CertMatch(t *testing.T) {
+	pki := newTestPKI(t)
+	leaf := pki.leaf(t, time.Now().Add(time.Hour), false)
+	sha1, _ := certutil.Fingerprint(leaf, certutil.FingerprintSHA1)
+
+	result := mustEvaluate(t, &Input{
+		PeerChain: []*x509.Certificate{leaf, pki.Inter},
+		Hostname:  "example.org",
+		Roots:     pki.Roots(),
+		Expected:  &certm.CertOutputData{SHA1: sha1},
+	})
+	if !result.CertMatch {
+		t.Error("Expected certificate to match")
+	}
+	// 证书匹配不影响信任状态判定
+	if len(result.Reasons) != 1 || result.Reasons[0].Code != certm.TrustStatusDomainNotMatch {
+		t.Errorf("Unexpected reasons %+v", result.Reasons)
+	}
+
+	ep := &certm.CheckEndpointResult{}
+	result.Apply(ep)
+	if ep.SHA1 != sha1 || ep.CommonName != "example.com" || ep.TrustStatus != certm.TrustStatusDomainNotMatch {
+		t.Errorf("Unexpected endpoint result %+v", ep)
+	}
+
+	result = mustEvaluate(t, &Input{
+		PeerChain: []*x509.Certificate{leaf},
+		Expected:  &certm.CertOutputData{SHA1: "00"},
+	})
+	if result.CertMatch {
+		t.Error("Expected certificate mismatch")
+	}
+}
@@ -13,6 +13,9 @@ import (
 type CertmContext struct {
 	ProjectID int    `json:"project_id"` // 项目ID
 	Language  string `json:"language"`   // 语言
+
+	Workflow *WorkflowRun `json:"workflow,omitempty"` // 工作流运行信息，仅 execute 时提供
+	Cache    *CachePolicy `json:"cache,omitempty"`    // 数据缓存控制
 }
 
 // Component 组件接口，实现的组件必须是无状态的
@@ -87,8 +90,105 @@ const (
 	TrustStatusChainErr       TrustStatus = 10 // 证书链配置错误
 	TrustStatusSelfSigned     TrustStatus = 11 // 自签名证书
 	TrustStatusDomainNotMatch TrustStatus = 12 // 域名不匹配
+
+	// 以下为细分原因，通过 Legacy 映射为上述状态
+
+	TrustStatusCertNotYetValid     TrustStatus = 13 // 证书尚未生效
+	TrustStatusWeakKey             TrustStatus = 14 // 弱密钥
+	TrustStatusSHA1Signature       TrustStatus = 15 // SHA-1签名
+	TrustStatusIntermediateExpired TrustStatus = 16 // 中间证书过期
+	TrustStatusOCSPUnknown         TrustStatus = 17 // OCSP状态未知
+	TrustStatusChainIncomplete     TrustStatus = 18 // 证书链不完整
+	TrustStatusCAANotPermitted     TrustStatus = 19 // CAA记录不允许颁发CA
+	TrustStatusTLSAMismatch        TrustStatus = 20 // TLSA记录与证书不匹配
+)
+
+// Legacy 返回细分原因对应的原有信任状态，不影响信任的原因返回 TrustStatusTrusted
+func (t TrustStatus) Legacy() TrustStatus {
+	switch t {
+	case TrustStatusCertNotYetValid:
+		return TrustStatusCertExpired
+	case TrustStatusIntermediateExpired:
+		return TrustStatusCAExpired
+	case TrustStatusChainIncomplete:
+		return TrustStatusChainErr
+	case TrustStatusWeakKey, TrustStatusSHA1Signature, TrustStatusOCSPUnknown,
+		TrustStatusCAANotPermitted, TrustStatusTLSAMismatch:
+		return TrustStatusTrusted
+	}
+	return t
+}
+
+// Severity 返回原因的默认严重程度
+func (t TrustStatus) Severity() TrustSeverity {
+	switch t {
+	case TrustStatusUnspecified, TrustStatusTrusted:
+		return TrustSeverityInfo
+	case TrustStatusWeakKey, TrustStatusSHA1Signature, TrustStatusOCSPUnknown,
+		TrustStatusCAANotPermitted, TrustStatusTLSAMismatch:
+		return TrustSeverityWarning
+	}
+	return TrustSeverityCritical
+}
+
+// TrustSeverity 原因严重程度
+type TrustSeverity string
+
+const (
+	TrustSeverityInfo     TrustSeverity = "info"     // 提示
+	TrustSeverityWarning  TrustSeverity = "warning"  // 警告，不影响信任状态
+	TrustSeverityCritical TrustSeverity = "critical" // 严重，导致不信任
 )
 
+// TrustReason 端点信任问题
+type TrustReason struct {
+	Code     TrustStatus   `json:"code"`     // 原因代码
+	Severity TrustSeverity `json:"severity"` // 严重程度
+	Message  string        `json:"message"`  // 原因描述
+}
+
+// NewTrustReason 使用默认严重程度创建原因
+func NewTrustReason(code TrustStatus, message string) TrustReason {
+	return TrustReason{Code: code, Severity: code.Severity(), Message: message}
+}
+
+// trustPriority 原有信任状态的判定优先级，靠前者优先
+var trustPriority = []TrustStatus{
+	TrustStatusCertRevoked,
+	TrustStatusCARevoked,
+	TrustStatusCertExpired,
+	TrustStatusSelfSigned,
+	TrustStatusCAExpired,
+	TrustStatusCADisabled,
+	TrustStatusCARemoved,
+	TrustStatusChainErr,
+	TrustStatusCANotFind,
+	TrustStatusDomainNotMatch,
+}
+
+// DeriveTrustStatus 从原因列表推导原有信任状态
+//
+// 取严重原因中优先级最高者映射后的状态，没有严重原因时返回 TrustStatusTrusted。
+func DeriveTrustStatus(reasons []TrustReason) TrustStatus {
+	found := make(map[TrustStatus]bool, len(reasons))
+	for _, r := range reasons {
+		if r.Severity == TrustSeverityCritical {
+			found[r.Code.Legacy()] = true
+		}
+	}
+	for _, status := range trustPriority {
+		if found[status] {
+			return status
+		}
+	}
+	for _, r := range reasons {
+		if r.Severity == TrustSeverityCritical && r.Code.Legacy() != TrustStatusTrusted {
+			return r.Code.Legacy()
+		}
+	}
+	return TrustStatusTrusted
+}
+
 // ComponentInfo 组件信息
 type ComponentInfo struct {
 	Type ComponentType `json:"type"`
@@ -142,20 +242,42 @@ type DeployOutputData struct {
 
 // CheckEndpointResult 单个检测端点结果
 type CheckEndpointResult struct {
-	Endpoint string `json:"endpoint"` // 检测地址: example.com:443
-	IP       string `json:"ip"`       // 检测IP
+	Endpoint string `json:"endpoint"`           // 检测地址: example.com:443
+	Protocol string `json:"protocol,omitempty"` // 检测协议: tls/smtp/imap/pop3/ftp/ldap/postgres/mysql，为空表示tls
+	IP       string `json:"ip"`                 // 检测IP
 
 	// 检测结果
-	TrustStatus  TrustStatus `json:"trust_status"`  // SSL信任状态
-	CertMatch    bool        `json:"cert_match"`    // 证书是否匹配
-	ResponseTime int         `json:"response_time"` // 响应时间(ms)
-	Error        string      `json:"error"`         // 错误信息
+	TrustStatus  TrustStatus   `json:"trust_status"`      // SSL信任状态，由 Reasons 推导
+	Reasons      []TrustReason `json:"reasons,omitempty"` // 信任问题列表
+	CertMatch    bool          `json:"cert_match"`        // 证书是否匹配
+	ResponseTime int           `json:"response_time"`     // 响应时间(ms)
+	Error        string        `json:"error"`             // 错误信息
 
 	// 证书信息
 	SHA1       string    `json:"sha1"`        // 证书指纹
 	CommonName string    `json:"common_name"` // 主域名
 	NotAfter   time.Time `json:"not_after"`   // 过期时间
 	CheckedAt  time.Time `json:"checked_at"`  // 检测时间
+
+	// 安全配置，仅在启用扫描时输出
+	Security *EndpointSecurity `json:"security,omitempty"`
+}
+
+// EndpointSecurity 端点TLS安全配置
+type EndpointSecurity struct {
+	TLSVersions      []string          `json:"tls_versions,omitempty"`       // 支持的协议版本: TLS1.0/TLS1.1/TLS1.2/TLS1.3
+	CipherSuites     []string          `json:"cipher_suites,omitempty"`      // 支持的密码套件
+	WeakCipherSuites []string          `json:"weak_cipher_suites,omitempty"` // 其中不安全的密码套件
+	HSTS             string            `json:"hsts,omitempty"`               // Strict-Transport-Security 响应头
+	Headers          map[string]string `json:"headers,omitempty"`            // 其他安全响应头
+	Grade            string            `json:"grade"`                        // 评级: A+/A/B/C/F
+	Issues           []string          `json:"issues,omitempty"`             // 影响评级的问题及扫描覆盖说明
+}
+
+// AddReason 追加信任问题并重新推导 TrustStatus
+func (r *CheckEndpointResult) AddReason(reasons ...TrustReason) {
+	r.Reasons = append(r.Reasons, reasons...)
+	r.TrustStatus = DeriveTrustStatus(r.Reasons)
 }
 
 // CheckOutputData 检测结果
@@ -273,6 +395,7 @@ type PluginYaml struct {
 	Author        *AuthorInfo        `json:"author" yaml:"author"`
 	Tags          []string           `json:"tags" yaml:"tags"`
 	Compatibility *CompatibilityInfo `json:"compatibility" yaml:"compatibility"`
+	Permissions   []Permission       `json:"permissions" yaml:"permissions"`
 }
 
 // AuthorInfo 作者信息
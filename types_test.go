@@ -0,0 +1,49 @@
+package certm
+
+import "testing"
+
+func TestDeriveTrustStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		reasons  []TrustReason
+		expected TrustStatus
+	}{
+		{"no reasons", nil, TrustStatusTrusted},
+		{"warnings only", []TrustReason{
+			NewTrustReason(TrustStatusWeakKey, ""),
+			NewTrustReason(TrustStatusOCSPUnknown, ""),
+			NewTrustReason(TrustStatusCAANotPermitted, ""),
+			NewTrustReason(TrustStatusTLSAMismatch, ""),
+		}, TrustStatusTrusted},
+		{"legacy mapping", []TrustReason{
+			NewTrustReason(TrustStatusChainIncomplete, ""),
+		}, TrustStatusChainErr},
+		{"priority", []TrustReason{
+			NewTrustReason(TrustStatusDomainNotMatch, ""),
+			NewTrustReason(TrustStatusIntermediateExpired, ""),
+			NewTrustReason(TrustStatusCertNotYetValid, ""),
+		}, TrustStatusCertExpired},
+		{"downgraded severity", []TrustReason{
+			{Code: TrustStatusDomainNotMatch, Severity: TrustSeverityWarning},
+		}, TrustStatusTrusted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DeriveTrustStatus(tt.reasons); got != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCheckEndpointResult_AddReason(t *testing.T) {
+	ep := &CheckEndpointResult{TrustStatus: TrustStatusTrusted}
+	ep.AddReason(NewTrustReason(TrustStatusSHA1Signature, "sha1"))
+	if ep.TrustStatus != TrustStatusTrusted {
+		t.Errorf("Expected Trusted, got %d", ep.TrustStatus)
+	}
+	ep.AddReason(NewTrustReason(TrustStatusCertRevoked, "revoked"))
+	if ep.TrustStatus != TrustStatusCertRevoked || len(ep.Reasons) != 2 {
+		t.Errorf("Expected CertRevoked with 2 reasons, got %d/%d", ep.TrustStatus, len(ep.Reasons))
+	}
+}
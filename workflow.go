@@ -0,0 +1,71 @@
+package certm
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const workflowRunCtxKey contextKey = "workflowRun"
+
+// WorkflowTrigger 工作流触发方式
+type WorkflowTrigger string
+
+const (
+	WorkflowTriggerManual   WorkflowTrigger = "manual"   // 手动触发
+	WorkflowTriggerSchedule WorkflowTrigger = "schedule" // 定时触发
+	WorkflowTriggerEvent    WorkflowTrigger = "event"    // 事件触发，如证书即将过期
+	WorkflowTriggerAPI      WorkflowTrigger = "api"      // API触发
+)
+
+// WorkflowRun 当前工作流运行信息
+type WorkflowRun struct {
+	WorkflowID   int               `json:"workflow_id"`    // 工作流ID
+	WorkflowName string            `json:"workflow_name"`  // 工作流名称
+	RunID        int               `json:"run_id"`         // 运行ID
+	Trigger      WorkflowTrigger   `json:"trigger"`        // 触发方式
+	Attempt      int               `json:"attempt"`        // 当前步骤执行次数，从1开始，重试时递增
+	StartedAt    time.Time         `json:"started_at"`     // 运行开始时间
+	Step         *WorkflowStepInfo `json:"step,omitempty"` // 当前步骤
+}
+
+// GetWorkflowRun 获取当前工作流运行信息，不在工作流中执行（如获取配置Schema）时返回 nil
+func GetWorkflowRun(ctx context.Context) *WorkflowRun {
+	run, _ := ctx.Value(workflowRunCtxKey).(*WorkflowRun)
+	return run
+}
+
+// SetWorkflowRun 设置当前工作流运行信息
+func SetWorkflowRun(ctx context.Context, run *WorkflowRun) context.Context {
+	return context.WithValue(ctx, workflowRunCtxKey, run)
+}
+
+// IsRetry 判断当前步骤是否为重试，不在工作流中运行时返回 false
+func (r *WorkflowRun) IsRetry() bool {
+	return r != nil && r.Attempt > 1
+}
+
+// StateKey 返回按运行隔离的状态键，用于同一次运行内的步骤间共享或重试时恢复进度
+//
+// 不在工作流中运行时原样返回 key。
+func (r *WorkflowRun) StateKey(key string) string {
+	if r == nil {
+		return key
+	}
+	return fmt.Sprintf("run/%d/%s", r.RunID, key)
+}
+
+// String 返回便于日志输出的描述，如 workflow 3 run 15 step deploy attempt 2
+func (r *WorkflowRun) String() string {
+	if r == nil {
+		return ""
+	}
+	s := fmt.Sprintf("workflow %d run %d", r.WorkflowID, r.RunID)
+	if r.Step != nil {
+		s += " step " + r.Step.Name
+	}
+	if r.Attempt > 0 {
+		s += fmt.Sprintf(" attempt %d", r.Attempt)
+	}
+	return s
+}
@@ -0,0 +1,38 @@
+package certm
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestWorkflowRun(t *testing.T) {
+	ctx := context.Background()
+	if GetWorkflowRun(ctx) != nil {
+		t.Error("expected nil run")
+	}
+	if GetWorkflowRun(SetWorkflowRun(ctx, nil)) != nil {
+		t.Error("expected nil run")
+	}
+	var none *WorkflowRun
+	if none.IsRetry() || none.StateKey("progress") != "progress" || none.String() != "" {
+		t.Errorf("nil run: IsRetry = %v, StateKey = %s, String = %q", none.IsRetry(), none.StateKey("progress"), none.String())
+	}
+
+	var c CertmContext
+	data := `{"project_id":1,"language":"zh-CN","workflow":{"workflow_id":3,"run_id":15,"trigger":"schedule","attempt":2,` +
+		`"step":{"id":7,"name":"deploy","component_id":"my-deployer","upstream":[5,6]}}}`
+	if err := json.Unmarshal([]byte(data), &c); err != nil {
+		t.Fatal(err)
+	}
+	run := GetWorkflowRun(SetWorkflowRun(ctx, c.Workflow))
+	if run == nil || run.Trigger != WorkflowTriggerSchedule || len(run.Step.Upstream) != 2 {
+		t.Fatalf("run = %+v", run)
+	}
+	if !run.IsRetry() || run.StateKey("progress") != "run/15/progress" {
+		t.Errorf("IsRetry = %v, StateKey = %s", run.IsRetry(), run.StateKey("progress"))
+	}
+	if got := run.String(); got != "workflow 3 run 15 step deploy attempt 2" {
+		t.Errorf("String = %s", got)
+	}
+}